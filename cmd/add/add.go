@@ -1,41 +1,200 @@
 package add
 
 import (
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/tnierman/git-grove/pkg/grove"
 )
 
 var Command = &cobra.Command{
-	Use:   "add <tree>",
-	Short: "Add a new tree to the grove",
-	Long: `Adds a new tree to the grove.
+	Use:   "add [<tree>...]",
+	Short: "Add new trees to the grove",
+	Long: `Adds new trees to the grove.
 
 The new worktree is created at the given path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
 
-In all cases, any subdirectory which does not already exist will be created with bit mask 0x700`,
-	Args: cobra.ExactArgs(1),
+In all cases, any subdirectory which does not already exist will be created with bit mask 0x700
+
+By default, a branch named after the last element of the path is checked out in the new worktree. With --detach, the given
+commit or tag is checked out with a detached HEAD instead.
+
+Several trees can be added at once by providing multiple paths. Each is checked out on a branch named after the last
+element of its own path - or at the --detach commit, for every tree. A failure to add one tree does not prevent the
+others from being added; a summary is printed once every tree has been attempted.
+
+With --branch, the given branch is checked out instead. If no path is provided alongside it, the tree is placed according
+to --layout: 'path' (the default) nests the tree at the path matching the branch's name, 'flat' places it directly
+beneath the grove's root with each '/' in the branch's name replaced by '-', and any other value is a Go text/template
+executed against the branch - such as 'wt/{{replace .Branch "/" "-"}}'.
+
+A branch which only exists on a remote - such as 'feature', after 'origin/feature' has been fetched - is created at the
+remote's branch, and configured to track it, as with 'git worktree add'. The remote-tracking branch may also be named
+directly with --branch, such as 'origin/feature', creating the local branch 'feature'. Remotes are searched for the
+branch starting with 'origin'. With --no-track, the branch is still created at the remote's branch, but does not track
+it.
+
+With --template, the contents of the given directory - such as a .env file or editor configuration - are copied into
+each new tree, without being committed. Files which would overwrite those checked out from the branch are skipped.
+
+As with 'git worktree add', the post-checkout hook is run in each new tree once it is checked out - and the template
+copied into it - unless --no-hooks is given. It is the only hook grove runs. The hook is read from the directory
+configured by core.hooksPath, or the repository's hooks/ directory otherwise. If it fails, the tree is left in place.
+
+With --recurse-submodules, each new tree's submodules are initialized and checked out, recursively, before the
+post-checkout hook is run. Credentials for each submodule are determined as when fetching, and only requested if it
+cannot be accessed anonymously. As with git, each tree keeps its submodules' repositories within its own git directory,
+so their objects are retrieved again for every tree, rather than shared with other trees.`,
+	Example: `
+Create a throwaway tree "reviewdir" checked out at the tag v1.2.3:
+
+	grove add reviewdir --detach v1.2.3
+
+Create trees for three new branches:
+
+	grove add feature-a feature-b bugfix
+
+Create a tree "feature-login" for the branch feature/login:
+
+	grove add --branch feature/login --layout flat
+
+Create a tree "feature" seeded with scratch files:
+
+	grove add feature --template ~/.grove-template
+
+Create a tree "feature" with its submodules checked out:
+
+	grove add feature --recurse-submodules
+	`,
 	RunE: func(_ *cobra.Command, args []string) error {
-		// cobra ExactArgs guarantees exactly 1 argument to this command
-		path := args[0]
-		err := NewTree(path)
-		if err != nil {
-			return err
+		opts := grove.AddTreeOptions{
+			Branch:   branch,
+			Template: template,
+			NoHooks:  noHooks,
+			NoTrack:  noTrack,
+
+			RecurseSubmodules: recurseSubmodules,
+		}
+		if detach != "" {
+			opts.Commit = detach
+			opts.Detach = true
+		}
+		if len(args) == 0 {
+			if branch == "" {
+				return fmt.Errorf("a tree or --branch must be provided")
+			}
+			l, err := grove.ParseLayout(layout)
+			if err != nil {
+				return err
+			}
+			return NewBranchTree(branch, l, opts)
+		}
+		if branch != "" && len(args) > 1 {
+			return fmt.Errorf("--branch cannot be used when adding multiple trees")
 		}
-		return nil
+		if len(args) == 1 {
+			return NewTree(args[0], opts)
+		}
+		return NewTrees(args, opts)
 	},
 }
 
-func NewTree(path string) error {
-	grove, err := grove.Init()
+var (
+	detach   string
+	branch   string
+	layout   string
+	template string
+	noHooks  bool
+	noTrack  bool
+
+	recurseSubmodules bool
+)
+
+func init() {
+	Command.Flags().StringVar(&detach, "detach", "", "check out the given commit or tag with a detached HEAD, rather than a branch")
+	Command.Flags().StringVarP(&branch, "branch", "b", "", "branch to check out in the tree (defaults to the last element of the tree's path)")
+	Command.Flags().StringVar(&layout, "layout", grove.LayoutPath, "where the tree is placed when only --branch is given: 'path', 'flat', or a template such as 'wt/{{.Branch}}'")
+	Command.Flags().StringVar(&template, "template", "", "directory whose contents are copied into each new tree, without being committed")
+	Command.Flags().BoolVar(&noTrack, "no-track", false, "don't configure a branch created from a remote's branch to track it")
+	Command.Flags().BoolVar(&noHooks, "no-hooks", false, "skip running the post-checkout hook in each new tree")
+	Command.Flags().BoolVar(&recurseSubmodules, "recurse-submodules", false, "initialize and check out the submodules of each new tree, recursively")
+	Command.MarkFlagsMutuallyExclusive("detach", "branch")
+}
+
+func NewTree(path string, opts grove.AddTreeOptions) error {
+	g, err := grove.Open()
 	if err != nil {
-		return fmt.Errorf("failed to initialize grove: %w", err)
+		return fmt.Errorf("failed to open grove: %w", err)
 	}
 
-	err = grove.AddTree(path)
+	err = g.AddTree(path, opts)
 	if err != nil {
+		printHint(err)
 		return fmt.Errorf("failed to add tree %q: %w", path, err)
 	}
 	return nil
 }
+
+// NewBranchTree adds a tree for the given branch, placed within the grove according to the given layout
+func NewBranchTree(branch string, layout grove.Layout, opts grove.AddTreeOptions) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+	g.Layout = layout
+
+	// Trees for remote-tracking branches, such as 'origin/feature', are placed according to the local branch's name
+	localBranch, err := g.LocalBranch(branch)
+	if err != nil {
+		return err
+	}
+	path, err := g.BranchTreePath(localBranch)
+	if err != nil {
+		return err
+	}
+
+	opts.Branch = branch
+	err = g.AddTree(path, opts)
+	if err != nil {
+		printHint(err)
+		return fmt.Errorf("failed to add tree %q for branch %q: %w", path, branch, err)
+	}
+	return nil
+}
+
+// NewTrees adds a tree at each of the given paths, continuing past any failures, then prints a summary
+func NewTrees(paths []string, opts grove.AddTreeOptions) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	failed := 0
+	for _, path := range paths {
+		err = g.AddTree(path, opts)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "failed to add tree %q: %v\n", path, err)
+			printHint(err)
+			continue
+		}
+		fmt.Printf("added tree %q\n", path)
+	}
+
+	fmt.Printf("added %d of %d trees\n", len(paths)-failed, len(paths))
+	if failed > 0 {
+		return fmt.Errorf("failed to add %d of %d trees", failed, len(paths))
+	}
+	return nil
+}
+
+// printHint suggests alternatives to the user when a tree could not be added because its branch is already checked out
+func printHint(err error) {
+	checkedOut := &grove.BranchCheckedOutError{}
+	if !errors.As(err, &checkedOut) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "hint: use '--detach %s' to check out its commit without the branch, or '--branch <name>' to create a new branch instead\n", checkedOut.Branch)
+}
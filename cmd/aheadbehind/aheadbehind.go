@@ -0,0 +1,93 @@
+package aheadbehind
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "ahead-behind <tree> [<base>]",
+	Short: "Count the commits a tree is ahead of and behind a base",
+	Long: `Compares the HEAD of a tree with a base revision, counting the commits the tree has which the base does not
+(ahead), and the commits the base has which the tree does not (behind). If no base is given, the upstream of the tree's
+branch is used, or otherwise the grove's default branch.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
+
+The result is printed as 'ahead=<n> behind=<n>', or as an object with --output-format json or yaml.`,
+	Example: `
+Fail a CI job if the tree "feature-x" is behind main:
+
+	test "$(grove ahead-behind feature-x main --output-format json | jq .behind)" -eq 0
+	`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := output.ParseResultFormat(cmd.Flag(output.ResultFormatFlag).Value.String())
+		if err != nil {
+			return err
+		}
+		legacy, err := output.ParseFormat(outputFormat)
+		if err != nil {
+			return err
+		}
+		base := ""
+		if len(args) > 1 {
+			base = args[1]
+		}
+		// cobra RangeArgs guarantees at least 1 argument to this command
+		return AheadBehind(args[0], base, format, legacy == output.FormatJSON)
+	},
+}
+
+var outputFormat string
+
+func init() {
+	Command.Flags().StringVar(&outputFormat, "output", string(output.FormatText), "format of the result: 'text' for 'ahead=<n> behind=<n>', or 'json' for a JSON object")
+	_ = Command.Flags().MarkDeprecated("output", "use --output-format instead")
+}
+
+// result is the JSON and YAML representation of a grove.Comparison
+type result struct {
+	Tree      string `json:"tree"`
+	Branch    string `json:"branch,omitempty"`
+	Base      string `json:"base"`
+	MergeBase string `json:"mergeBase,omitempty"`
+	Ahead     int    `json:"ahead"`
+	Behind    int    `json:"behind"`
+}
+
+// AheadBehind prints the number of commits the tree at the given path is ahead of and behind base, in the given format.
+// If compactJSON is set, the result is instead printed as a single line of JSON, as with the deprecated '--output json'
+func AheadBehind(path, base string, format output.ResultFormat, compactJSON bool) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	comparison, err := g.AheadBehind(path, base)
+	if err != nil {
+		return fmt.Errorf("failed to compare tree %q: %w", path, err)
+	}
+
+	res := result{
+		Tree:      comparison.Tree.Path,
+		Branch:    comparison.Tree.Branch,
+		Base:      comparison.Base,
+		MergeBase: comparison.MergeBase,
+		Ahead:     comparison.Ahead,
+		Behind:    comparison.Behind,
+	}
+	if compactJSON {
+		return json.NewEncoder(os.Stdout).Encode(res)
+	}
+	return output.Render(os.Stdout, format, res, func(out io.Writer) error {
+		_, err := fmt.Fprintf(out, "ahead=%d behind=%d\n", comparison.Ahead, comparison.Behind)
+		return err
+	})
+}
@@ -0,0 +1,102 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/git/local"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "archive [<tree>]",
+	Short: "Export the files of a tree as an archive",
+	Long: `Exports the files committed at a tree's HEAD - or at the revision given by --ref - as an archive, without any
+history. Uncommitted changes are not included. If no tree is given, the tree containing the current directory is archived.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
+
+The archive is written to the file given by --output, or to stdout. Its format - 'tar', 'tar.gz', or 'zip' - is given by
+--format, or otherwise inferred from the output file's extension, defaulting to 'tar'.`,
+	Example: `
+Export the tree "feature-x" as a gzipped tarball:
+
+	grove archive feature-x -o feature-x.tar.gz
+
+Export the tag v1.2.3 as a zip file, with every file beneath a "project-1.2.3" directory:
+
+	grove archive --ref v1.2.3 --prefix project-1.2.3 -o project-1.2.3.zip
+	`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		format := local.ArchiveFormatTar
+		if formatName != "" {
+			var err error
+			format, err = local.ParseArchiveFormat(formatName)
+			if err != nil {
+				return err
+			}
+		} else if inferred, ok := local.ArchiveFormatOf(outputPath); ok {
+			format = inferred
+		}
+		return Archive(path, ref, format, prefix, outputPath)
+	},
+}
+
+var (
+	formatName string
+	outputPath string
+	ref        string
+	prefix     string
+)
+
+func init() {
+	Command.Flags().StringVar(&formatName, "format", "", "format of the archive: 'tar', 'tar.gz', or 'zip' (defaults to inferring it from --output)")
+	Command.Flags().StringVarP(&outputPath, "output", "o", "", "file to write the archive to (defaults to stdout)")
+	Command.Flags().StringVar(&ref, "ref", "", "revision to archive (defaults to the tree's HEAD)")
+	Command.Flags().StringVar(&prefix, "prefix", "", "directory to place every file beneath within the archive")
+}
+
+// Archive writes the files of the tree at the given path, or the tree containing the current directory if path is
+// empty, to the given output file as an archive. If output is empty, the archive is written to stdout
+func Archive(path, revision string, format local.ArchiveFormat, prefix, output string) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	if path == "" {
+		tree, err := g.CurrentTree()
+		if err != nil {
+			return err
+		}
+		path = tree.Path
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create archive %q: %w", output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	err = g.Archive(w, path, revision, format, prefix)
+	if err != nil {
+		if output != "" {
+			// Don't leave a truncated archive behind
+			os.Remove(output)
+		}
+		return fmt.Errorf("failed to archive tree %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package branch
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "branch",
+	Short: "List the branches which can be checked out in the grove",
+	Long: `Lists every branch of the grove - both local branches, and the branches of its remotes as of their last fetch -
+along with the tree each is checked out in, if any. A local branch and the remote branches of the same name are listed
+once. As with 'git branch', branches are listed whether or not --list is given.
+
+With --missing, only the branches without a tree are listed: those which can be checked out with 'grove add'.`,
+	Example: `
+List the branches fetched from the remote which have no tree yet:
+
+	grove fetch && grove branch --list --missing
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		format, err := output.ParseResultFormat(cmd.Flag(output.ResultFormatFlag).Value.String())
+		if err != nil {
+			return err
+		}
+		return List(missing, format)
+	},
+}
+
+var (
+	list    bool
+	missing bool
+)
+
+func init() {
+	Command.Flags().BoolVarP(&list, "list", "l", false, "list branches, which is also the default")
+	Command.Flags().BoolVar(&missing, "missing", false, "only list branches which are not checked out in any tree")
+}
+
+// List prints the grove's branches in the given format. If missing is set, only branches without a tree are printed
+func List(missing bool, format output.ResultFormat) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return fmt.Errorf("failed to determine grove root: %w", err)
+	}
+
+	branches, err := g.Branches()
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+	if missing {
+		filtered := []grove.BranchInfo{}
+		for _, branch := range branches {
+			if branch.Tree == "" {
+				filtered = append(filtered, branch)
+			}
+		}
+		branches = filtered
+	}
+
+	return output.Render(os.Stdout, format, branches, func(out io.Writer) error {
+		return printTable(out, root, branches)
+	})
+}
+
+// printTable writes branches to out as a table, with the path of each tree relative to the grove's root
+func printTable(out io.Writer, root string, branches []grove.BranchInfo) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BRANCH\tLOCAL\tREMOTES\tTREE")
+	for _, branch := range branches {
+		local := "no"
+		if branch.Local {
+			local = "yes"
+		}
+
+		remotes := "-"
+		if len(branch.Remotes) > 0 {
+			remotes = strings.Join(branch.Remotes, ",")
+		}
+
+		tree := "-"
+		if branch.Tree != "" {
+			relative, err := filepath.Rel(root, branch.Tree)
+			if err != nil {
+				relative = branch.Tree
+			}
+			tree = relative
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", branch.Name, local, remotes, tree)
+	}
+	return w.Flush()
+}
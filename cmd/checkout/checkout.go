@@ -0,0 +1,67 @@
+package checkout
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "checkout <branch-or-ref>",
+	Short: "Check out a branch or ref in the current tree",
+	Long: `Checks out a branch or ref in the tree containing the current directory, as with 'git checkout'.
+
+A local branch is checked out as is. A branch which only exists on a remote - such as 'feature', when only
+'origin/feature' has been fetched - is created from the remote's branch, and tracks it. Any other ref, such as a tag,
+commit, or remote-tracking branch, is checked out with a detached HEAD. A branch checked out in another tree cannot be
+checked out: switch to that tree instead, with 'grove switch'.
+
+If the tree has uncommitted changes to tracked files, the checkout is refused, as they would be lost - unless --force is
+provided, in which case they are discarded. Untracked files are left in place.`,
+	Example: `
+Check out the tag v1.2.0 in the current tree, to reproduce a bug:
+
+	grove checkout v1.2.0
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		return Checkout(args[0], force)
+	},
+}
+
+var force bool
+
+func init() {
+	Command.Flags().BoolVarP(&force, "force", "f", false, "discard uncommitted changes to tracked files, rather than refusing to check out")
+}
+
+// Checkout checks out ref in the tree containing the current directory. Unless force is set, the checkout is refused
+// if the tree has uncommitted changes
+func Checkout(ref string, force bool) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	tree, err := g.CurrentTree()
+	if err != nil {
+		return fmt.Errorf("failed to determine current tree: %w", err)
+	}
+
+	result, err := g.CheckoutTree(tree.Path, ref, force)
+	if err != nil {
+		return fmt.Errorf("failed to check out %q in tree %q: %w", ref, tree.Path, err)
+	}
+
+	switch {
+	case result.Created:
+		fmt.Printf("checked out new branch %q, tracking the remote's branch\n", result.Branch)
+	case result.Branch != "":
+		fmt.Printf("checked out branch %q\n", result.Branch)
+	default:
+		fmt.Printf("checked out %.7s with a detached HEAD\n", result.Commit)
+	}
+	return nil
+}
@@ -1,12 +1,53 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/tnierman/git-grove/cmd/add"
+	"github.com/tnierman/git-grove/cmd/aheadbehind"
+	"github.com/tnierman/git-grove/cmd/archive"
+	"github.com/tnierman/git-grove/cmd/branch"
+	"github.com/tnierman/git-grove/cmd/checkout"
+	"github.com/tnierman/git-grove/cmd/commit"
+	"github.com/tnierman/git-grove/cmd/config"
 	"github.com/tnierman/git-grove/cmd/convert"
+	"github.com/tnierman/git-grove/cmd/defaultbranch"
+	"github.com/tnierman/git-grove/cmd/diff"
+	"github.com/tnierman/git-grove/cmd/exectree"
+	"github.com/tnierman/git-grove/cmd/fetch"
+	"github.com/tnierman/git-grove/cmd/foreach"
+	"github.com/tnierman/git-grove/cmd/gc"
+	"github.com/tnierman/git-grove/cmd/grep"
+	"github.com/tnierman/git-grove/cmd/importtree"
+	"github.com/tnierman/git-grove/cmd/info"
 	"github.com/tnierman/git-grove/cmd/initialize"
+	"github.com/tnierman/git-grove/cmd/list"
+	"github.com/tnierman/git-grove/cmd/lock"
+	"github.com/tnierman/git-grove/cmd/log"
+	"github.com/tnierman/git-grove/cmd/open"
+	"github.com/tnierman/git-grove/cmd/pr"
+	"github.com/tnierman/git-grove/cmd/prune"
+	"github.com/tnierman/git-grove/cmd/pull"
+	"github.com/tnierman/git-grove/cmd/push"
+	"github.com/tnierman/git-grove/cmd/relocate"
+	"github.com/tnierman/git-grove/cmd/remote"
+	"github.com/tnierman/git-grove/cmd/renamebranch"
+	"github.com/tnierman/git-grove/cmd/reset"
+	"github.com/tnierman/git-grove/cmd/snapshot"
+	"github.com/tnierman/git-grove/cmd/stash"
+	"github.com/tnierman/git-grove/cmd/status"
+	"github.com/tnierman/git-grove/cmd/switchtree"
+	"github.com/tnierman/git-grove/cmd/treepath"
+	"github.com/tnierman/git-grove/cmd/unlock"
+	"github.com/tnierman/git-grove/cmd/verifyremote"
+	pkgconfig "github.com/tnierman/git-grove/pkg/config"
+	gitremote "github.com/tnierman/git-grove/pkg/git/remote"
+	"github.com/tnierman/git-grove/pkg/output"
 )
 
 // grove represents the base command when called without any subcommands
@@ -16,18 +57,161 @@ var grove = &cobra.Command{
 	RunE: func(cmd *cobra.Command, _ []string) error {
 		return cmd.Help()
 	},
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		return applyConfig(cmd)
+	},
 }
 
 func init() {
+	// Parsed by each read command - 'list', 'status', 'info', 'ahead-behind', 'branch', and 'log' - rendering its results via
+	// output.Render
+	grove.PersistentFlags().String(output.ResultFormatFlag, string(output.ResultFormatTable), "format of the results of read commands: 'table' for human-readable output, 'json', or 'yaml'")
+
 	grove.AddCommand(add.Command)
+	grove.AddCommand(aheadbehind.Command)
+	grove.AddCommand(archive.Command)
+	grove.AddCommand(branch.Command)
+	grove.AddCommand(initalize.CloneCommand)
+	grove.AddCommand(checkout.Command)
+	grove.AddCommand(commit.Command)
+	grove.AddCommand(config.Command)
 	grove.AddCommand(convert.Command)
+	grove.AddCommand(defaultbranch.Command)
+	grove.AddCommand(diff.Command)
+	grove.AddCommand(exectree.Command)
+	grove.AddCommand(fetch.Command)
+	grove.AddCommand(foreach.Command)
+	grove.AddCommand(gc.Command)
+	grove.AddCommand(grep.Command)
+	grove.AddCommand(importtree.Command)
+	grove.AddCommand(info.Command)
 	grove.AddCommand(initalize.Command)
+	grove.AddCommand(list.Command)
+	grove.AddCommand(lock.Command)
+	grove.AddCommand(log.Command)
+	grove.AddCommand(open.Command)
+	grove.AddCommand(pr.Command)
+	grove.AddCommand(prune.Command)
+	grove.AddCommand(pull.Command)
+	grove.AddCommand(push.Command)
+	grove.AddCommand(relocate.Command)
+	grove.AddCommand(remote.Command)
+	grove.AddCommand(renamebranch.Command)
+	grove.AddCommand(reset.Command)
+	grove.AddCommand(snapshot.Command)
+	grove.AddCommand(stash.Command)
+	grove.AddCommand(status.Command)
+	grove.AddCommand(switchtree.Command)
+	grove.AddCommand(treepath.Command)
+	grove.AddCommand(unlock.Command)
+	grove.AddCommand(verifyremote.Command)
 }
 
 func Grove() error {
 	err := grove.Execute()
 	if err != nil {
+		printGuidance(err)
 		return fmt.Errorf("failed to execute command: %w", err)
 	}
 	return nil
 }
+
+// printGuidance suggests how to resolve errors communicating with a remote or cloning it, depending on the kind of
+// failure
+func printGuidance(err error) {
+	noSpace := &gitremote.NoSpaceError{}
+	switch {
+	case errors.As(err, &noSpace):
+		fmt.Fprintf(os.Stderr, "hint: free up space on the disk holding %q, or reduce the size of the clone with --depth or --single-branch\n", noSpace.Path)
+	case errors.Is(err, gitremote.ErrAuthFailed):
+		fmt.Fprintln(os.Stderr, "hint: check your credentials: for HTTP(S) remotes, provide an access token with --token or via a git credential helper; for SSH remotes, check the key loaded in your SSH agent or given with --identity-file")
+	case errors.Is(err, gitremote.ErrUnreachable):
+		fmt.Fprintln(os.Stderr, "hint: check your network connection, and that the remote's URL is correct")
+	case errors.Is(err, gitremote.ErrNoDefaultBranch):
+		fmt.Fprintln(os.Stderr, "hint: the remote's HEAD does not point to a branch; use --branch to choose the branch to check out")
+	}
+}
+
+// applyConfig sets each of the command's flags which was not given on the command line to its default from the
+// environment, if any, or otherwise its configured default. Defaults are read from the GROVE_* variables named by
+// envDefault, then the '<command>.<flag>' keys of grove's configuration files - whose command is the full path of
+// subcommands, such as 'remote.add.fetch' - and a warning is printed for each key which is invalid. Flags confirming
+// destructive operations, such as --yes, are never defaulted. Defaults are set as though given on the command line, so
+// that cobra then checks them against the command's groups of mutually exclusive and required flags
+func applyConfig(cmd *cobra.Command) error {
+	cfg, err := pkgconfig.LoadAll(config.CurrentRoot())
+	if err != nil {
+		return err
+	}
+	// 'grove config --edit' checks the file once it has been edited instead
+	if cmd != config.Command {
+		config.WarnInvalid(cmd.Root(), cfg)
+	}
+
+	key := strings.Join(commandPath(cmd), ".")
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "help" || pkgconfig.ExplicitOnly(flag.Name) {
+			return
+		}
+		value, name, ok := envDefault(cmd, flag)
+		if ok {
+			setErr := cmd.Flags().Set(flag.Name, value)
+			if setErr != nil {
+				err = fmt.Errorf("invalid value %q in $%s for --%s: %w", value, name, flag.Name, setErr)
+			}
+			return
+		}
+
+		value, ok, getErr := cfg.Get(key + "." + flag.Name)
+		if getErr != nil || !ok {
+			return
+		}
+		setErr := cmd.Flags().Set(flag.Name, value)
+		if setErr != nil {
+			err = fmt.Errorf("invalid value %q configured for %s.%s: %w", value, key, flag.Name, setErr)
+		}
+	})
+	return err
+}
+
+// commandPath gives the names of the commands leading from grove's root command to cmd, such as 'remote', 'add' for
+// 'grove remote add', so that subcommands sharing a name are told apart
+func commandPath(cmd *cobra.Command) []string {
+	path := []string{}
+	for ; cmd.HasParent(); cmd = cmd.Parent() {
+		path = append([]string{cmd.Name()}, path...)
+	}
+	return path
+}
+
+// envAliases are the shorter names of the environment variables setting the defaults of some flags, which are
+// accepted in addition to those named after the flag
+var envAliases = map[string]string{
+	"identity-file": "GROVE_IDENTITY",
+}
+
+// envDefault looks up the default of the command's flag in the environment, returning its value and the name of the
+// variable it was read from. A command's own variable, GROVE_<COMMAND>_<FLAG> - such as GROVE_INIT_DEPTH, or
+// GROVE_REMOTE_ADD_FETCH for a subcommand - takes precedence over GROVE_<FLAG>, which applies to every command with the
+// flag
+func envDefault(cmd *cobra.Command, flag *pflag.Flag) (string, string, bool) {
+	names := []string{envName(append(commandPath(cmd), flag.Name)...), envName(flag.Name)}
+	alias, ok := envAliases[flag.Name]
+	if ok {
+		names = append(names, alias)
+	}
+	for _, name := range names {
+		value, ok := os.LookupEnv(name)
+		if ok {
+			return value, name, true
+		}
+	}
+	return "", "", false
+}
+
+// envName derives the name of an environment variable from the given words, such as GROVE_ALL_BRANCHES from
+// 'all-branches'
+func envName(words ...string) string {
+	name := "GROVE_" + strings.Join(words, "_")
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestEnvDefault(t *testing.T) {
+	root := &cobra.Command{Use: "grove"}
+	initCmd := &cobra.Command{Use: "init"}
+	initCmd.Flags().Int("depth", 0, "")
+	initCmd.Flags().String("identity-file", "", "")
+	remote := &cobra.Command{Use: "remote"}
+	add := &cobra.Command{Use: "add"}
+	add.Flags().Bool("fetch", false, "")
+	topLevelAdd := &cobra.Command{Use: "add"}
+	topLevelAdd.Flags().Bool("fetch", false, "")
+	remote.AddCommand(add)
+	root.AddCommand(initCmd, remote, topLevelAdd)
+
+	tests := []struct {
+		name     string
+		cmd      *cobra.Command
+		flag     string
+		env      map[string]string
+		want     string
+		wantName string
+	}{
+		{name: "unset", cmd: initCmd, flag: "depth"},
+		{name: "command", cmd: initCmd, flag: "depth", env: map[string]string{"GROVE_INIT_DEPTH": "1"}, want: "1", wantName: "GROVE_INIT_DEPTH"},
+		{name: "every command", cmd: initCmd, flag: "depth", env: map[string]string{"GROVE_DEPTH": "2"}, want: "2", wantName: "GROVE_DEPTH"},
+		{
+			name:     "command takes precedence",
+			cmd:      initCmd,
+			flag:     "depth",
+			env:      map[string]string{"GROVE_INIT_DEPTH": "1", "GROVE_DEPTH": "2"},
+			want:     "1",
+			wantName: "GROVE_INIT_DEPTH",
+		},
+		{name: "alias", cmd: initCmd, flag: "identity-file", env: map[string]string{"GROVE_IDENTITY": "key"}, want: "key", wantName: "GROVE_IDENTITY"},
+		{
+			name:     "subcommand",
+			cmd:      add,
+			flag:     "fetch",
+			env:      map[string]string{"GROVE_REMOTE_ADD_FETCH": "true"},
+			want:     "true",
+			wantName: "GROVE_REMOTE_ADD_FETCH",
+		},
+		{name: "subcommand by name alone", cmd: add, flag: "fetch", env: map[string]string{"GROVE_ADD_FETCH": "true"}},
+		{
+			name:     "command sharing a subcommand's name",
+			cmd:      topLevelAdd,
+			flag:     "fetch",
+			env:      map[string]string{"GROVE_REMOTE_ADD_FETCH": "true", "GROVE_ADD_FETCH": "false"},
+			want:     "false",
+			wantName: "GROVE_ADD_FETCH",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, name := range []string{"GROVE_INIT_DEPTH", "GROVE_DEPTH", "GROVE_IDENTITY_FILE", "GROVE_IDENTITY", "GROVE_REMOTE_ADD_FETCH", "GROVE_ADD_FETCH", "GROVE_FETCH"} {
+				// Restored once the test ends
+				t.Setenv(name, "")
+				os.Unsetenv(name)
+			}
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			value, name, ok := envDefault(tt.cmd, tt.cmd.Flags().Lookup(tt.flag))
+			if ok != (tt.wantName != "") || value != tt.want || name != tt.wantName {
+				t.Errorf("envDefault() = %q, %q, %v, want %q, %q", value, name, ok, tt.want, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestApplyConfigLeavesExplicitFlags(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Chdir(t.TempDir())
+	writeConfig(t, filepath.Join(configHome, "grove", "config"), "[prune]\n\tyes = true\n\tdepth = 2\n[reset]\n\tforce = true\n")
+
+	root := &cobra.Command{Use: "grove"}
+	prune := &cobra.Command{Use: "prune"}
+	prune.Flags().Bool("yes", false, "")
+	prune.Flags().Int("depth", 0, "")
+	reset := &cobra.Command{Use: "reset"}
+	reset.Flags().Bool("force", false, "")
+	push := &cobra.Command{Use: "push"}
+	push.Flags().Bool("force-with-lease", false, "")
+	root.AddCommand(prune, reset, push)
+
+	tests := []struct {
+		name string
+		cmd  *cobra.Command
+		flag string
+		env  map[string]string
+		want string
+	}{
+		{name: "configured yes", cmd: prune, flag: "yes", want: "false"},
+		{name: "configured force", cmd: reset, flag: "force", want: "false"},
+		{name: "yes from environment", cmd: prune, flag: "yes", env: map[string]string{"GROVE_YES": "true", "GROVE_PRUNE_YES": "true"}, want: "false"},
+		{name: "force from environment", cmd: reset, flag: "force", env: map[string]string{"GROVE_FORCE": "1"}, want: "false"},
+		{name: "force-with-lease from environment", cmd: push, flag: "force-with-lease", env: map[string]string{"GROVE_FORCE_WITH_LEASE": "1"}, want: "false"},
+		// Other flags of the same command are still defaulted
+		{name: "other flag", cmd: prune, flag: "depth", want: "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			for _, c := range []*cobra.Command{prune, reset, push} {
+				c.Flags().VisitAll(func(flag *pflag.Flag) {
+					_ = flag.Value.Set(flag.DefValue)
+					flag.Changed = false
+				})
+			}
+
+			err := applyConfig(tt.cmd)
+			if err != nil {
+				t.Fatalf("applyConfig() returned error: %v", err)
+			}
+			got := tt.cmd.Flags().Lookup(tt.flag).Value.String()
+			if got != tt.want {
+				t.Errorf("--%s = %s, want %s", tt.flag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyConfigFlagGroups(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Chdir(t.TempDir())
+	writeConfig(t, filepath.Join(configHome, "grove", "config"), "[clone]\n\tmirror = true\n")
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "no defaults", args: []string{"init", "--single-branch"}},
+		{name: "default alone", args: []string{"init"}, env: map[string]string{"GROVE_ALL_BRANCHES": "true"}},
+		{
+			name:    "default excluded by flag",
+			args:    []string{"init", "--single-branch"},
+			env:     map[string]string{"GROVE_ALL_BRANCHES": "true"},
+			wantErr: true,
+		},
+		{
+			name:    "defaults excluding each other",
+			args:    []string{"init"},
+			env:     map[string]string{"GROVE_INIT_ALL_BRANCHES": "true", "GROVE_SINGLE_BRANCH": "true"},
+			wantErr: true,
+		},
+		{name: "configured default excluded by flag", args: []string{"clone", "--reference", "../other"}, wantErr: true},
+		{name: "configured default required with another flag", args: []string{"clone"}, wantErr: true},
+		{name: "configured default given its required flag", args: []string{"clone", "--bare"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			root := &cobra.Command{
+				Use:               "grove",
+				SilenceErrors:     true,
+				SilenceUsage:      true,
+				PersistentPreRunE: func(cmd *cobra.Command, _ []string) error { return applyConfig(cmd) },
+			}
+			initCmd := &cobra.Command{Use: "init", Run: func(*cobra.Command, []string) {}}
+			initCmd.Flags().Bool("all-branches", false, "")
+			initCmd.Flags().Bool("single-branch", false, "")
+			initCmd.MarkFlagsMutuallyExclusive("all-branches", "single-branch")
+			clone := &cobra.Command{Use: "clone", Run: func(*cobra.Command, []string) {}}
+			clone.Flags().Bool("mirror", false, "")
+			clone.Flags().Bool("bare", false, "")
+			clone.Flags().String("reference", "", "")
+			clone.MarkFlagsMutuallyExclusive("mirror", "reference")
+			clone.MarkFlagsRequiredTogether("mirror", "bare")
+			root.AddCommand(initCmd, clone)
+			root.SetArgs(tt.args)
+
+			err := root.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("grove %v returned error %v, want error: %v", tt.args, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// writeConfig writes a configuration file at path with the given content
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		t.Fatalf("failed to create directory %q: %v", filepath.Dir(path), err)
+	}
+	err = os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write %q: %v", path, err)
+	}
+}
@@ -0,0 +1,83 @@
+package commit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/git/local"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "commit [<tree>] -m <message>",
+	Short: "Commit the staged changes of a tree",
+	Long: `Commits the changes staged in a tree, attributing the commit to the identity configured by user.name and user.email.
+If no tree is given, the tree containing the current directory is used. With --all, changes to tracked files are staged
+first, as with 'git commit -a'; untracked files are never staged.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
+
+This is a thin convenience for scripts which otherwise only need grove. It does not replace git: to stage individual files
+or hunks, amend commits, or sign them, use 'git add' and 'git commit' within the tree.`,
+	Example: `
+Commit every change to tracked files in the tree for the 'feature' branch:
+
+	grove commit feature --all -m "Add feature"
+	`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+		return Commit(path, message, all)
+	},
+}
+
+var (
+	message string
+	all     bool
+)
+
+func init() {
+	Command.Flags().StringVarP(&message, "message", "m", "", "the commit message")
+	Command.Flags().BoolVarP(&all, "all", "a", false, "stage changes to tracked files before committing")
+	_ = Command.MarkFlagRequired("message")
+}
+
+// Commit commits the changes staged in the tree at the given path, or the tree containing the current directory if
+// path is empty. If all is set, changes to tracked files are staged first
+func Commit(path, message string, all bool) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	tree, err := g.CurrentOrNamedTree(path)
+	if err != nil {
+		return err
+	}
+
+	hash, err := g.Commit(tree.Path, message, all)
+	if err != nil {
+		if errors.Is(err, local.ErrNothingToCommit) && !all {
+			return fmt.Errorf("failed to commit in tree %q: %w (stage changes with 'git add', or use --all)", tree.Path, err)
+		}
+		return fmt.Errorf("failed to commit in tree %q: %w", tree.Path, err)
+	}
+
+	branch := tree.Branch
+	if branch == "" {
+		branch = "detached HEAD"
+	}
+	fmt.Printf("[%s %.7s] %s\n", branch, hash, firstLine(message))
+	return nil
+}
+
+// firstLine returns the first line of the given message
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+	return line
+}
@@ -0,0 +1,318 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/config"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "config",
+	Short: "Manage grove's default settings",
+	Long: `Manages the defaults of grove's flags, which are stored as '<command>.<flag>' keys in configuration files - or, for
+subcommands, keys naming the full command, such as 'remote.add.fetch' for 'grove remote add --fetch'.
+
+Two configuration files are consulted: the global file in the user's configuration directory (typically
+~/.config/grove/config), and the grove's own file at .grove/config in its root directory. Flags given on the command
+line override the grove's settings, which in turn override the global settings and grove's built-in defaults.
+
+Defaults may also be set by environment variables, such as from a shell profile or CI environment, which override
+both configuration files. Each flag's default is read from GROVE_<COMMAND>_<FLAG>, such as GROVE_INIT_DEPTH, or
+GROVE_REMOTE_ADD_FETCH for a subcommand, or otherwise from GROVE_<FLAG>, such as GROVE_ALL_BRANCHES - which applies
+to every command with the flag. --identity-file may also be set by GROVE_IDENTITY.
+
+Flags confirming destructive operations - --yes, --force, and --force-with-lease - have no defaults: they are only
+honored when given on the command line, so that a forgotten setting can't remove or discard anything unasked.
+
+Settings are written to the grove's file, unless --global is provided. --edit opens the file in $VISUAL or $EDITOR
+instead, creating it if necessary.
+
+Values may refer to environment variables as '${NAME}', which are expanded when the configuration is read. Settings
+which do not name a flag of one of grove's commands, or whose value is invalid for it, produce a warning.`,
+	Example: `
+Always clone with a depth of 1:
+
+	grove config set --global init.depth 1
+
+Use a specific SSH key for the current grove's remote operations:
+
+	grove config set init.identity-file ~/.ssh/work_ed25519
+
+Use the same SSH key on every machine, wherever the home directory is:
+
+	grove config set --global init.identity-file '${HOME}/.ssh/id_ed25519'
+
+Create a tree for every branch whenever a grove is initialized in CI:
+
+	export GROVE_ALL_BRANCHES=true
+
+Edit the global configuration file directly:
+
+	grove config --edit --global
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if edit {
+			return Edit(cmd.Root(), global)
+		}
+		return cmd.Help()
+	},
+}
+
+var getCommand = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the value of a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		return Get(args[0], global)
+	},
+}
+
+var setCommand = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set the value of a setting",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 2 arguments to this command
+		key, value := args[0], args[1]
+		err := validate(cmd.Root(), key, value)
+		if err != nil {
+			return err
+		}
+		return Set(key, value, global)
+	},
+}
+
+var unsetCommand = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		return Unset(args[0], global)
+	},
+}
+
+var listCommand = &cobra.Command{
+	Use:   "list",
+	Short: "List every setting",
+	Long:  `Lists the effective value of every setting, along with the scope of the file which sets it.`,
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return List(global)
+	},
+}
+
+var (
+	global bool
+	edit   bool
+)
+
+func init() {
+	Command.PersistentFlags().BoolVar(&global, "global", false, "use only the global configuration file, rather than the grove's")
+	Command.Flags().BoolVar(&edit, "edit", false, "open the configuration file in $VISUAL or $EDITOR")
+	Command.AddCommand(getCommand)
+	Command.AddCommand(setCommand)
+	Command.AddCommand(unsetCommand)
+	Command.AddCommand(listCommand)
+}
+
+// Get prints the effective value of the given key. If global is set, only the global configuration file is consulted
+func Get(key string, global bool) error {
+	cfg, err := load(global)
+	if err != nil {
+		return err
+	}
+	value, ok, err := cfg.Get(key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%q is not set", key)
+	}
+	fmt.Println(value)
+	return nil
+}
+
+// Set sets the given key to value in the grove's configuration file, or in the global configuration file if global is set
+func Set(key, value string, global bool) error {
+	file, err := file(global)
+	if err != nil {
+		return err
+	}
+	err = file.Set(key, value)
+	if err != nil {
+		return err
+	}
+	return file.Save()
+}
+
+// Unset removes the given key from the grove's configuration file, or from the global configuration file if global is set
+func Unset(key string, global bool) error {
+	file, err := file(global)
+	if err != nil {
+		return err
+	}
+	ok, err := file.Unset(key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%q is not set in %q", key, file.Path)
+	}
+	return file.Save()
+}
+
+// List prints the effective value of every key. If global is set, only the global configuration file is consulted
+func List(global bool) error {
+	cfg, err := load(global)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSCOPE")
+	for _, setting := range cfg.Settings() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, setting.Value, setting.Scope)
+	}
+	return w.Flush()
+}
+
+// Edit opens the grove's configuration file, or the global configuration file if global is set, in $VISUAL or
+// $EDITOR, creating it if necessary. Once the editor exits, a warning is printed for each invalid setting in the file
+func Edit(root *cobra.Command, global bool) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		return fmt.Errorf("no editor configured: set $VISUAL or $EDITOR")
+	}
+
+	f, err := file(global)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = f.Save()
+	}
+	if err != nil {
+		return err
+	}
+
+	// The editor is run via the shell so that it may contain its own arguments; "$@" expands to the file's path
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, f.Path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("failed to edit %q with %q: %w", f.Path, editor, err)
+	}
+
+	edited, err := config.Load(f.Path, f.Scope)
+	if err != nil {
+		return err
+	}
+	WarnInvalid(root, &config.Config{Files: []*config.File{edited}})
+	return nil
+}
+
+// WarnInvalid prints a warning for each setting in cfg which does not name a flag of one of root's commands, or whose
+// value is invalid for it
+func WarnInvalid(root *cobra.Command, cfg *config.Config) {
+	for _, f := range cfg.Files {
+		for _, setting := range f.Settings() {
+			err := validate(root, setting.Key, config.Expand(setting.Value))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v, in %q\n", err, f.Path)
+			}
+		}
+	}
+}
+
+// load reads the configuration files applicable to the current directory, or only the global configuration file
+// if global is set
+func load(global bool) (*config.Config, error) {
+	if global {
+		return config.LoadAll("")
+	}
+	return config.LoadAll(CurrentRoot())
+}
+
+// file reads the configuration file which settings are written to: the current grove's, or the global file if
+// global is set
+func file(global bool) (*config.File, error) {
+	if global {
+		path, err := config.GlobalPath()
+		if err != nil {
+			return nil, err
+		}
+		return config.Load(path, config.ScopeGlobal)
+	}
+
+	root := CurrentRoot()
+	if root == "" {
+		return nil, fmt.Errorf("not within a grove: use --global to change the global configuration")
+	}
+	return config.Load(config.GrovePath(root), config.ScopeGrove)
+}
+
+// CurrentRoot returns the root directory of the grove containing the current directory, or "" if there is none
+func CurrentRoot() string {
+	g, err := grove.Open()
+	if err != nil {
+		return ""
+	}
+	root, err := g.Root()
+	if err != nil {
+		return ""
+	}
+	return root
+}
+
+// validate ensures that key names a flag of one of grove's commands, and that value is valid for it
+func validate(root *cobra.Command, key, value string) error {
+	section, subsection, name, err := config.ParseKey(key)
+	if err != nil {
+		return err
+	}
+
+	path := []string{section}
+	if subsection != "" {
+		path = append(path, strings.Split(subsection, ".")...)
+	}
+	command := strings.Join(path, " ")
+	cmd, rest, err := root.Find(path)
+	if err != nil || cmd == root || len(rest) > 0 {
+		return fmt.Errorf("invalid key %q: no command named %q", key, command)
+	}
+	flag := cmd.Flags().Lookup(name)
+	if flag == nil {
+		return fmt.Errorf("invalid key %q: command %q has no flag named %q", key, command, name)
+	}
+	if config.ExplicitOnly(name) {
+		return fmt.Errorf("invalid key %q: --%s confirms a destructive operation, so must be given on the command line", key, name)
+	}
+
+	switch flag.Value.Type() {
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	case "int":
+		_, err = strconv.Atoi(value)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value %q for %q: expected a %s", value, key, flag.Value.Type())
+	}
+	return nil
+}
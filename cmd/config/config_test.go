@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestValidate(t *testing.T) {
+	root := &cobra.Command{Use: "grove"}
+	initCmd := &cobra.Command{Use: "init", Run: func(*cobra.Command, []string) {}}
+	initCmd.Flags().Int("depth", 0, "")
+	remote := &cobra.Command{Use: "remote"}
+	add := &cobra.Command{Use: "add", Run: func(*cobra.Command, []string) {}}
+	add.Flags().Bool("fetch", false, "")
+	remote.AddCommand(add)
+	prune := &cobra.Command{Use: "prune", Run: func(*cobra.Command, []string) {}}
+	prune.Flags().Bool("yes", false, "")
+	prune.Flags().Bool("force", false, "")
+	root.AddCommand(initCmd, remote, prune)
+
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{name: "command", key: "init.depth", value: "1"},
+		{name: "subcommand", key: "remote.add.fetch", value: "true"},
+		{name: "invalid value", key: "remote.add.fetch", value: "yes please", wantErr: true},
+		{name: "subcommand by name alone", key: "add.fetch", value: "true", wantErr: true},
+		{name: "unknown subcommand", key: "remote.rename.fetch", value: "true", wantErr: true},
+		{name: "flag of another command", key: "remote.add.depth", value: "1", wantErr: true},
+		{name: "unknown command", key: "clone.depth", value: "1", wantErr: true},
+		{name: "confirmation flag", key: "prune.yes", value: "true", wantErr: true},
+		{name: "force flag", key: "prune.force", value: "true", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(root, tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validate(%q, %q) returned error %v, want error: %v", tt.key, tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
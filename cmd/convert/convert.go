@@ -1,26 +1,45 @@
 package convert
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/tnierman/git-grove/pkg/git/local"
+	"github.com/tnierman/git-grove/pkg/grove"
 )
 
+const defaultDirectoryPermissions = 0o755
+
 var Command = &cobra.Command{
-	Use:   "convert <path>",
+	Use:   "convert [<path> [<destination>]]",
 	Short: "Convert an existing git repository to a grove",
-	Long:  "TODO",
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Converts the git repository at path - or the current directory, if none is given - to a grove, whose default tree
+is the repository itself, checked out as it is.
+
+By default, the grove is created in place: the repository is moved into a directory named after its default branch,
+beneath the directory it occupied, which becomes the grove's root. Given a destination, the grove is created there
+instead, and the repository is moved into it. The destination must be empty, or not yet exist, and must not be within
+the repository, or any other.`,
+	Example: `
+Convert the repository at ~/src/project into a grove at ~/groves/project, whose default tree is ~/groves/project/main:
+
+	grove convert ~/src/project ~/groves/project
+	`,
+	Args: cobra.MaximumNArgs(2),
 	RunE: func(_ *cobra.Command, args []string) error {
 		path := "."
 		if len(args) > 0 {
 			path = args[0]
 		}
+		dest := ""
+		if len(args) > 1 {
+			dest = args[1]
+		}
 
-		err := ToGrove(path)
+		err := ToGrove(path, dest)
 		if err != nil {
 			return fmt.Errorf("failed to convert %q to grove: %w", path, err)
 		}
@@ -28,10 +47,14 @@ var Command = &cobra.Command{
 	},
 }
 
-// TODO: this initial method sucks. Rather than move everything, recreate the original directory,
-// then move everything back, we can just create the grove in the tmp dir, and move it once to the designated path,
-// replacing the current directory
-func ToGrove(path string) error {
+// ToGrove converts the repository at path to a grove at dest, moving the repository into it as the default tree. If
+// dest is empty, the grove is created in place, at path
+func ToGrove(path, dest string) error {
+	err := checkStandalone(path)
+	if err != nil {
+		return err
+	}
+
 	// Open repository and retrieve defaultBranch name before moving to tmp dir
 	// Additionally, verifies that the provided path is a git directory before migrating anything
 	repo, err := local.NewRepository(path)
@@ -42,6 +65,13 @@ func ToGrove(path string) error {
 	if err != nil {
 		return fmt.Errorf("failed to determine default branch for %q: %w", path, err)
 	}
+	if dest != "" {
+		return toGroveAt(path, dest, defaultBranch)
+	}
+
+	// TODO: this initial method sucks. Rather than move everything, recreate the original directory,
+	// then move everything back, we can just create the grove in the tmp dir, and move it once to the designated path,
+	// replacing the current directory
 
 	// Migrate local repo to temporary directory
 	tmp, err := os.MkdirTemp(os.TempDir(), "convert-grove-*")
@@ -53,7 +83,7 @@ func ToGrove(path string) error {
 		// Using os.Remove() instead of os.RemoveAll() will cause an error if the directory is not empty, which is informative, in this case
 		tmpErr := os.Remove(tmp)
 		if tmpErr != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to cleanup directory %q: %w", err)
+			fmt.Fprintf(os.Stderr, "warning: failed to cleanup directory %q: %v\n", tmp, tmpErr)
 		}
 	}()
 
@@ -87,5 +117,122 @@ func ToGrove(path string) error {
 		return fmt.Errorf("failed to move temporary directory %q to new grove at %q: %w", tmpRelocationPath, defaultBranchPath, err)
 	}
 
+	// The grove is usable without its marker, its root then being derived from the repository's layout
+	err = grove.MarkRoot(abs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return nil
+}
+
+// toGroveAt creates a grove at dest, which must be empty or not yet exist, and moves the repository at path into it as
+// the tree for defaultBranch
+func toGroveAt(path, dest, defaultBranch string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to determine absolute path of %q: %w", path, err)
+	}
+	dest, err = filepath.Abs(dest)
+	if err != nil {
+		return fmt.Errorf("failed to determine absolute path of %q: %w", dest, err)
+	}
+
+	root, err := local.FindWorktreeRoot(dest)
+	if err == nil {
+		return fmt.Errorf("destination %q is within the existing grove or git repository at %q: choose a different directory", dest, root)
+	}
+	if !errors.Is(err, local.ErrNoWorktree) {
+		return fmt.Errorf("failed to check whether %q is within an existing repository: %w", dest, err)
+	}
+
+	created, err := newOrEmptyDir(dest)
+	if err != nil {
+		return fmt.Errorf("destination %q is invalid: %w", dest, err)
+	}
+
+	defaultBranchPath := filepath.Join(dest, defaultBranch)
+	err = os.MkdirAll(filepath.Dir(defaultBranchPath), defaultDirectoryPermissions)
+	if err == nil {
+		err = os.Rename(abs, defaultBranchPath)
+	}
+	if err != nil {
+		// Leave the destination as it was found
+		cleanupErr := os.RemoveAll(dest)
+		if !created && cleanupErr == nil {
+			cleanupErr = os.Mkdir(dest, defaultDirectoryPermissions)
+		}
+		if cleanupErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to cleanup directory %q: %v\n", dest, cleanupErr)
+		}
+		return fmt.Errorf("failed to move %q to new grove at %q: %w", abs, defaultBranchPath, err)
+	}
+
+	// The grove is usable without its marker, its root then being derived from the repository's layout
+	err = grove.MarkRoot(dest)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return nil
+}
+
+// newOrEmptyDir validates that the directory at path is empty, creating it if it does not exist. It reports whether
+// the directory was created
+func newOrEmptyDir(path string) (bool, error) {
+	files, err := os.ReadDir(path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = os.MkdirAll(path, defaultDirectoryPermissions)
+		if err != nil {
+			return false, fmt.Errorf("failed to create directory %q: %w", path, err)
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to open directory %q: %w", path, err)
+	}
+	if len(files) > 0 {
+		return false, fmt.Errorf("directory %q is not empty: found %q", path, files[0].Name())
+	}
+	return false, nil
+}
+
+// checkStandalone returns an error unless path is the root of a standalone repository's main worktree - rather than a
+// grove already, a tree of one, or a directory within a repository - as converting it would nest one grove in another
+func checkStandalone(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to determine absolute path of %q: %w", path, err)
+	}
+
+	isRoot, err := grove.IsRoot(abs)
+	if err != nil {
+		return err
+	}
+	if isRoot {
+		return fmt.Errorf("%q is already a grove", abs)
+	}
+
+	root, err := local.FindWorktreeRoot(abs)
+	if err != nil {
+		return fmt.Errorf("%q is not a git repository: %w", abs, err)
+	}
+	if root != abs {
+		return fmt.Errorf("%q is within the repository at %q: convert the repository's root instead", abs, root)
+	}
+
+	info, err := os.Stat(local.GitPath(root))
+	if err != nil {
+		return fmt.Errorf("failed to check %q: %w", local.GitPath(root), err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%q is a linked worktree of another repository, or a tree of an existing grove: convert the repository's main worktree instead", abs)
+	}
+
+	isRoot, err = grove.IsRoot(filepath.Dir(root))
+	if err != nil {
+		return err
+	}
+	if isRoot {
+		return fmt.Errorf("%q is the default tree of the existing grove at %q", abs, filepath.Dir(root))
+	}
 	return nil
 }
@@ -0,0 +1,153 @@
+package convert
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+func TestCheckStandalone(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare creates the directory to check within dir, returning its path
+		prepare func(t *testing.T, dir string) string
+		// wantErr is part of the error expected, or empty if the directory can be converted
+		wantErr string
+	}{
+		{
+			name: "standalone repository",
+			prepare: func(t *testing.T, dir string) string {
+				return newRepository(t, filepath.Join(dir, "repo"))
+			},
+		},
+		{
+			name: "directory within repository",
+			prepare: func(t *testing.T, dir string) string {
+				repo := newRepository(t, filepath.Join(dir, "repo"))
+				return mkdir(t, filepath.Join(repo, "sub"))
+			},
+			wantErr: "is within the repository",
+		},
+		{
+			name: "not a repository",
+			prepare: func(t *testing.T, dir string) string {
+				return mkdir(t, filepath.Join(dir, "plain"))
+			},
+			wantErr: "is not a git repository",
+		},
+		{
+			name: "grove",
+			prepare: func(t *testing.T, dir string) string {
+				return newGrove(t, filepath.Join(dir, "grove"))
+			},
+			wantErr: "is already a grove",
+		},
+		{
+			name: "bare grove",
+			prepare: func(t *testing.T, dir string) string {
+				root := filepath.Join(dir, "grove")
+				_, err := git.PlainInit(filepath.Join(root, grove.BareGitDir), true)
+				if err != nil {
+					t.Fatalf("failed to initialize repository: %v", err)
+				}
+				return root
+			},
+			wantErr: "is already a grove",
+		},
+		{
+			name: "default tree of grove",
+			prepare: func(t *testing.T, dir string) string {
+				return filepath.Join(newGrove(t, filepath.Join(dir, "grove")), "main")
+			},
+			wantErr: "is the default tree of the existing grove",
+		},
+		{
+			name: "linked worktree",
+			prepare: func(t *testing.T, dir string) string {
+				return filepath.Join(newGrove(t, filepath.Join(dir, "grove")), "feature")
+			},
+			wantErr: "is a linked worktree",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := filepath.EvalSymlinks(t.TempDir())
+			if err != nil {
+				t.Fatalf("failed to resolve temporary directory: %v", err)
+			}
+			path := tt.prepare(t, dir)
+
+			err = checkStandalone(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkStandalone(%q) returned error: %v", path, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkStandalone(%q) returned error %v, want one containing %q", path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newRepository creates a repository at path, with the branch 'main' checked out and a single commit, returning path
+func newRepository(t *testing.T, path string) string {
+	t.Helper()
+
+	repo, err := git.PlainInit(path, false, git.WithDefaultBranch(plumbing.NewBranchReferenceName("main")))
+	if err != nil {
+		t.Fatalf("failed to initialize repository: %v", err)
+	}
+	err = os.WriteFile(filepath.Join(path, "README.md"), []byte("repository\n"), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write README.md: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to open worktree %q: %v", path, err)
+	}
+	_, err = wt.Add("README.md")
+	if err != nil {
+		t.Fatalf("failed to stage README.md: %v", err)
+	}
+	signature := &object.Signature{Name: "grove", Email: "grove@example.com", When: time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)}
+	_, err = wt.Commit("add README.md", &git.CommitOptions{Author: signature, Committer: signature})
+	if err != nil {
+		t.Fatalf("failed to commit README.md: %v", err)
+	}
+	return path
+}
+
+// newGrove creates a grove at root, whose main worktree 'main' has a linked worktree 'feature', returning root
+func newGrove(t *testing.T, root string) string {
+	t.Helper()
+
+	g, err := grove.OpenAt(newRepository(t, filepath.Join(root, "main")))
+	if err != nil {
+		t.Fatalf("failed to open grove: %v", err)
+	}
+	err = g.AddTree("feature", grove.AddTreeOptions{NoHooks: true})
+	if err != nil {
+		t.Fatalf("failed to add tree: %v", err)
+	}
+	return root
+}
+
+// mkdir creates the directory at path, along with its parents, returning path
+func mkdir(t *testing.T, path string) string {
+	t.Helper()
+
+	err := os.MkdirAll(path, 0o755)
+	if err != nil {
+		t.Fatalf("failed to create directory %q: %v", path, err)
+	}
+	return path
+}
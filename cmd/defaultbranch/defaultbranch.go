@@ -0,0 +1,67 @@
+package defaultbranch
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "default-branch",
+	Short: "Print the grove's default branch",
+	Long: `Prints the grove's default branch, which trees are compared with and pruned against when no other branch is given.
+
+The default branch is the branch HEAD points to on the grove's remote, as recorded when the grove was cloned. If none is
+recorded, the branch the grove's repository HEAD points to is used instead.`,
+	Example: `
+Make 'develop' the grove's default branch:
+
+	grove default-branch set develop
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return Print()
+	},
+}
+
+var setCommand = &cobra.Command{
+	Use:   "set <branch>",
+	Short: "Change the grove's default branch",
+	Long: `Changes the grove's default branch to the given branch of the grove's remote - 'origin', if it exists.
+
+The branch must already have been fetched from the remote.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		return Set(args[0])
+	},
+}
+
+func init() {
+	Command.AddCommand(setCommand)
+}
+
+// Print prints the grove's default branch
+func Print() error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	branch, err := g.DefaultBranch()
+	if err != nil {
+		return err
+	}
+	fmt.Println(branch)
+	return nil
+}
+
+// Set changes the grove's default branch to the given branch
+func Set(branch string) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+	return g.SetDefaultBranch(branch)
+}
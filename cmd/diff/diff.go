@@ -0,0 +1,70 @@
+package diff
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "diff <from-tree> <to-tree>",
+	Short: "Compare the branches of two trees",
+	Long: `Prints the changes between the HEAD commits of two trees, as a unified diff. Uncommitted changes are not included.
+
+Each tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
+
+With --stat, only a summary of the lines changed in each file is printed. With --name-only, only the names of the
+changed files are printed.`,
+	Example: `
+Review the changes made on the branch in "feature-x", relative to "main":
+
+	grove diff main feature-x
+	`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 2 arguments to this command
+		return Diff(args[0], args[1], stat, nameOnly)
+	},
+}
+
+var (
+	stat     bool
+	nameOnly bool
+)
+
+func init() {
+	Command.Flags().BoolVar(&stat, "stat", false, "print a summary of the lines changed in each file, rather than the full diff")
+	Command.Flags().BoolVar(&nameOnly, "name-only", false, "print only the names of the changed files")
+	Command.MarkFlagsMutuallyExclusive("stat", "name-only")
+}
+
+// Diff prints the changes between the HEAD commits of the trees at the given paths
+func Diff(from, to string, stat, nameOnly bool) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	patch, err := g.Diff(from, to)
+	if err != nil {
+		return fmt.Errorf("failed to compare tree %q with tree %q: %w", from, to, err)
+	}
+
+	switch {
+	case stat:
+		fmt.Print(patch.Stats().String())
+	case nameOnly:
+		for _, filePatch := range patch.FilePatches() {
+			fromFile, toFile := filePatch.Files()
+			if toFile != nil {
+				fmt.Println(toFile.Path())
+				continue
+			}
+			fmt.Println(fromFile.Path())
+		}
+	default:
+		fmt.Print(patch.String())
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package exectree
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "exec --in <tree> -- <command> [<args>...]",
+	Short: "Run a command in a single tree",
+	Long: `Runs a command in the root directory of a single tree, from anywhere in the grove - without changing into it. To
+run a command in every tree instead, use 'grove foreach'.
+
+The tree is given by --in, as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute
+path is assumed.
+
+The command is run directly, rather than via the shell, with its input and output connected to grove's. grove exits with
+the command's exit status, so that scripts can rely on it.`,
+	Example: `
+Run the tests of the tree "feature-x":
+
+	grove exec --in feature-x -- go test ./...
+	`,
+	Args: cobra.MinimumNArgs(1),
+	// The command's own failure is reported by its exit status, rather than grove's usage
+	SilenceUsage: true,
+	RunE: func(_ *cobra.Command, args []string) error {
+		return Exec(in, args)
+	},
+}
+
+var in string
+
+func init() {
+	Command.Flags().StringVar(&in, "in", "", "tree to run the command in")
+	_ = Command.MarkFlagRequired("in")
+	// Treat everything following the command as part of it, rather than as flags to exec
+	Command.Flags().SetInterspersed(false)
+}
+
+// ExitError is returned by Exec when the command it runs exits with a non-zero status, so that grove can exit with the
+// same status. Exit statuses of commands grove runs for itself, such as git, are never propagated
+type ExitError struct {
+	// Command is the name of the command which failed
+	Command string
+	// Tree is the path of the tree the command was run in
+	Tree string
+	// Err is the command's exit status
+	Err *exec.ExitError
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command %q failed in tree %q: %v", e.Command, e.Tree, e.Err)
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the command's exit status, or -1 if it was killed by a signal
+func (e *ExitError) ExitCode() int {
+	return e.Err.ExitCode()
+}
+
+// Exec runs the command given by args in the root directory of the tree at the given path. If the command exits with a
+// non-zero status, an *ExitError is returned
+func Exec(path string, args []string) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	tree, err := g.Tree(path)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = tree.Path
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	exitErr := &exec.ExitError{}
+	if errors.As(err, &exitErr) {
+		return &ExitError{Command: args[0], Tree: tree.Path, Err: exitErr}
+	}
+	if err != nil {
+		return fmt.Errorf("command %q failed in tree %q: %w", args[0], tree.Path, err)
+	}
+	return nil
+}
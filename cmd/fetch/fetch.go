@@ -0,0 +1,179 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/git/remote"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "fetch [<remote>...]",
+	Short: "Fetch the branches of one or more remotes",
+	Long: `Updates the remote-tracking branches of the given remotes, or 'origin' if none is given. No tree is modified.
+
+With --all, every remote of the grove is fetched. When fetching several remotes, up to --jobs are fetched concurrently,
+and a summary of each is printed once it finishes. Credentials are obtained for every remote before any is fetched, so
+prompts for them are never interleaved. Progress is only shown when remotes are fetched one at a time.
+
+With --refspec, the given refspecs are fetched instead of the remote's configured ones. This allows refs which are not
+branches - such as the heads of GitHub pull requests, under 'refs/pull/' - to be fetched into remote-tracking refs.
+
+On constrained connections, --limit-rate caps the rate data is transferred at. Limiting is best-effort: only the data
+sent over the network is throttled, not the indexing of the objects received. SSH remotes are only throttled when
+$GIT_SSH_COMMAND is set. --timeout stops fetching once it has taken longer than the given duration.`,
+	Example: `
+Fetch every branch of the remote 'upstream':
+
+	grove fetch upstream
+
+Fetch a fork and the repository it was created from at once:
+
+	grove fetch --jobs 2 origin upstream
+
+Fetch the head of every pull request into 'origin/pr/<number>':
+
+	grove fetch --refspec '+refs/pull/*/head:refs/remotes/origin/pr/*'
+	`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(_ *cobra.Command, args []string) error {
+		if all && len(args) > 0 {
+			return fmt.Errorf("cannot name remotes to fetch when --all is given")
+		}
+		var rateLimit *remote.RateLimit
+		if limitRate != "" {
+			rate, err := remote.ParseRate(limitRate)
+			if err != nil {
+				return err
+			}
+			rateLimit = remote.NewRateLimit(rate)
+		}
+		if all || len(args) > 1 {
+			if len(refSpecs) > 0 {
+				return fmt.Errorf("--refspec can only be given when fetching a single remote")
+			}
+			return FetchRemotes(args, jobs, quiet, timeout, rateLimit)
+		}
+
+		name := remote.DefaultRemoteName
+		if len(args) > 0 {
+			name = args[0]
+		}
+		err := Fetch(name, refSpecs, quiet, timeout, rateLimit)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+var (
+	refSpecs  []string
+	quiet     bool
+	limitRate string
+	timeout   time.Duration
+	all       bool
+	jobs      int
+)
+
+func init() {
+	Command.Flags().StringArrayVar(&refSpecs, "refspec", nil, "refspec to fetch instead of the remote's configured refspecs (may be repeated)")
+	Command.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress progress output while fetching")
+	Command.Flags().BoolVar(&all, "all", false, "fetch every remote of the grove")
+	Command.Flags().IntVarP(&jobs, "jobs", "j", 1, "number of remotes fetched concurrently")
+	Command.Flags().DurationVar(&timeout, "timeout", 0, "how long fetching may take, such as '10m' (defaults to no limit)")
+	Command.Flags().StringVar(&limitRate, "limit-rate", "", "cap the transfer rate at the given bytes per second, optionally suffixed by 'k', 'm', or 'g' - such as '500k' (best-effort)")
+}
+
+// Fetch fetches the given refspecs - or the remote's configured refspecs, if none are given - from the named remote.
+// Fetching stops once it has taken longer than timeout, unless timeout is zero. Transfers are throttled by rateLimit,
+// if set
+func Fetch(name string, refSpecs []string, quiet bool, timeout time.Duration, rateLimit *remote.RateLimit) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+	g.RateLimit = rateLimit
+
+	auth, err := g.RemoteAuth(name)
+	if err != nil {
+		return err
+	}
+
+	var progress io.Writer = output.NewProgressBar(os.Stdout)
+	if quiet {
+		progress = io.Discard
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	err = g.Fetch(ctx, name, refSpecs, auth, progress)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("fetching from %q timed out after %s (raise --timeout): %w", name, timeout, err)
+	}
+	return err
+}
+
+// FetchRemotes fetches the configured refspecs of each of the named remotes - or every remote, if none are named -
+// fetching up to jobs remotes concurrently, then prints a summary of each. Fetching stops once it has taken longer
+// than timeout, unless timeout is zero. Transfers are throttled by rateLimit, if set, which is shared by every remote
+func FetchRemotes(names []string, jobs int, quiet bool, timeout time.Duration, rateLimit *remote.RateLimit) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+	g.RateLimit = rateLimit
+
+	if len(names) == 0 {
+		remotes, err := g.Remotes()
+		if err != nil {
+			return err
+		}
+		for _, r := range remotes {
+			names = append(names, r.Name)
+		}
+	}
+
+	// Progress bars of concurrent transfers would overwrite one another
+	var progress io.Writer = output.NewProgressBar(os.Stdout)
+	if quiet || jobs > 1 {
+		progress = io.Discard
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	results := g.FetchRemotes(ctx, names, jobs, progress)
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				result.Err = fmt.Errorf("timed out after %s (raise --timeout): %w", timeout, result.Err)
+			}
+			fmt.Fprintf(os.Stderr, "failed to fetch %q: %v\n", result.Remote, result.Err)
+			continue
+		}
+		if !quiet {
+			fmt.Printf("fetched %q in %s\n", result.Remote, result.Duration.Round(time.Millisecond))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to fetch %d of %d remotes", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package foreach
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "foreach <command>",
+	Short: "Run a templated command in every tree",
+	Long: `Runs the given shell command in the root directory of every tree in the grove.
+
+The command is a Go text/template, executed against each tree's metadata before it is run. The following fields are available:
+
+	{{.Branch}}  the branch checked out in the tree (empty when HEAD is detached)
+	{{.Path}}    the absolute path to the root of the tree
+	{{.Hash}}    the commit hash of the tree's HEAD
+
+Trees can be limited to those whose branch matches --match, and not --exclude. Each pattern is a glob, such as
+'release/*', or a regular expression wrapped in slashes, such as '/^v[0-9]+$/'.
+
+Each command's output is preceded by a header naming the tree, unless --quiet is provided. Every tree is visited even if
+the command fails in some of them.
+
+With --output-format json, a stream of newline-delimited JSON events is written instead: each tree's event holds the
+output of its command, along with any error.`,
+	Example: `
+Show the last commit on every tree:
+
+	grove foreach 'git log -1 --oneline'
+
+Print the branch and HEAD of every tree, without headers:
+
+	grove foreach --quiet 'echo {{.Branch}} {{.Hash}}'
+
+Pull every feature branch, except those still in draft:
+
+	grove foreach --match 'feature/*' --exclude 'feature/draft-*' 'git pull'
+	`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Allow the command to be given unquoted
+		command := strings.Join(args, " ")
+		events, err := output.EventsSelected(cmd.Flag(output.ResultFormatFlag).Value.String(), outputFormat, cmd.Name())
+		if err != nil {
+			return err
+		}
+		sel, err := grove.ParseSelector(match, exclude)
+		if err != nil {
+			return err
+		}
+		err = ForEach(command, quiet, events, sel)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+var (
+	quiet        bool
+	outputFormat string
+	match        []string
+	exclude      []string
+)
+
+func init() {
+	Command.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress the header printed before each tree's output")
+	Command.Flags().StringVar(&outputFormat, "output", string(output.FormatText), "format of results: 'text' for each command's raw output, or 'json' for a stream of newline-delimited JSON events")
+	_ = Command.Flags().MarkDeprecated("output", "use --output-format instead")
+	Command.Flags().StringSliceVar(&match, "match", nil, "only run in trees whose branch matches the given glob or /regex/ (may be repeated)")
+	Command.Flags().StringSliceVar(&exclude, "exclude", nil, "skip trees whose branch matches the given glob or /regex/ (may be repeated)")
+	// Treat everything following the command as part of it, rather than as flags to foreach
+	Command.Flags().SetInterspersed(false)
+}
+
+// ForEach renders the command template for each tree in the grove selected by sel and runs the result in the tree's root directory.
+// If events is set, a stream of JSON events is written instead of each command's raw output
+func ForEach(command string, quiet, events bool, sel grove.Selector) error {
+	tmpl, err := template.New("command").Option("missingkey=error").Parse(command)
+	if err != nil {
+		return fmt.Errorf("failed to parse command template: %w", err)
+	}
+
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return fmt.Errorf("failed to determine grove root: %w", err)
+	}
+
+	trees, err := g.Trees()
+	if err != nil {
+		return fmt.Errorf("failed to list trees: %w", err)
+	}
+	trees = sel.Select(trees)
+
+	if events {
+		err = forEachJSON(tmpl, trees)
+		if err != nil {
+			return err
+		}
+		return nil
+	}
+
+	failed := 0
+	for _, tree := range trees {
+		if !quiet {
+			name, err := filepath.Rel(root, tree.Path)
+			if err != nil {
+				name = tree.Path
+			}
+			fmt.Printf("==> %s <==\n", name)
+		}
+
+		err = run(tmpl, tree, os.Stdout, os.Stderr)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("command failed in %d of %d trees", failed, len(trees))
+	}
+	return nil
+}
+
+// forEachJSON runs the command template in each of the given trees, emitting an event with each tree's combined
+// output and error to stdout
+func forEachJSON(tmpl *template.Template, trees []grove.Tree) error {
+	events := output.NewEmitter(os.Stdout)
+	events.Started("foreach")
+
+	failed := 0
+	for _, tree := range trees {
+		out := &bytes.Buffer{}
+		event := output.Event{Type: output.EventTree, Tree: tree.Path, Branch: tree.Branch}
+		err := run(tmpl, tree, out, out)
+		if err != nil {
+			failed++
+			event.Error = err.Error()
+		}
+		event.Output = out.String()
+		events.Emit(event)
+	}
+
+	var err error
+	if failed > 0 {
+		err = fmt.Errorf("command failed in %d of %d trees", failed, len(trees))
+	}
+	events.Done(err)
+	return err
+}
+
+// run renders the command template for the given tree and runs it via the shell in the tree's root directory,
+// writing its output to stdout and stderr
+func run(tmpl *template.Template, tree grove.Tree, stdout, stderr io.Writer) error {
+	command := &bytes.Buffer{}
+	err := tmpl.Execute(command, tree)
+	if err != nil {
+		return fmt.Errorf("failed to render command for tree %q: %w", tree.Path, err)
+	}
+
+	cmd := exec.Command("sh", "-c", command.String())
+	cmd.Dir = tree.Path
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("command %q failed in tree %q: %w", command.String(), tree.Path, err)
+	}
+	return nil
+}
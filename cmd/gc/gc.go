@@ -0,0 +1,57 @@
+package gc
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "gc",
+	Short: "Compact the grove's shared object store",
+	Long: `Collects garbage in the grove's repository: loose objects are packed, and unreachable objects are removed. Every tree
+shares the repository's object store, so a single collection benefits the whole grove.
+
+'git gc' is run when git is installed, accounting for every tree's HEAD, index, and reflog. Otherwise, objects reachable
+from the repository's branches and tags are repacked in-process, which is refused while any tree has a detached HEAD.
+
+The size of the repository before and after collecting garbage is reported.`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return GC(aggressive, quiet)
+	},
+}
+
+var (
+	aggressive bool
+	quiet      bool
+)
+
+func init() {
+	Command.Flags().BoolVar(&aggressive, "aggressive", false, "recompute deltas more thoroughly, at the cost of a much slower collection (requires git)")
+	Command.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress output from the collection")
+}
+
+// GC collects garbage in the grove's repository, reporting how much its size changed
+func GC(aggressive, quiet bool) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	var out io.Writer = os.Stderr
+	if quiet {
+		out = io.Discard
+	}
+	result, err := g.GarbageCollect(aggressive, out)
+	if err != nil {
+		return fmt.Errorf("failed to collect garbage: %w", err)
+	}
+
+	fmt.Printf("repository size: %s -> %s\n", output.FormatBytes(result.Before), output.FormatBytes(result.After))
+	return nil
+}
@@ -0,0 +1,143 @@
+package grep
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+const (
+	// noMatchExitCode is the exit code 'git grep' returns when no lines matched
+	noMatchExitCode = 1
+)
+
+var Command = &cobra.Command{
+	Use:   "grep <pattern> [<pathspec>...]",
+	Short: "Search for a pattern across every tree",
+	Long: `Searches the tracked files of every tree in the grove for the given pattern, using 'git grep'.
+
+Each matching line is prefixed with the name of the tree it was found in - its path relative to the grove's root.
+Pathspecs may optionally be provided to limit the search to specific files within each tree.
+
+Trees can be limited to those whose branch matches --match, and not --exclude. Each pattern is a glob, such as
+'release/*', or a regular expression wrapped in slashes, such as '/^v[0-9]+$/'.`,
+	Example: `
+Find every TODO, case-insensitively, with line numbers:
+
+	grove grep -i -n todo
+
+Search only the trees of release branches:
+
+	grove grep --match 'release/*' CVE-2024
+	`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// MinimumNArgs ensures there's at least one argument to this command
+		pattern := args[0]
+		pathspecs := args[1:]
+		sel, err := grove.ParseSelector(append(match, branches...), exclude)
+		if err != nil {
+			return err
+		}
+		err = Grep(pattern, pathspecs, sel)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+var (
+	ignoreCase  bool
+	lineNumbers bool
+	branches    []string
+	match       []string
+	exclude     []string
+)
+
+func init() {
+	Command.Flags().BoolVarP(&ignoreCase, "ignore-case", "i", false, "ignore case differences between the pattern and the files")
+	Command.Flags().BoolVarP(&lineNumbers, "line-number", "n", false, "prefix each match with its line number")
+	Command.Flags().StringSliceVar(&match, "match", nil, "only search trees whose branch matches the given glob or /regex/ (may be repeated)")
+	Command.Flags().StringSliceVar(&exclude, "exclude", nil, "skip trees whose branch matches the given glob or /regex/ (may be repeated)")
+	Command.Flags().StringSliceVarP(&branches, "branch", "b", nil, "only search trees whose branch matches the given glob (may be repeated)")
+	_ = Command.Flags().MarkDeprecated("branch", "use --match instead")
+}
+
+// Grep searches each tree in the grove selected by sel for the given pattern
+func Grep(pattern string, pathspecs []string, sel grove.Selector) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return fmt.Errorf("failed to determine grove root: %w", err)
+	}
+
+	trees, err := g.Trees()
+	if err != nil {
+		return fmt.Errorf("failed to list trees: %w", err)
+	}
+
+	for _, tree := range sel.Select(trees) {
+		name, err := filepath.Rel(root, tree.Path)
+		if err != nil {
+			name = tree.Path
+		}
+
+		err = grep(tree.Path, name, pattern, pathspecs)
+		if err != nil {
+			return fmt.Errorf("failed to search tree %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// grep runs 'git grep' in the tree at the given path, printing each matching line prefixed with name
+func grep(dir, name, pattern string, pathspecs []string) error {
+	args := []string{"grep", "--no-color"}
+	if ignoreCase {
+		args = append(args, "--ignore-case")
+	}
+	if lineNumbers {
+		args = append(args, "--line-number")
+	}
+	args = append(args, "-e", pattern, "--")
+	args = append(args, pathspecs...)
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to capture output of 'git grep': %w", err)
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return fmt.Errorf("failed to run 'git grep': %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		fmt.Printf("%s:%s\n", name, scanner.Text())
+	}
+
+	err = cmd.Wait()
+	if err != nil {
+		exitErr := &exec.ExitError{}
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == noMatchExitCode {
+			return nil
+		}
+		return fmt.Errorf("'git grep' failed: %w", err)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,68 @@
+package importtree
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "import <path>",
+	Short: "Import a worktree created outside of grove",
+	Long: `Imports a worktree created outside of grove - for example, with 'git worktree add' - into the grove.
+
+The path is relative to the current directory, unless absolute. It must be a worktree of the grove's repository, or an
+error is returned.
+
+With --move, a worktree lying outside of the grove's root is moved beneath it: to the path given by --to - relative to
+the grove's root, unless prefixed by '/' - or, by default, to its branch's path according to --layout.`,
+	Example: `
+Import a worktree created with 'git worktree add ../hotfix', moving it into the grove:
+
+	grove import ../hotfix --move
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		l, err := grove.ParseLayout(layout)
+		if err != nil {
+			return err
+		}
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		return Import(args[0], to, move || cmd.Flags().Changed("to"), l)
+	},
+}
+
+var (
+	move   bool
+	to     string
+	layout string
+)
+
+func init() {
+	Command.Flags().BoolVar(&move, "move", false, "move the worktree beneath the grove's root, if it lies outside of it")
+	Command.Flags().StringVar(&to, "to", "", "path to move the worktree to, relative to the grove's root (implies --move)")
+	Command.Flags().StringVar(&layout, "layout", grove.LayoutPath, "where the worktree is moved to when --to is not given: 'path', 'flat', or a template such as 'wt/{{.Branch}}'")
+}
+
+// Import validates that the worktree at the given path belongs to the grove and, if move is set, moves it beneath the
+// grove's root
+func Import(path, destination string, move bool, layout grove.Layout) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+	g.Layout = layout
+
+	tree, err := g.ImportTree(path, destination, move)
+	if err != nil {
+		return fmt.Errorf("failed to import %q: %w", path, err)
+	}
+
+	branch := tree.Branch
+	if branch == "" {
+		branch = "(detached)"
+	}
+	fmt.Printf("imported tree %q on branch %s\n", tree.Path, branch)
+	return nil
+}
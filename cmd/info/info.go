@@ -0,0 +1,96 @@
+package info
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "info [<tree>]",
+	Short: "Show detailed information about a tree",
+	Long: `Shows detailed information about a single tree: its path, branch, upstream and how far it has diverged from it,
+HEAD commit, uncommitted changes, and lock state. If no tree is given, the tree containing the current directory is shown.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
+
+With --output-format json or yaml, the information is printed as a single object instead.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := output.ParseResultFormat(cmd.Flag(output.ResultFormatFlag).Value.String())
+		if err != nil {
+			return err
+		}
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+		return Info(path, format)
+	},
+}
+
+// Info prints detailed information about the tree at the given path, or the tree containing the current directory if
+// path is empty, in the given format
+func Info(path string, format output.ResultFormat) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	if path == "" {
+		tree, err := g.CurrentTree()
+		if err != nil {
+			return fmt.Errorf("failed to determine current tree: %w", err)
+		}
+		path = tree.Path
+	}
+
+	info, err := g.Info(path)
+	if err != nil {
+		return fmt.Errorf("failed to describe tree %q: %w", path, err)
+	}
+	return output.Render(os.Stdout, format, info, func(out io.Writer) error {
+		return printTable(out, info)
+	})
+}
+
+// printTable writes info to out as a table of labelled fields
+func printTable(out io.Writer, info grove.TreeInfo) error {
+	branch := info.Branch
+	if branch == "" {
+		branch = "(detached)"
+	}
+	upstream := "(none)"
+	if info.Upstream != "" {
+		upstream = fmt.Sprintf("%s (ahead %d, behind %d)", info.Upstream, info.Ahead, info.Behind)
+	}
+	head := "(unborn)"
+	if info.Hash != "" {
+		head = fmt.Sprintf("%s %s", info.Hash, info.Subject)
+	}
+	status := "clean"
+	if !info.Status.Clean() || info.Status.Untracked > 0 {
+		status = fmt.Sprintf("%d changed, %d untracked", info.Status.Changed, info.Status.Untracked)
+	}
+	locked := "no"
+	if info.Locked {
+		locked = "yes"
+		if info.LockReason != "" {
+			locked = fmt.Sprintf("yes (%s)", info.LockReason)
+		}
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Path:\t%s\n", info.Path)
+	fmt.Fprintf(w, "Branch:\t%s\n", branch)
+	fmt.Fprintf(w, "Upstream:\t%s\n", upstream)
+	fmt.Fprintf(w, "HEAD:\t%s\n", head)
+	fmt.Fprintf(w, "Status:\t%s\n", status)
+	fmt.Fprintf(w, "Locked:\t%s\n", locked)
+	return w.Flush()
+}
@@ -0,0 +1,36 @@
+package initalize
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// CloneCommand is equivalent to Command, documented in terms of the clone it performs
+var CloneCommand = &cobra.Command{
+	Use:   "clone <repo> [<directory>]",
+	Short: "Clone a repository into a new grove",
+	Long: `Clone a repository into a new grove. This is equivalent to "grove init".
+
+The repository is cloned into a subdirectory of the grove named after the checked out branch - the remote's
+default branch, unless --branch is provided. This subdirectory becomes the grove's main worktree; additional
+worktrees can then be created alongside it with "grove add".
+
+A directory can optionally be supplied to indicate where the grove should be created; if none is provided
+the grove is created in the current directory, with the same name as the repo.`,
+	Example: `
+Clone "linux" into a new grove in the current directory:
+
+	grove clone https://github.com/torvalds/linux.git
+
+The default branch is checked out in "linux/master"; run "cd linux/master" to enter it.
+
+To clone only the most recent commit of a specific branch:
+
+	grove clone --branch v6.0 --depth 1 https://github.com/torvalds/linux.git
+	`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: run,
+}
+
+func init() {
+	addFlags(CloneCommand)
+}
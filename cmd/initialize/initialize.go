@@ -4,28 +4,114 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/go-git/go-git/v6/plumbing/transport"
 	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/git/local"
 	"github.com/tnierman/git-grove/pkg/git/remote"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
 )
 
 const (
 	defaultDirectoryPermissions = 0o0755
 
-	groveInitTimeout = 60 * time.Second
+	// defaultListTimeout limits how long the remote may take to list its refs, which is quick even for large
+	// repositories - so a remote which takes longer is most likely unreachable
+	defaultListTimeout = 60 * time.Second
 )
 
+// defaultIgnoredFiles are the files created by file managers which may be present in a directory which is otherwise empty
+var defaultIgnoredFiles = []string{".DS_Store", "Thumbs.db", "desktop.ini", ".keep"}
+
 var Command = &cobra.Command{
 	Use:   "init <repo> [<directory>]",
 	Short: "Initialize new grove",
 	Long: `Initialize a new grove with the provided repository.
 
 A directory can optionally be supplied to indicate where the grove should be created; if none is provided
-the grove is created in the current directory, with the same name as the repo.`,
+the grove is created in the current directory, with the same name as the repo. The directory must be empty, or not yet
+exist - though files left by file managers, such as .DS_Store, are disregarded. These can be customized by --ignore-files.
+
+By default, the repository is cloned into a tree for the default branch, which becomes the grove's main worktree:
+it holds the .git/ directory shared by every other tree. This layout is compatible with any git tooling, but the
+default tree is special: it cannot be removed or locked, and moving it requires relinking every other tree.
+
+The default tree's directory is named according to --layout, after the branch it checks out. --default-dir places it
+in another directory - such as 'default' - instead, while it still checks out the default branch.
+
+With --bare, the repository is instead cloned into a hidden bare repository in the grove's root directory, and
+every tree - including the default branch's - is a linked worktree of it. No tree is special, so any of them can be
+removed, locked, or moved freely. However, git commands which require a working tree will fail when run from the
+grove's root, and some older tools do not understand worktrees of a bare repository.
+
+Every tag of the remote is cloned by default, as with 'git clone'. For repositories with many tags, '--tags following'
+limits this to the tags pointing into the cloned history, and '--tags none' skips tags entirely. Combined with
+--single-branch, which clones only the initial tree's branch, '--tags following' clones only the tags on that branch,
+whereas '--tags all' still clones every tag - along with the history each one points to.
+
+SSH remotes are accessed with a built-in client by default. When --ssh-command or $GIT_SSH_COMMAND is set, that
+command is run to connect instead - as git does - so that the system's SSH configuration, such as ProxyJump bastions,
+is respected. The command is then responsible for authentication and host key verification.
+
+The repository is cloned into the remote 'origin', unless another name is given by --origin. When cloning a fork,
+--upstream adds the repository it was created from as the remote 'upstream', and fetches its branches - so that the
+fork's branches can be rebased onto it.
+
+For very large repositories, --no-checkout clones the repository without checking out any files, so that only the trees
+which are needed can be created afterwards with 'grove add'. Without --bare, the default tree is still created - as it
+holds the repository - but is left empty: run 'grove reset --force <tree>' to populate it later. With --bare, no tree is
+created at all, so every branch - including the default branch - is checked out only once added.
+
+When another clone of the repository already exists locally, --reference borrows its objects, so that only the objects
+it lacks are retrieved from the remote. Borrowed objects are hard-linked where possible, so that they take no extra space
+while both exist; --dissociate copies them instead. Either way, the grove never depends on the reference, which can be
+removed at any time - unlike with 'git clone --reference', no alternates are used.
+
+--all-branches creates a tree for every branch of the remote, in addition to the default tree. On repositories with
+many branches, --branches-glob limits this to the branches matching any of the given patterns, such as 'release/*' -
+in which '*' does not match '/'. The two are combined: --branches-glob only filters the branches --all-branches creates
+trees for, so cannot be given without it.
+
+With --mirror, every ref of the remote - not only its branches and tags, but also refs such as pull requests and notes -
+is cloned into a hidden bare repository at the grove's root, as with 'git clone --mirror', and no tree is created. This
+suits groves acting as a local cache of the remote, such as for CI: 'grove fetch' then updates every ref to match the
+remote, and trees are created on demand with 'grove add'. A mirror's branches are the remote's own, so are overwritten
+by each fetch - check them out with 'grove add --detach <commit>' unless committing to them. --mirror cannot be combined with
+--all-branches.
+
+With --exec, a command - such as 'make setup' - is run via the shell in the default tree once the grove is created,
+turning init into a single step for onboarding onto a project. If the command fails, so does init, and the grove is
+removed so that init can be retried: --keep-on-exec-failure retains it instead, for investigating the failure. --exec
+requires a tree to run in, so cannot be combined with --mirror, or with --bare and --no-checkout.
+
+On constrained connections, --limit-rate caps the rate data is transferred at. Limiting is best-effort: only the data
+sent over the network is throttled, not the indexing of the objects received. SSH remotes are only throttled when
+--ssh-command or $GIT_SSH_COMMAND is used.
+
+When SSH isn't set up - such as when the SSH agent holds no key the remote accepts - --auth-fallback offers to retry over
+HTTPS instead, rewriting the URL 'git@host:org/repo' to 'https://host/org/repo', so that it needn't be re-typed. The
+grove's remote then uses the HTTPS URL. Switching protocols is only offered interactively, never done unasked.
+
+With --output-format json, progress and results are written to stdout as a stream of newline-delimited JSON events
+instead of human-readable text, for consumption by scripts and editors.
+
+With --recurse-submodules, the submodules of every tree created - the default tree, and those created by --all-branches -
+are initialized and checked out, recursively, as with 'git clone --recurse-submodules'. Credentials for each submodule
+are determined in the same way as for the cloned repository, and only requested if it cannot be accessed anonymously.
+As with git, each tree keeps its submodules' repositories within its own git directory, so their objects are retrieved
+once per tree, rather than shared between trees: only the submodules' configuration, such as their URLs, is shared.
+Trees added later check out their submodules only when given 'grove add --recurse-submodules'.`,
 	Example: `
 Create a new grove "linux" in the current directory:
 
@@ -38,101 +124,1006 @@ To create a grove in a specific directory:
 	grove init https://github.com/torvalds/linux.git /tmp/linux
 
 The grove will be created in the /tmp directory instead
+
+To keep the repository in a hidden bare repository at the grove's root:
+
+	grove init --bare https://github.com/torvalds/linux.git
+
+To clone a fork, along with the repository it was created from:
+
+	grove init --upstream https://github.com/torvalds/linux.git https://github.com/me/linux.git
+
+To connect through the system's SSH client, via a bastion host:
+
+	grove init --ssh-command 'ssh -J bastion.example.com' git@git.internal:team/repo.git
+
+To borrow the objects of an existing clone, rather than retrieving them again:
+
+	grove init --reference ~/src/linux https://github.com/torvalds/linux.git linux-review
+
+To keep a local mirror of a repository, checking out a tree on demand:
+
+	grove init --mirror https://github.com/torvalds/linux.git
+	cd linux && grove fetch && grove add --detach v6.9 v6.9
+
+To check out the submodules of the default tree, and of a tree for every branch:
+
+	grove init --recurse-submodules --all-branches https://github.com/me/monorepo.git
+
+To fall back to HTTPS if the SSH agent has no key for the remote:
+
+	grove init --auth-fallback git@github.com:me/project.git
+
+To create a tree for each release branch, as well as main and develop:
+
+	grove init --all-branches --branches-glob 'release/*,main,develop' https://github.com/me/project.git
+
+To bootstrap the project once it's cloned:
+
+	grove init --exec 'make setup' https://github.com/me/project.git
+
+To clone a large repository without checking out any tree, then check out a single branch:
+
+	grove init --bare --no-checkout https://github.com/torvalds/linux.git
+	cd linux && grove add --branch feature/foo
 	`,
 	Args: cobra.RangeArgs(1, 2),
-	RunE: func(_ *cobra.Command, args []string) error {
-		var (
-			// RangeArgs ensures there's at least one argument to this command
-			repo = args[0]
-
-			dir string
-			err error
-		)
-
-		// Get dir from arguments, if provided, or default to repo name
-		if len(args) > 1 {
-			dir = args[1]
-		} else {
-			dir, err = nameOf(repo)
-			if err != nil {
-				return fmt.Errorf("failed to determine name of repository %q: %w", repo, err)
-			}
+	RunE: run,
+}
+
+var (
+	identityFile string
+	username     string
+	token        string
+	depth        int
+	branch       string
+	quiet        bool
+	insecure     bool
+	caCert       string
+
+	knownHosts            string
+	strictHostKeyChecking string
+	noHostKeyCheck        bool
+	sshCommand            string
+
+	allBranches bool
+	branchGlobs []string
+	parallel    int
+	bare        bool
+	noCheckout  bool
+	limitRate   string
+	defaultDir  string
+	reference   string
+	dissociate  bool
+	mirror      bool
+
+	listTimeout  time.Duration
+	cloneTimeout time.Duration
+
+	outputFormat string
+	layout       string
+
+	origin   string
+	upstream string
+
+	ignoredFiles []string
+
+	tags         string
+	singleBranch bool
+
+	execCommand       string
+	keepOnExecFailure bool
+
+	authFallback bool
+
+	recurseSubmodules bool
+)
+
+func init() {
+	addFlags(Command)
+}
+
+// addFlags registers the flags shared by the init and clone commands on the given command
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&identityFile, "identity-file", "i", "", "private key used to authenticate against SSH remotes (defaults to using the SSH agent)")
+	cmd.Flags().StringVarP(&username, "username", "u", "", "user to authenticate as against HTTP(S) remotes when --token is provided")
+	cmd.Flags().StringVarP(&token, "token", "t", "", "password or access token used to authenticate against HTTP(S) remotes (defaults to prompting)")
+	cmd.Flags().IntVar(&depth, "depth", 0, "limit cloning to the given number of commits (defaults to the full history)")
+	cmd.Flags().StringVarP(&branch, "branch", "b", "", "branch to check out in the grove's initial worktree (defaults to the remote's default branch)")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress progress output while cloning")
+	cmd.Flags().BoolVar(&insecure, "insecure", false, "skip TLS certificate verification for HTTPS remotes (dangerous)")
+	cmd.Flags().StringVar(&caCert, "ca-cert", "", "PEM-encoded CA bundle used to verify HTTPS remotes, in addition to the system's certificates")
+	cmd.MarkFlagsMutuallyExclusive("insecure", "ca-cert")
+	cmd.Flags().StringVar(&knownHosts, "known-hosts", "", "known_hosts file used to verify SSH host keys (defaults to ~/.ssh/known_hosts)")
+	cmd.Flags().StringVar(&strictHostKeyChecking, "strict-host-key-checking", string(remote.HostKeyCheckingStrict), "how unknown SSH host keys are treated: 'yes' refuses them, 'accept-new' trusts and records them, 'no' skips verification entirely")
+	cmd.Flags().BoolVar(&noHostKeyCheck, "ssh-no-host-key-check", false, "accept any SSH host key without verification (dangerous)")
+	cmd.MarkFlagsMutuallyExclusive("strict-host-key-checking", "ssh-no-host-key-check")
+	cmd.Flags().StringVar(&sshCommand, "ssh-command", "", "command run to connect to SSH remotes instead of the built-in client, as with $GIT_SSH_COMMAND (defaults to $GIT_SSH_COMMAND)")
+	cmd.MarkFlagsMutuallyExclusive("ssh-command", "identity-file")
+	cmd.Flags().StringVar(&tags, "tags", string(remote.TagModeAll), "which tags to clone: 'all' clones every tag, 'following' only those pointing into the cloned history, 'none' clones no tags")
+	cmd.Flags().BoolVar(&singleBranch, "single-branch", false, "clone only the history of the initial tree's branch")
+	cmd.Flags().BoolVar(&allBranches, "all-branches", false, "create a tree for every branch of the remote, in addition to the default tree")
+	cmd.Flags().StringSliceVar(&branchGlobs, "branches-glob", nil, "with --all-branches, only create trees for the branches matching any of the given patterns, such as 'release/*'")
+	cmd.Flags().IntVar(&parallel, "parallel", runtime.NumCPU(), "number of trees created concurrently when --all-branches is set")
+	cmd.MarkFlagsMutuallyExclusive("all-branches", "single-branch")
+	cmd.Flags().StringVar(&outputFormat, "output", string(output.FormatText), "format of progress and results: 'text' for human-readable output, or 'json' for a stream of newline-delimited JSON events")
+	_ = cmd.Flags().MarkDeprecated("output", "use --output-format instead")
+	cmd.Flags().StringVar(&layout, "layout", grove.LayoutPath, "where each branch's tree is placed: 'path' nests trees by the branch's name, 'flat' replaces each '/' in it with '-', or a template such as 'wt/{{.Branch}}'")
+	cmd.Flags().StringVarP(&origin, "origin", "o", remote.DefaultRemoteName, "name given to the remote the repository is cloned from")
+	cmd.Flags().StringVar(&upstream, "upstream", "", "URL of the repository a fork was created from, added as the remote 'upstream' after cloning")
+	cmd.Flags().StringSliceVar(&ignoredFiles, "ignore-files", defaultIgnoredFiles, "names of files disregarded when checking that the grove's directory is empty")
+	cmd.Flags().BoolVar(&bare, "bare", false, "clone into a hidden bare repository at the grove's root, so that every tree is a linked worktree")
+	cmd.Flags().BoolVar(&noCheckout, "no-checkout", false, "clone without checking out any files: the default tree is left empty, or not created at all with --bare")
+	cmd.MarkFlagsMutuallyExclusive("no-checkout", "all-branches")
+	cmd.Flags().StringVar(&defaultDir, "default-dir", "", "name of the directory the default tree is placed in, relative to the grove's root (defaults to placing it according to --layout)")
+	cmd.Flags().StringVar(&reference, "reference", "", "path to a local repository or grove of the same project, whose objects are borrowed rather than retrieved from the remote")
+	cmd.Flags().BoolVar(&dissociate, "dissociate", false, "copy the objects borrowed from --reference, rather than hard-linking them")
+	cmd.Flags().BoolVar(&mirror, "mirror", false, "mirror every ref of the remote into a hidden bare repository at the grove's root, without checking out any tree (implies --bare and --no-checkout)")
+	cmd.MarkFlagsMutuallyExclusive("mirror", "all-branches")
+	cmd.MarkFlagsMutuallyExclusive("mirror", "single-branch")
+	cmd.MarkFlagsMutuallyExclusive("mirror", "reference")
+	cmd.Flags().StringVar(&execCommand, "exec", "", "command run via the shell in the default tree once the grove is created, such as 'make setup'")
+	cmd.Flags().BoolVar(&keepOnExecFailure, "keep-on-exec-failure", false, "retain the grove when the --exec command fails, rather than removing it")
+	cmd.Flags().BoolVar(&authFallback, "auth-fallback", false, "when authenticating with an SSH remote fails, offer to retry over HTTPS")
+	cmd.MarkFlagsMutuallyExclusive("mirror", "exec")
+	cmd.Flags().BoolVar(&recurseSubmodules, "recurse-submodules", false, "initialize and check out the submodules of each tree created, recursively")
+	cmd.MarkFlagsMutuallyExclusive("no-checkout", "recurse-submodules")
+	cmd.MarkFlagsMutuallyExclusive("mirror", "recurse-submodules")
+	cmd.Flags().DurationVar(&listTimeout, "list-timeout", defaultListTimeout, "how long to wait for the remote to list its refs before cloning, not counting time spent answering prompts")
+	cmd.Flags().DurationVar(&cloneTimeout, "clone-timeout", 0, "how long cloning - and fetching --upstream - may take, such as '2h' (defaults to no limit)")
+	cmd.Flags().StringVar(&limitRate, "limit-rate", "", "cap the transfer rate at the given bytes per second, optionally suffixed by 'k', 'm', or 'g' - such as '500k' (best-effort)")
+}
+
+// reportsEvents determines whether the command reports its progress and results as a stream of JSON events, as
+// selected by '--output-format json' - or the deprecated '--output json' - rather than as human-readable text
+func reportsEvents(cmd *cobra.Command) (bool, error) {
+	return output.EventsSelected(cmd.Flag(output.ResultFormatFlag).Value.String(), outputFormat, cmd.Name())
+}
+
+// run creates a new grove from the command's arguments and flags. It is shared by the init and clone commands
+func run(cmd *cobra.Command, args []string) error {
+	var (
+		// RangeArgs ensures there's at least one argument to this command
+		repo = args[0]
+
+		dir string
+		err error
+	)
+
+	// Get dir from arguments, if provided, or default to repo name
+	if len(args) > 1 {
+		dir, err = grove.ExpandPath(args[1])
+		if err != nil {
+			return err
+		}
+	} else {
+		dir, err = nameOf(repo)
+		if err != nil {
+			return fmt.Errorf("failed to determine name of repository %q: %w", repo, err)
+		}
+	}
+
+	events, err := reportsEvents(cmd)
+	if err != nil {
+		return err
+	}
+
+	remoteOpts, err := remoteOptions()
+	if err != nil {
+		return err
+	}
+
+	opts := Options{
+		Remote:       remoteOpts,
+		AllBranches:  allBranches,
+		BranchGlobs:  branchGlobs,
+		Parallel:     parallel,
+		Quiet:        quiet,
+		Bare:         bare,
+		Mirror:       mirror,
+		Upstream:     upstream,
+		IgnoredFiles: ignoredFiles,
+		DefaultDir:   defaultDir,
+		ListTimeout:  listTimeout,
+		CloneTimeout: cloneTimeout,
+
+		Exec:              execCommand,
+		KeepOnExecFailure: keepOnExecFailure,
+		AuthFallback:      authFallback,
+		RecurseSubmodules: recurseSubmodules,
+	}
+	opts.Layout, err = grove.ParseLayout(layout)
+	if err != nil {
+		return err
+	}
+	if events {
+		opts.Events = output.NewEmitter(os.Stdout)
+		opts.Events.Started(cmd.Name())
+		// Only JSON events may be written to stdout
+		opts.Remote.Waiting = nil
+		if !quiet {
+			opts.Remote.Progress = opts.Events.Progress()
+		}
+	}
+
+	// Cancel the clone when interrupted, so that NewGrove can clean up after itself
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = NewGrove(ctx, repo, dir, opts)
+	if err != nil {
+		err = fmt.Errorf("failed to create new grove: %w", err)
+	}
+	if opts.Events != nil {
+		opts.Events.Done(err)
+	}
+	return err
+}
+
+// remoteOptions builds the remote.Options used to clone the repository from the command's flags
+func remoteOptions() (remote.Options, error) {
+	opts := remote.Options{
+		IdentityFile:   identityFile,
+		Username:       username,
+		Credential:     token,
+		Depth:          depth,
+		Branch:         branch,
+		KnownHostsFile: knownHosts,
+		SingleBranch:   singleBranch,
+		SSHCommand:     sshCommand,
+		RemoteName:     origin,
+		NoCheckout:     noCheckout,
+		Dissociate:     dissociate,
+	}
+	if quiet {
+		opts.Progress = io.Discard
+	} else {
+		opts.Progress = output.NewProgressBar(os.Stdout)
+		opts.Waiting = func(message string) func() {
+			return output.NewSpinner(os.Stdout, message).Stop
 		}
+	}
+	if insecure {
+		fmt.Fprintln(os.Stderr, "warning: TLS certificate verification is disabled - the remote's identity will not be verified")
+		opts.InsecureSkipTLS = true
+	}
 
-		err = NewGrove(repo, dir)
+	var err error
+	if dissociate && reference == "" {
+		return remote.Options{}, fmt.Errorf("--dissociate requires --reference")
+	}
+	if reference != "" {
+		opts.Reference, err = referenceRepository(reference)
 		if err != nil {
-			return fmt.Errorf("failed to create new grove: %w", err)
+			return remote.Options{}, err
 		}
-		return nil
-	},
+	}
+	if limitRate != "" {
+		opts.RateLimit, err = remote.ParseRate(limitRate)
+		if err != nil {
+			return remote.Options{}, err
+		}
+	}
+	opts.Tags, err = remote.ParseTagMode(tags)
+	if err != nil {
+		return remote.Options{}, err
+	}
+	opts.HostKeyChecking, err = remote.ParseHostKeyChecking(strictHostKeyChecking)
+	if err != nil {
+		return remote.Options{}, err
+	}
+	if noHostKeyCheck {
+		opts.HostKeyChecking = remote.HostKeyCheckingOff
+	}
+	if opts.HostKeyChecking == remote.HostKeyCheckingOff {
+		fmt.Fprintln(os.Stderr, "warning: SSH host key verification is disabled - the remote's identity will not be verified")
+	}
+	if caCert != "" {
+		opts.CABundle, err = os.ReadFile(caCert)
+		if err != nil {
+			return remote.Options{}, fmt.Errorf("failed to read CA certificate bundle %q: %w", caCert, err)
+		}
+	}
+	return opts, nil
+}
+
+// referenceRepository resolves the repository objects are borrowed from when given --reference. Besides a repository,
+// the root of a grove may be given: a bare grove's root links to its repository, but otherwise its main worktree - the
+// only tree holding a .git/ directory - is used
+func referenceRepository(path string) (string, error) {
+	path, err := grove.ExpandPath(path)
+	if err != nil {
+		return "", err
+	}
+	_, err = local.NewRepository(path)
+	if err == nil {
+		return path, nil
+	}
+
+	entries, readErr := os.ReadDir(path)
+	if readErr != nil {
+		return "", fmt.Errorf("invalid reference %q: %w", path, readErr)
+	}
+	for _, entry := range entries {
+		info, statErr := os.Stat(local.GitPath(filepath.Join(path, entry.Name())))
+		if statErr == nil && info.IsDir() {
+			return filepath.Join(path, entry.Name()), nil
+		}
+	}
+	return "", fmt.Errorf("invalid reference %q: %w", path, err)
+}
+
+// Options configures how NewGrove creates a grove
+type Options struct {
+	// Remote configures how the repository is retrieved from the remote
+	Remote remote.Options
+	// AllBranches creates a tree for every branch of the remote, in addition to the default tree
+	AllBranches bool
+	// BranchGlobs limits the trees created by AllBranches to the branches matching any of the given patterns, as with
+	// grove.MatchBranch. It requires AllBranches
+	BranchGlobs []string
+	// Parallel is the number of trees created concurrently when AllBranches is set
+	Parallel int
+	// Quiet suppresses informational output
+	Quiet bool
+	// Bare clones the repository into a hidden bare repository at the grove's root, rather than into the default tree
+	Bare bool
+	// Mirror clones every ref of the remote into a hidden bare repository at the grove's root, as with Bare, without
+	// creating any tree. It cannot be combined with AllBranches
+	Mirror bool
+	// Layout determines where each branch's tree is placed within the grove
+	Layout grove.Layout
+	// DefaultDir is the path of the initial tree's directory, relative to the grove's root. The tree still checks out
+	// the default branch, or Remote.Branch if set. Defaults to placing the tree according to Layout
+	DefaultDir string
+	// IgnoredFiles are the names of files disregarded when checking that the grove's directory is empty, such as
+	// those created by file managers
+	IgnoredFiles []string
+	// Upstream is the URL of the repository a fork was created from. If set, it is added as the remote
+	// remote.UpstreamRemoteName after cloning
+	Upstream string
+	// ListTimeout limits how long the remote may take to list its refs - for example, to determine its default
+	// branch, or to check whether it can be accessed anonymously. Time spent answering prompts for credentials is not
+	// counted. Defaults to 60 seconds if zero
+	ListTimeout time.Duration
+	// CloneTimeout limits how long cloning the repository, and fetching Upstream, may take. Defaults to no limit if zero
+	CloneTimeout time.Duration
+	// Exec is a command run via the shell in the default tree once the grove is created, such as 'make setup'. If it
+	// fails, the grove is removed, unless KeepOnExecFailure is set. It cannot be combined with a bare grove cloned
+	// without a checkout, which has no tree to run it in
+	Exec string
+	// KeepOnExecFailure retains the grove when Exec fails
+	KeepOnExecFailure bool
+	// AuthFallback offers to retry over HTTPS, once confirmed by the user, when authenticating with an SSH remote fails
+	AuthFallback bool
+	// RecurseSubmodules initializes and checks out the submodules of every tree created, recursively. It cannot be
+	// combined with Mirror, or a clone without a checkout, whose trees have no submodules checked out
+	RecurseSubmodules bool
+	// Events receives an event for each tree created, in place of human-readable output, if not nil
+	Events *output.Emitter
 }
 
 // NewGrove creates a grove for the given repo at the provided path.
 //
 // The path must be a directory, or an error is returned.
 // Repo must be a valid URL to the repository (remote or local).
-func NewGrove(repoURL, path string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), groveInitTimeout)
-	defer cancel()
+//
+// If ctx is cancelled - for example, when the user interrupts grove - creating the grove stops, and anything created
+// at path is removed, so that creating the grove can be retried
+func NewGrove(ctx context.Context, repoURL, path string, opts Options) error {
+	if opts.ListTimeout == 0 {
+		opts.ListTimeout = defaultListTimeout
+	}
+	if len(opts.BranchGlobs) > 0 {
+		if !opts.AllBranches {
+			return fmt.Errorf("--branches-glob only filters the trees created by --all-branches, so requires it")
+		}
+		// Reject invalid patterns before cloning, rather than once the clone is done
+		_, err := grove.MatchBranch("", opts.BranchGlobs)
+		if err != nil {
+			return err
+		}
+	}
 
-	repository, err := remote.NewRepository(repoURL)
+	if opts.Mirror {
+		// A mirror's branches are the remote's own, rather than remote-tracking branches, so are only checked out in
+		// trees on demand
+		if opts.AllBranches {
+			return fmt.Errorf("cannot create a tree for every branch of a mirror: add trees on demand with 'grove add' instead")
+		}
+		if opts.Remote.SingleBranch || opts.Remote.Reference != "" {
+			return fmt.Errorf("mirrors clone every ref of the remote, so cannot be limited to a single branch, or borrow objects from a reference")
+		}
+		opts.Bare = true
+		opts.Remote.NoCheckout = true
+	}
+	opts.Remote.Bare = opts.Bare
+	opts.Remote.Mirror = opts.Mirror
+	if opts.Exec != "" && opts.Bare && opts.Remote.NoCheckout {
+		return fmt.Errorf("cannot run %q: no tree is checked out to run it in", opts.Exec)
+	}
+	if opts.RecurseSubmodules && opts.Remote.NoCheckout {
+		return fmt.Errorf("cannot check out submodules without checking out the trees holding them")
+	}
+	if opts.Remote.RemoteName == "" {
+		opts.Remote.RemoteName = remote.DefaultRemoteName
+	}
+	repository, err := remote.NewRepositoryWithOptions(repoURL, opts.Remote)
 	if err != nil {
 		return fmt.Errorf("failed to connect to remote repository: %w", err)
 	}
+	// Authenticating may prompt for credentials, a passphrase, or whether to fall back to HTTPS, none of which should
+	// count against --list-timeout, so is done before starting it
+	repository, err = authenticate(ctx, repository, opts)
+	if err != nil {
+		return err
+	}
+	repoURL = repository.URL
+	timeoutCtx, cancel := context.WithTimeout(ctx, opts.ListTimeout)
+	defer cancel()
 
-	branch, err := repository.DefaultBranch(ctx)
+	branch := opts.Remote.Branch
+	if branch == "" {
+		branch, err = repository.DefaultBranch(timeoutCtx)
+		if err != nil {
+			return fmt.Errorf("failed to determine default branch for repository %q: %w", repoURL, timedOut(timeoutCtx, err, "listing refs", "--list-timeout"))
+		}
+	}
+
+	relativeWorktreePath, err := opts.Layout.TreePath(branch)
 	if err != nil {
-		return fmt.Errorf("failed to determine default branch for repository %q: %w", repoURL, err)
+		return err
+	}
+	if opts.DefaultDir != "" {
+		relativeWorktreePath, err = defaultTreeDir(opts.DefaultDir)
+		if err != nil {
+			return err
+		}
+	}
+	// The grove's root is found from the main worktree, which must therefore sit directly beneath it
+	if !opts.Bare && filepath.Base(relativeWorktreePath) != relativeWorktreePath {
+		return fmt.Errorf("cannot place the default tree at %q: it must be directly beneath the grove's root, unless --bare is set", relativeWorktreePath)
+	}
+
+	err = notWithinRepository(path)
+	if err != nil {
+		return err
 	}
 
 	// Validate that both the root of grove and default worktree dir are empty, or do not exist on init.
 	// Because we want both to be empty or newly-created, perform the check in two steps
-	err = newOrEmptyDir(path)
+	existing, err := newOrEmptyDir(path, opts.IgnoredFiles)
 	if err != nil {
 		return fmt.Errorf("directory %q is invalid: %w", path, err)
 	}
+	// A failed clone leaves a partial grove behind, which would block a retry
+	cloneFailed := false
+	execFailed := false
+	defer func() {
+		if ctx.Err() != nil {
+			removePartialGrove(path, existing, "interrupted")
+		} else if cloneFailed {
+			removePartialGrove(path, existing, "clone failed")
+		} else if execFailed && !opts.KeepOnExecFailure {
+			removePartialGrove(path, existing, "--exec failed")
+		}
+	}()
+
+	// Cloning a large repository may take far longer than listing its refs, so has its own timeout
+	cloneCtx := ctx
+	if opts.CloneTimeout > 0 {
+		var cancelClone context.CancelFunc
+		cloneCtx, cancelClone = context.WithTimeout(ctx, opts.CloneTimeout)
+		defer cancelClone()
+	}
 
-	defaultWorktreePath := filepath.Join(path, branch)
-	err = newOrEmptyDir(defaultWorktreePath)
+	defaultWorktreePath := filepath.Join(path, relativeWorktreePath)
+	// The directory the grove is opened from once cloned: the root of a bare grove links to its repository, but
+	// otherwise only the default tree does
+	groveDir := defaultWorktreePath
+	if opts.Bare {
+		groveDir = path
+		err = newBareGrove(cloneCtx, repository, path, relativeWorktreePath, branch, !opts.Remote.NoCheckout)
+		if err != nil {
+			cloneFailed = true
+			return timedOut(cloneCtx, err, "cloning", "--clone-timeout")
+		}
+	} else {
+		_, err = newOrEmptyDir(defaultWorktreePath, opts.IgnoredFiles)
+		if err != nil {
+			return fmt.Errorf("directory %q is invalid: %w", path, err)
+		}
+
+		// Finally, clone the repo into the default worktree location
+		err = repository.Clone(cloneCtx, defaultWorktreePath)
+		if err != nil {
+			cloneFailed = true
+			return fmt.Errorf("failed to clone %q to %q: %w", repoURL, defaultWorktreePath, timedOut(cloneCtx, err, "cloning", "--clone-timeout"))
+		}
+	}
+
+	// The grove is usable without its marker, its root then being derived from the repository's layout
+	err = grove.MarkRoot(path)
 	if err != nil {
-		return fmt.Errorf("directory %q is invalid: %w", path, err)
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
+	recordDefaultBranch(ctx, repository, groveDir, opts)
+
+	if opts.RecurseSubmodules {
+		err = updateSubmodules(cloneCtx, groveDir, defaultWorktreePath, opts)
+		if err != nil {
+			return fmt.Errorf("grove %q was created, but %w", path, timedOut(cloneCtx, err, "cloning", "--clone-timeout"))
+		}
+	}
+
+	// Bare groves cloned without a checkout have no tree
+	if opts.Events != nil && !(opts.Bare && opts.Remote.NoCheckout) {
+		opts.Events.Emit(output.Event{Type: output.EventTree, Tree: defaultWorktreePath, Branch: branch})
 	}
 
-	// Finally, clone the repo into the default worktree location
-	err = repository.Clone(defaultWorktreePath)
+	if opts.Upstream != "" {
+		err = addUpstream(ctx, groveDir, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.AllBranches {
+		err = addBranchTrees(ctx, groveDir, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.Exec != "" {
+		err = runExec(ctx, defaultWorktreePath, opts)
+		if err != nil {
+			execFailed = true
+			if opts.KeepOnExecFailure {
+				fmt.Fprintf(os.Stderr, "keeping grove %q, as --keep-on-exec-failure is set\n", path)
+			}
+			return err
+		}
+	}
+
+	if !opts.Quiet && opts.Events == nil {
+		printSummary(path, groveDir, relativeWorktreePath, branch, opts)
+	}
+	return nil
+}
+
+// runExec runs opts.Exec via the shell in the tree at path, reporting its exit status
+func runExec(ctx context.Context, path string, opts Options) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", opts.Exec)
+	cmd.Dir = path
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	// Only JSON events may be written to stdout
+	if opts.Events != nil {
+		cmd.Stdout = os.Stderr
+	}
+	err := cmd.Run()
+
+	exitErr := &exec.ExitError{}
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("command %q failed in tree %q: exited with status %d", opts.Exec, path, exitErr.ExitCode())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run command %q in tree %q: %w", opts.Exec, path, err)
+	}
+	if !opts.Quiet && opts.Events == nil {
+		fmt.Printf("ran %q in %q: exited with status 0\n", opts.Exec, path)
+	}
+	return nil
+}
+
+// authenticate determines how to authenticate with the repository, prompting for credentials or a passphrase if need
+// be, then offers to fall back to HTTPS if opts.AuthFallback is set and authenticating via SSH fails. The repository
+// to use is returned. Only the remote's responses are limited by opts.ListTimeout, rather than the time taken to
+// answer prompts
+func authenticate(ctx context.Context, repository *remote.Repository, opts Options) (*remote.Repository, error) {
+	_, err := resolveAuth(ctx, repository, opts.ListTimeout)
+	// Failing to authenticate via SSH is what falling back to HTTPS is for
+	if err != nil && !(opts.AuthFallback && errors.Is(err, remote.ErrAuthFailed)) {
+		return nil, err
+	}
+	if !opts.AuthFallback {
+		return repository, nil
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, opts.ListTimeout)
+	defer cancel()
+	fallback, err := fallbackToHTTPS(timeoutCtx, repository, opts)
 	if err != nil {
-		return fmt.Errorf("failed to clone %q to %q: %w", repoURL, defaultWorktreePath, err)
+		return nil, err
 	}
+	if fallback != repository {
+		_, err = resolveAuth(ctx, fallback, opts.ListTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fallback, nil
+}
 
+// resolveAuth determines how to authenticate with the repository, which is cached for its later use. HTTP(S) remotes
+// are probed for anonymous access first, which must respond within timeout; prompting for credentials afterwards is
+// not limited
+func resolveAuth(ctx context.Context, repository *remote.Repository, timeout time.Duration) (transport.AuthMethod, error) {
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	auth, err := repository.Auth(probeCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate with %q: %w", repository.URL, timedOut(probeCtx, err, "listing refs", "--list-timeout"))
+	}
+	return auth, nil
+}
+
+// fallbackToHTTPS checks that authenticating with the given SSH repository succeeds. If it fails, the user is offered
+// to retry over HTTPS, and the repository at the equivalent HTTPS URL is returned once they accept. Repositories
+// accessed via other protocols are returned as is
+func fallbackToHTTPS(ctx context.Context, repository *remote.Repository, opts Options) (*remote.Repository, error) {
+	if remote.DetectProtocol(repository.URL) != remote.ProtocolSSH {
+		return repository, nil
+	}
+	_, err := repository.IsEmpty(ctx)
+	if !errors.Is(err, remote.ErrAuthFailed) {
+		// Any other failure is reported once the repository is used
+		return repository, nil
+	}
+	httpsURL, ok := remote.HTTPSURL(repository.URL)
+	if !ok {
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, "authenticating with %q via SSH failed\n", repository.URL)
+	retry, promptErr := output.Confirm(fmt.Sprintf("Retry over HTTPS, via %q?", httpsURL))
+	if promptErr != nil {
+		return nil, fmt.Errorf("%w (%w)", err, promptErr)
+	}
+	if !retry {
+		return nil, err
+	}
+
+	repository, err = remote.NewRepositoryWithOptions(httpsURL, opts.Remote)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to remote repository: %w", err)
+	}
+	return repository, nil
+}
+
+// printSummary describes the grove created at path, containing groveDir, and explains how to enter its default tree at
+// treePath. The grove is already usable, so failing to summarize it only produces a warning
+func printSummary(path, groveDir, treePath, branch string, opts Options) {
+	g, err := grove.OpenAt(groveDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to summarize grove: failed to open new grove: %v\n", err)
+		return
+	}
+	summary, err := g.Summary()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to summarize grove: %v\n", err)
+		return
+	}
+
+	fmt.Printf("created grove %q: %d refs, %d branches, %d objects, %s on disk\n", path, summary.Refs, summary.Branches, summary.Objects, output.FormatBytes(summary.Size))
+	// Bare groves cloned without a checkout have no tree to enter yet
+	if opts.Bare && opts.Remote.NoCheckout {
+		fmt.Printf("no tree is checked out: run 'cd %s && grove add --branch %s' to check out %q\n", path, branch, branch)
+		return
+	}
+	fmt.Printf("checked out %q: run 'cd %s' to get started\n", branch, filepath.Join(path, treePath))
+}
+
+// defaultTreeDir validates the directory the default tree is placed in, given relative to the grove's root
+func defaultTreeDir(dir string) (string, error) {
+	clean := filepath.Clean(dir)
+	if filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid default tree directory %q: it must be a path within the grove's root", dir)
+	}
+	if clean == grove.BareGitDir {
+		return "", fmt.Errorf("invalid default tree directory %q: it is reserved for the repository of bare groves", dir)
+	}
+	return clean, nil
+}
+
+// newBareGrove clones the repository into a hidden bare repository at the root of the grove at path, then creates
+// the grove's initial tree for branch from it, at treePath relative to the grove's root, if checkout is set
+func newBareGrove(ctx context.Context, repository *remote.Repository, path, treePath, branch string, checkout bool) error {
+	gitDir := filepath.Join(path, grove.BareGitDir)
+	err := repository.Clone(ctx, gitDir)
+	if err != nil {
+		return fmt.Errorf("failed to clone %q to %q: %w", repository.URL, gitDir, err)
+	}
+
+	// Link the grove's root to the bare repository, so the grove can be found from any directory within it
+	err = local.WriteGitFile(path, grove.BareGitDir)
+	if err != nil {
+		return fmt.Errorf("failed to link grove %q to its repository: %w", path, err)
+	}
+
+	if !checkout {
+		return nil
+	}
+
+	g, err := grove.OpenAt(path)
+	if err != nil {
+		return fmt.Errorf("failed to open new grove: %w", err)
+	}
+	err = g.AddTree(treePath, grove.AddTreeOptions{Branch: branch})
+	if err != nil {
+		return fmt.Errorf("failed to create tree for branch %q: %w", branch, err)
+	}
+	return nil
+}
+
+// timedOut explains that err was caused by the operation exceeding the timeout of ctx, which is set by flag, if so.
+// Otherwise, err is returned as is
+func timedOut(ctx context.Context, err error, operation, flag string) error {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%s timed out (raise %s): %w", operation, flag, err)
+}
+
+// recordDefaultBranch records the remote's default branch in the grove containing the given path, so that it remains
+// the grove's default branch even when another branch was cloned. Failing to do so only produces a warning, as the
+// grove is otherwise usable
+func recordDefaultBranch(ctx context.Context, repository *remote.Repository, groveDir string, opts Options) {
+	// Only the cloned branch is tracked by single-branch groves, and mirrors have no remote-tracking branches at all
+	if opts.Remote.SingleBranch || opts.Mirror {
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, opts.ListTimeout)
+	defer cancel()
+	// Empty remotes have no HEAD to record until their first branch is pushed
+	empty, err := repository.IsEmpty(timeoutCtx)
+	if err == nil && empty {
+		return
+	}
+	branch, err := repository.DefaultBranch(timeoutCtx)
+	if err != nil {
+		if !errors.Is(err, remote.ErrNoDefaultBranch) {
+			fmt.Fprintf(os.Stderr, "warning: failed to record default branch: %v\n", err)
+		}
+		return
+	}
+
+	g, err := grove.OpenAt(groveDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record default branch: failed to open new grove: %v\n", err)
+		return
+	}
+	err = g.SetDefaultBranch(branch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to record default branch: %v\n", err)
+	}
+}
+
+// addUpstream adds the repository a fork was created from as a remote of the grove containing the given path, authenticating with it in the same way as with the cloned repository
+func addUpstream(ctx context.Context, groveDir string, opts Options) error {
+	repository, err := remote.NewRepositoryWithOptions(opts.Upstream, opts.Remote)
+	if err != nil {
+		return fmt.Errorf("failed to connect to upstream repository: %w", err)
+	}
+	auth, err := resolveAuth(ctx, repository, opts.ListTimeout)
+	if err != nil {
+		return err
+	}
+
+	g, err := grove.OpenAt(groveDir)
+	if err != nil {
+		return fmt.Errorf("failed to open new grove: %w", err)
+	}
+	err = g.AddRemote(remote.UpstreamRemoteName, opts.Upstream)
+	if err != nil {
+		return fmt.Errorf("failed to add upstream %q: %w", opts.Upstream, err)
+	}
+	fetchCtx := ctx
+	if opts.CloneTimeout > 0 {
+		var cancelFetch context.CancelFunc
+		fetchCtx, cancelFetch = context.WithTimeout(ctx, opts.CloneTimeout)
+		defer cancelFetch()
+	}
+	err = g.Fetch(fetchCtx, remote.UpstreamRemoteName, nil, auth, opts.Remote.Progress)
+	if err != nil {
+		return fmt.Errorf("failed to fetch upstream %q: %w", opts.Upstream, timedOut(fetchCtx, err, "fetching", "--clone-timeout"))
+	}
+	return nil
+}
+
+// updateSubmodules initializes and checks out the submodules of the tree at treePath, in the grove containing groveDir
+func updateSubmodules(ctx context.Context, groveDir, treePath string, opts Options) error {
+	g, err := grove.OpenAt(groveDir)
+	if err != nil {
+		return fmt.Errorf("failed to open new grove: %w", err)
+	}
+	g.SubmoduleAuth = submoduleAuth(opts)
+	// Relative paths are resolved against the grove's root, rather than the current directory
+	treePath, err = filepath.Abs(treePath)
+	if err != nil {
+		return fmt.Errorf("failed to determine absolute path of %q: %w", treePath, err)
+	}
+
+	if !opts.Quiet && opts.Events == nil {
+		fmt.Printf("updating submodules of tree %q\n", treePath)
+	}
+	err = g.UpdateSubmodules(ctx, treePath)
+	if err != nil {
+		return fmt.Errorf("failed to update submodules of tree %q: %w", treePath, err)
+	}
+	return nil
+}
+
+// submoduleAuth authenticates with each submodule in the same way as with the cloned repository
+func submoduleAuth(opts Options) grove.SubmoduleAuth {
+	return func(ctx context.Context, url string) (transport.AuthMethod, error) {
+		repository, err := remote.NewRepositoryWithOptions(url, opts.Remote)
+		if err != nil {
+			return nil, err
+		}
+		return repository.Auth(ctx)
+	}
+}
+
+// addBranchTrees creates a tree for every remote branch in the grove containing the given path,
+// printing a summary of the trees created
+func addBranchTrees(ctx context.Context, groveDir string, opts Options) error {
+	g, err := grove.OpenAt(groveDir)
+	if err != nil {
+		return fmt.Errorf("failed to open new grove: %w", err)
+	}
+	g.Layout = opts.Layout
+	g.SubmoduleAuth = submoduleAuth(opts)
+
+	results, err := g.AddRemoteBranchTrees(ctx, opts.Remote.RemoteName, opts.BranchGlobs, opts.Parallel, opts.RecurseSubmodules)
+	if err != nil {
+		return fmt.Errorf("failed to create trees for remote branches: %w", err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+		if opts.Events != nil {
+			event := output.Event{Type: output.EventTree, Tree: result.Path, Branch: result.Branch}
+			if result.Err != nil {
+				event.Error = result.Err.Error()
+			}
+			opts.Events.Emit(event)
+			continue
+		}
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create tree %q for branch %q: %v\n", result.Path, result.Branch, result.Err)
+			continue
+		}
+		if !opts.Quiet {
+			fmt.Printf("created tree %q for branch %q\n", result.Path, result.Branch)
+		}
+	}
+
+	if !opts.Quiet && opts.Events == nil {
+		fmt.Printf("created %d of %d trees\n", len(results)-failed, len(results))
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to create %d of %d trees", failed, len(results))
+	}
 	return nil
 }
 
 // newOrEmptyDir validates that the provided path refers to an empty directory, or creates an empty directory at the given path if none exists.
+// Files whose names are in ignored - such as .DS_Store - are disregarded when determining whether the directory is empty.
+// The paths which already existed are returned - the directory itself, and any ignored files within it - so that they
+// can be left in place if the grove is removed again.
 //
 // If the given path refers to a non-directory file or an existing, non-empty directory, an error is returned.
-func newOrEmptyDir(path string) error {
+func newOrEmptyDir(path string, ignored []string) (map[string]bool, error) {
+	existing := map[string]bool{}
 	files, err := os.ReadDir(path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			// Directory does not exist: create it and return
 			err = os.MkdirAll(path, defaultDirectoryPermissions)
 			if err != nil {
-				return fmt.Errorf("failed to create directory %q: %w", path, err)
+				return nil, fmt.Errorf("failed to create directory %q: %w", path, err)
 			}
-			return nil
+			return existing, nil
 		}
 
 		// Directory could not be opened
-		return fmt.Errorf("failed to open directory %q: %w", path, err)
+		return nil, fmt.Errorf("failed to open directory %q: %w", path, err)
 	}
 
 	// Directory exists - validate that it's empty
-	if len(files) > 0 {
-		return fmt.Errorf("directory %q is not empty", path)
+	existing[filepath.Clean(path)] = true
+	for _, file := range files {
+		if !slices.Contains(ignored, file.Name()) {
+			return nil, fmt.Errorf("directory %q is not empty: found %q", path, file.Name())
+		}
+		existing[filepath.Join(path, file.Name())] = true
+	}
+	return existing, nil
+}
+
+// notWithinRepository validates that the provided path is not within an existing grove or git repository, which
+// would result in nested repositories
+func notWithinRepository(path string) error {
+	root, err := local.FindWorktreeRoot(path)
+	if err != nil && !errors.Is(err, local.ErrNoWorktree) {
+		return fmt.Errorf("failed to check whether %q is within an existing repository: %w", path, err)
+	}
+	if err == nil {
+		return fmt.Errorf("%q is within the existing grove or git repository at %q: choose a different directory, or use 'grove convert' to turn an existing repository into a grove", path, root)
+	}
+
+	// The root of a grove which isn't bare is not itself within any worktree, so is only found by its layout
+	root, err = groveRootAbove(path)
+	if err != nil {
+		return fmt.Errorf("failed to check whether %q is within an existing grove: %w", path, err)
+	}
+	if root != "" {
+		return fmt.Errorf("%q is within the existing grove at %q: choose a different directory", path, root)
 	}
 	return nil
 }
 
+// groveRootAbove returns the root of the grove which path lies within, if any, checking each existing directory from
+// path upwards. Directories which can't be read are skipped
+func groveRootAbove(path string) (string, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path of %q: %w", path, err)
+	}
+	for ; ; dir = filepath.Dir(dir) {
+		isRoot, err := grove.IsRoot(dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) && !errors.Is(err, os.ErrPermission) {
+			return "", err
+		}
+		if isRoot {
+			return dir, nil
+		}
+		if filepath.Dir(dir) == dir {
+			return "", nil
+		}
+	}
+}
+
+// removePartialGrove removes a partially-created grove at path, explaining why with reason. Only what was added by
+// this run is removed: paths in existing - such as the grove's directory itself, if it already existed, and the ignored
+// files within it - are left in place
+func removePartialGrove(path string, existing map[string]bool, reason string) {
+	fmt.Fprintf(os.Stderr, "%s: removing partially-created grove %q\n", reason, path)
+	removeAdded(filepath.Clean(path), existing)
+}
+
+// removeAdded removes path unless it is in existing, in which case every path added within it is removed instead
+func removeAdded(path string, existing map[string]bool) {
+	if !existing[path] {
+		err := os.RemoveAll(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to cleanup %q: %v\n", path, err)
+		}
+		return
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil || !info.IsDir() {
+		return
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to cleanup directory %q: %v\n", path, err)
+		return
+	}
+	for _, entry := range entries {
+		removeAdded(filepath.Join(path, entry.Name()), existing)
+	}
+}
+
 // nameOf parses a standard URL or local path for the provided git repo to determine its name
 func nameOf(repo string) (string, error) {
 	index := strings.LastIndex(repo, "/")
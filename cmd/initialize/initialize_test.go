@@ -0,0 +1,438 @@
+package initalize
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+func TestRemovePartialGrove(t *testing.T) {
+	ignored := []string{".DS_Store", "Thumbs.db"}
+
+	tests := []struct {
+		name string
+		// prepare creates whatever exists in the grove's directory beforehand
+		prepare func(t *testing.T, path string)
+		// want lists the paths expected to remain, relative to the grove's directory, or nil if it should be removed
+		want []string
+	}{
+		{
+			name:    "created",
+			prepare: func(*testing.T, string) {},
+		},
+		{
+			name: "empty",
+			prepare: func(t *testing.T, path string) {
+				mkdir(t, path)
+			},
+			want: []string{},
+		},
+		{
+			name: "ignored files",
+			prepare: func(t *testing.T, path string) {
+				writeFile(t, filepath.Join(path, ".DS_Store"))
+				writeFile(t, filepath.Join(path, "Thumbs.db"))
+			},
+			want: []string{".DS_Store", "Thumbs.db"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "grove")
+			tt.prepare(t, path)
+
+			existing, err := newOrEmptyDir(path, ignored)
+			if err != nil {
+				t.Fatalf("newOrEmptyDir() returned error: %v", err)
+			}
+			defaultTree := filepath.Join(path, "main")
+			// Simulate a partial clone into the default tree, and a marker at the grove's root
+			writeFile(t, filepath.Join(defaultTree, ".git", "HEAD"))
+			writeFile(t, filepath.Join(defaultTree, "README.md"))
+			writeFile(t, filepath.Join(path, ".grove", "root"))
+
+			removePartialGrove(path, existing, "test")
+
+			if tt.want == nil {
+				_, err = os.Stat(path)
+				if !os.IsNotExist(err) {
+					t.Fatalf("directory created for the grove was not removed: %v", err)
+				}
+				return
+			}
+			got := []string{}
+			err = filepath.WalkDir(path, func(file string, _ os.DirEntry, err error) error {
+				if err != nil || file == path {
+					return err
+				}
+				relative, err := filepath.Rel(path, file)
+				got = append(got, relative)
+				return err
+			})
+			if err != nil {
+				t.Fatalf("failed to list the grove's directory: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("left %q in place, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("left %q in place, want %q", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestNewOrEmptyDir(t *testing.T) {
+	ignored := []string{".DS_Store"}
+
+	tests := []struct {
+		name string
+		// path is the path checked, relative to a temporary directory
+		path string
+		// prepare creates whatever exists at the path beforehand
+		prepare func(t *testing.T, path string)
+		// wantExisting lists the paths reported as already existing, relative to the path
+		wantExisting []string
+		wantErr      bool
+	}{
+		{
+			name:         "missing",
+			path:         "grove",
+			prepare:      func(*testing.T, string) {},
+			wantExisting: []string{},
+		},
+		{
+			name:         "missing parents",
+			path:         filepath.Join("a", "b", "grove"),
+			prepare:      func(*testing.T, string) {},
+			wantExisting: []string{},
+		},
+		{
+			name:         "empty",
+			path:         "grove",
+			prepare:      func(t *testing.T, path string) { mkdir(t, path) },
+			wantExisting: []string{"."},
+		},
+		{
+			name:         "ignored file",
+			path:         "grove",
+			prepare:      func(t *testing.T, path string) { writeFile(t, filepath.Join(path, ".DS_Store")) },
+			wantExisting: []string{".", ".DS_Store"},
+		},
+		{
+			name: "other file",
+			path: "grove",
+			prepare: func(t *testing.T, path string) {
+				writeFile(t, filepath.Join(path, ".DS_Store"))
+				writeFile(t, filepath.Join(path, "README.md"))
+			},
+			wantErr: true,
+		},
+		{
+			name:    "hidden directory",
+			path:    "grove",
+			prepare: func(t *testing.T, path string) { mkdir(t, filepath.Join(path, ".git")) },
+			wantErr: true,
+		},
+		{
+			name:    "file",
+			path:    "grove",
+			prepare: func(t *testing.T, path string) { writeFile(t, path) },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.path)
+			tt.prepare(t, path)
+
+			existing, err := newOrEmptyDir(path, ignored)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("newOrEmptyDir() accepted a path which is neither missing nor empty")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newOrEmptyDir() returned error: %v", err)
+			}
+			info, err := os.Stat(path)
+			if err != nil || !info.IsDir() {
+				t.Fatalf("newOrEmptyDir() left no directory at %q: %v", path, err)
+			}
+			if len(existing) != len(tt.wantExisting) {
+				t.Fatalf("newOrEmptyDir() = %v, want %q", existing, tt.wantExisting)
+			}
+			for _, want := range tt.wantExisting {
+				if !existing[filepath.Join(path, want)] {
+					t.Errorf("newOrEmptyDir() = %v, want %q to be included", existing, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNotWithinRepository(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare creates the directory the grove is to be created beneath
+		prepare func(t *testing.T, dir string)
+		// path is where the grove is to be created, relative to the directory
+		path    string
+		wantErr bool
+	}{
+		{
+			name:    "unrelated directory",
+			prepare: func(t *testing.T, dir string) { mkdir(t, dir) },
+			path:    "grove",
+		},
+		{
+			name: "within a marked grove",
+			prepare: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, ".grove", "root"))
+			},
+			path:    "newdir",
+			wantErr: true,
+		},
+		{
+			name: "nested within a marked grove",
+			prepare: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, ".grove", "root"))
+			},
+			path:    filepath.Join("a", "b", "newdir"),
+			wantErr: true,
+		},
+		{
+			name:    "within a bare grove",
+			prepare: func(t *testing.T, dir string) { mkdir(t, filepath.Join(dir, ".bare")) },
+			path:    "newdir",
+			wantErr: true,
+		},
+		{
+			name: "within a repository",
+			prepare: func(t *testing.T, dir string) {
+				_, err := git.PlainInit(dir, false)
+				if err != nil {
+					t.Fatalf("failed to init repository: %v", err)
+				}
+			},
+			path:    "newdir",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "parent")
+			tt.prepare(t, dir)
+
+			err := notWithinRepository(filepath.Join(dir, tt.path))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("notWithinRepository() returned error %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReportsEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want bool
+		// wantErr is whether the arguments should be rejected
+		wantErr bool
+	}{
+		{name: "default", args: nil, want: false},
+		{name: "table", args: []string{"--output-format", "table"}, want: false},
+		{name: "json", args: []string{"--output-format", "json"}, want: true},
+		{name: "deprecated json", args: []string{"--output", "json"}, want: true},
+		{name: "deprecated text", args: []string{"--output", "text"}, want: false},
+		{name: "yaml", args: []string{"--output-format", "yaml"}, wantErr: true},
+		{name: "invalid", args: []string{"--output-format", "xml"}, wantErr: true},
+		{name: "invalid deprecated", args: []string{"--output", "xml"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &cobra.Command{Use: "grove"}
+			root.PersistentFlags().String(output.ResultFormatFlag, string(output.ResultFormatTable), "")
+			cmd := &cobra.Command{Use: "init", Run: func(*cobra.Command, []string) {}}
+			addFlags(cmd)
+			root.AddCommand(cmd)
+			t.Cleanup(func() { outputFormat = string(output.FormatText) })
+
+			err := cmd.ParseFlags(tt.args)
+			if err != nil {
+				t.Fatalf("failed to parse flags: %v", err)
+			}
+			got, err := reportsEvents(cmd)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("reportsEvents() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("reportsEvents() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("reportsEvents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewGroveWritesOnlyEventsToStdout(t *testing.T) {
+	// A remote requiring credentials, so that they are prompted for
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="grove"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	stdin := redirect(t, &os.Stdin)
+	_, err := stdin.WriteString("user\n")
+	if err != nil {
+		t.Fatalf("failed to write username: %v", err)
+	}
+	_, err = stdin.Seek(0, io.SeekStart)
+	if err != nil {
+		t.Fatalf("failed to rewind stdin: %v", err)
+	}
+	stdout := redirect(t, &os.Stdout)
+	stderr := redirect(t, &os.Stderr)
+
+	opts := Options{Events: output.NewEmitter(os.Stdout)}
+	opts.Events.Started("init")
+	opts.Remote.Progress = opts.Events.Progress()
+	// The password can't be read, as stdin is not a terminal
+	err = NewGrove(context.Background(), server.URL+"/repo.git", filepath.Join(t.TempDir(), "grove"), opts)
+	if err == nil {
+		t.Fatal("NewGrove() succeeded without credentials")
+	}
+	opts.Events.Done(err)
+
+	prompts, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatalf("failed to read stderr: %v", err)
+	}
+	if !strings.Contains(string(prompts), "username: ") {
+		t.Errorf("stderr = %q, want the username prompt", prompts)
+	}
+	events, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatalf("failed to read stdout: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(events), "\n"), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("stdout = %q, want the started and done events", events)
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Errorf("stdout holds %q, which is not a JSON event", line)
+		}
+	}
+}
+
+func TestNewGroveListTimeoutExcludesAuthentication(t *testing.T) {
+	// A remote requiring credentials, which records those it is sent
+	var mu sync.Mutex
+	authorized := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="grove"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		authorized = true
+		mu.Unlock()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	// A credential helper taking longer than --list-timeout, as a user answering a prompt would
+	home := t.TempDir()
+	gitConfig := filepath.Join(home, ".gitconfig")
+	err := os.WriteFile(gitConfig, []byte("[credential]\n\thelper = \"!f() { sleep 1; echo username=user; echo password=secret; }; f\"\n"), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write git configuration: %v", err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", gitConfig)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	redirect(t, &os.Stdout)
+	redirect(t, &os.Stderr)
+
+	opts := Options{ListTimeout: 500 * time.Millisecond}
+	err = NewGrove(context.Background(), server.URL+"/repo.git", filepath.Join(t.TempDir(), "grove"), opts)
+	// The remote has no repository to list, once authenticated
+	if err == nil {
+		t.Fatal("NewGrove() succeeded, want the remote's failure")
+	}
+	if strings.Contains(err.Error(), "timed out") {
+		t.Errorf("NewGrove() returned error %v, want no timeout", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !authorized {
+		t.Error("remote was never sent the credentials")
+	}
+}
+
+// redirect replaces the given standard stream with a temporary file for the rest of the test, returning the file
+func redirect(t *testing.T, stream **os.File) *os.File {
+	t.Helper()
+
+	f, err := os.CreateTemp(t.TempDir(), "stream")
+	if err != nil {
+		t.Fatalf("failed to create file: %v", err)
+	}
+	original := *stream
+	*stream = f
+	t.Cleanup(func() {
+		*stream = original
+		f.Close()
+	})
+	return f
+}
+
+// mkdir creates the directory at path, along with its parents
+func mkdir(t *testing.T, path string) {
+	t.Helper()
+
+	err := os.MkdirAll(path, 0o755)
+	if err != nil {
+		t.Fatalf("failed to create directory %q: %v", path, err)
+	}
+}
+
+// writeFile writes an empty file at path, creating its parent directories
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+
+	mkdir(t, filepath.Dir(path))
+	err := os.WriteFile(path, nil, 0o644)
+	if err != nil {
+		t.Fatalf("failed to write %q: %v", path, err)
+	}
+}
@@ -0,0 +1,145 @@
+package list
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+const (
+	// shortHashLength is the number of characters of each tree's HEAD commit hash displayed
+	shortHashLength = 7
+)
+
+var Command = &cobra.Command{
+	Use:   "list",
+	Short: "List the trees in the grove",
+	Long: `Lists each tree in the grove, starting with the main worktree.
+
+Each tree is displayed with its path relative to the grove's root, the branch it has checked out, its HEAD commit,
+and whether it is locked.
+
+With --sort, trees are ordered by 'path', 'branch', 'date' - of their HEAD commit, most recent first - or by how many
+commits they are 'ahead' of or 'behind' their upstream, most first. --dirty, --ahead, and --behind list only the trees
+with uncommitted changes, or which are ahead of or behind their upstream, respectively.
+
+Trees can also be limited to those whose branch matches --match, and not --exclude. Each pattern is a glob, such as
+'release/*', or a regular expression wrapped in slashes, such as '/^v[0-9]+$/'.
+
+With --output-format json or yaml, the trees are printed as a list of objects instead, each holding the tree's absolute
+path, branch, full HEAD commit hash, and lock state.`,
+	Example: `
+List the trees with uncommitted changes, most recently committed to first:
+
+	grove list --dirty --sort date
+
+Print the path of every locked tree:
+
+	grove list --output-format json | jq -r '.[] | select(.locked) | .path'
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		format, err := output.ParseResultFormat(cmd.Flag(output.ResultFormatFlag).Value.String())
+		if err != nil {
+			return err
+		}
+		key, err := grove.ParseSortKey(sortKey)
+		if err != nil {
+			return err
+		}
+		sel, err := grove.ParseSelector(match, exclude)
+		if err != nil {
+			return err
+		}
+		return List(grove.ListOptions{
+			Sort:     key,
+			Dirty:    dirty,
+			Ahead:    ahead,
+			Behind:   behind,
+			Selector: sel,
+		}, format)
+	},
+}
+
+var (
+	sortKey string
+	dirty   bool
+	ahead   bool
+	behind  bool
+	match   []string
+	exclude []string
+)
+
+func init() {
+	Command.Flags().StringVar(&sortKey, "sort", "", "order trees by 'path', 'branch', 'date', 'ahead', or 'behind' (defaults to the main worktree first, followed by each linked worktree)")
+	Command.Flags().BoolVar(&dirty, "dirty", false, "only list trees with uncommitted changes or untracked files")
+	Command.Flags().BoolVar(&ahead, "ahead", false, "only list trees whose branch is ahead of its upstream")
+	Command.Flags().BoolVar(&behind, "behind", false, "only list trees whose branch is behind its upstream")
+	Command.Flags().StringSliceVar(&match, "match", nil, "only list trees whose branch matches the given glob or /regex/ (may be repeated)")
+	Command.Flags().StringSliceVar(&exclude, "exclude", nil, "skip trees whose branch matches the given glob or /regex/ (may be repeated)")
+}
+
+// List prints the trees of the grove matching opts, in the given format
+func List(opts grove.ListOptions, format output.ResultFormat) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return fmt.Errorf("failed to determine grove root: %w", err)
+	}
+
+	infos, err := g.ListTrees(opts)
+	if err != nil {
+		return fmt.Errorf("failed to list trees: %w", err)
+	}
+	trees := make([]grove.Tree, 0, len(infos))
+	for _, info := range infos {
+		trees = append(trees, info.Tree)
+	}
+
+	return output.Render(os.Stdout, format, trees, func(out io.Writer) error {
+		return printTable(out, root, trees)
+	})
+}
+
+// printTable writes trees to out as a table, with paths relative to the grove's root
+func printTable(out io.Writer, root string, trees []grove.Tree) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TREE\tBRANCH\tHEAD\tLOCKED")
+	for _, tree := range trees {
+		path, err := filepath.Rel(root, tree.Path)
+		if err != nil {
+			path = tree.Path
+		}
+
+		branch := tree.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+
+		hash := tree.Hash
+		if len(hash) > shortHashLength {
+			hash = hash[:shortHashLength]
+		}
+
+		locked := "no"
+		if tree.Locked {
+			locked = "yes"
+			if tree.LockReason != "" {
+				locked = fmt.Sprintf("yes (%s)", tree.LockReason)
+			}
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", path, branch, hash, locked)
+	}
+	return w.Flush()
+}
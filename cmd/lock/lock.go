@@ -0,0 +1,47 @@
+package lock
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "lock <tree>",
+	Short: "Lock a tree to prevent it from being pruned",
+	Long: `Locks a tree, preventing it from being pruned while it is unavailable - for example, when it resides on removable
+media or a network mount.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
+The grove's main worktree cannot be locked.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		path := args[0]
+		err := LockTree(path, reason)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+var reason string
+
+func init() {
+	Command.Flags().StringVar(&reason, "reason", "", "explanation of why the tree is locked")
+}
+
+func LockTree(path, reason string) error {
+	grove, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	err = grove.LockTree(path, reason)
+	if err != nil {
+		return fmt.Errorf("failed to lock tree %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/git/local"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "log [<tree>]",
+	Short: "Show the commit history of a tree",
+	Long: `Shows the commits in the history of a tree's HEAD, most recent first, as with 'git log' - without having to enter
+the tree. If no tree is given, the tree containing the current directory is used.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
+
+Each commit is printed with its hash, author, date, and subject; --oneline prints only its abbreviated hash and subject.
+With --output-format json or yaml, the commits are printed as a list of objects instead.`,
+	Example: `
+Peek at the last 10 commits of the branch checked out in "feature-x":
+
+	grove log feature-x -n 10
+	`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := output.ParseResultFormat(cmd.Flag(output.ResultFormatFlag).Value.String())
+		if err != nil {
+			return err
+		}
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+		return Log(path, maxCount, oneline, format)
+	},
+}
+
+var (
+	maxCount int
+	oneline  bool
+)
+
+func init() {
+	Command.Flags().IntVarP(&maxCount, "max-count", "n", 0, "limit the log to the given number of commits (defaults to the full history)")
+	Command.Flags().BoolVar(&oneline, "oneline", false, "print each commit on a single line, as its abbreviated hash and subject")
+}
+
+// Log prints up to limit commits in the history of the tree at the given path, or the tree containing the current
+// directory if path is empty, in the given format. If limit is not positive, the full history is printed
+func Log(path string, limit int, oneline bool, format output.ResultFormat) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	if path == "" {
+		tree, err := g.CurrentTree()
+		if err != nil {
+			return fmt.Errorf("failed to determine current tree: %w", err)
+		}
+		path = tree.Path
+	}
+
+	entries, err := g.Log(path, limit)
+	if err != nil {
+		return fmt.Errorf("failed to read history of tree %q: %w", path, err)
+	}
+	return output.Render(os.Stdout, format, entries, func(out io.Writer) error {
+		return printLog(out, entries, oneline)
+	})
+}
+
+// printLog writes entries to out in the style of 'git log', or 'git log --oneline' if oneline is set
+func printLog(out io.Writer, entries []local.LogEntry, oneline bool) error {
+	for i, entry := range entries {
+		var err error
+		if oneline {
+			_, err = fmt.Fprintf(out, "%s %s\n", entry.Hash[:7], entry.Subject)
+		} else {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			_, err = fmt.Fprintf(out, "commit %s\nAuthor: %s <%s>\nDate:   %s\n\n    %s\n", entry.Hash, entry.Author, entry.Email, entry.Date.Format("Mon Jan 2 15:04:05 2006 -0700"), entry.Subject)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
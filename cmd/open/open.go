@@ -0,0 +1,84 @@
+package open
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "open [<tree>]",
+	Short: "Open a tree in an editor",
+	Long: `Opens the root directory of a tree in an editor or IDE. If no tree is given, the tree containing the current
+directory is opened.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
+
+The editor is the command given by --with, falling back to $VISUAL, then $EDITOR. It is run via the shell with the tree's
+path appended, so it may include its own arguments.`,
+	Example: `
+Open the tree "feature-x" in VS Code, in a new window:
+
+	grove open feature-x --with 'code -n'
+
+Open the current tree in $VISUAL or $EDITOR:
+
+	grove open
+	`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+		err := Open(path, with)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+var with string
+
+func init() {
+	Command.Flags().StringVar(&with, "with", "", "command used to open the tree (defaults to $VISUAL, then $EDITOR)")
+}
+
+// Open runs the editor in the tree at the given path, or the tree containing the current directory if path is empty
+func Open(path, editor string) error {
+	if editor == "" {
+		editor = os.Getenv("VISUAL")
+	}
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		return fmt.Errorf("no editor configured: provide one with --with, or set $VISUAL or $EDITOR")
+	}
+
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	tree, err := g.CurrentOrNamedTree(path)
+	if err != nil {
+		return err
+	}
+
+	// The editor is run via the shell so that it may contain its own arguments; "$@" expands to the tree's path
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, tree.Path)
+	cmd.Dir = tree.Path
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("failed to open tree %q with %q: %w", tree.Path, editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package pr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/git/remote"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "pr <number>",
+	Short: "Create a tree for a pull request",
+	Long: `Fetches the head of a pull request into the remote-tracking ref '<remote>/pr/<number>', then creates a tree
+checking out a new branch 'pr/<number>' at it. The tree is placed according to --layout, as with 'grove add --branch'.
+
+Where the pull request's head is found depends on the provider hosting the remote: GitHub, Gitea, and Forgejo publish it
+at 'refs/pull/<number>/head', while GitLab publishes merge requests at 'refs/merge-requests/<number>/head'. The provider
+is detected from the remote's hostname unless --provider is given.
+
+If a tree for the pull request already exists, the remote-tracking ref is still updated, but the tree is left untouched.`,
+	Example: `
+Review pull request #123 of origin:
+
+	grove pr 123
+
+Review merge request !45 of a self-hosted GitLab instance:
+
+	grove pr --provider gitlab 45
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		number, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid pull request number %q", args[0])
+		}
+		p, err := grove.ParseProvider(provider)
+		if err != nil {
+			return err
+		}
+		l, err := grove.ParseLayout(layout)
+		if err != nil {
+			return err
+		}
+		err = PullRequest(number, remoteName, p, l, quiet)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+var (
+	remoteName string
+	provider   string
+	layout     string
+	quiet      bool
+)
+
+func init() {
+	Command.Flags().StringVar(&remoteName, "remote", remote.DefaultRemoteName, "remote the pull request was opened against")
+	Command.Flags().StringVar(&provider, "provider", "", "provider hosting the remote: 'github' or 'gitlab' (defaults to detecting it from the remote's URL)")
+	Command.Flags().StringVar(&layout, "layout", grove.LayoutPath, "where the tree is placed: 'path', 'flat', or a template such as 'wt/{{.Branch}}'")
+	Command.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress progress output while fetching")
+}
+
+// PullRequest fetches the given pull request from the named remote and creates a tree for it
+func PullRequest(number int, name string, provider grove.Provider, layout grove.Layout, quiet bool) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+	g.Layout = layout
+
+	var progress io.Writer = output.NewProgressBar(os.Stdout)
+	if quiet {
+		progress = io.Discard
+	}
+	tracking, err := g.FetchPullRequest(name, number, provider, progress)
+	if err != nil {
+		return err
+	}
+
+	branch := grove.PullRequestBranch(number)
+	path, err := g.BranchTreePath(branch)
+	if err != nil {
+		return err
+	}
+	err = g.AddTree(path, grove.AddTreeOptions{
+		Branch: branch,
+		Commit: tracking.String(),
+	})
+	if err != nil {
+		checkedOut := &grove.BranchCheckedOutError{}
+		if errors.As(err, &checkedOut) {
+			fmt.Printf("pull request %d is already checked out in tree %q; updated %q\n", number, checkedOut.Tree, tracking.Short())
+			return nil
+		}
+		return fmt.Errorf("failed to add tree %q for pull request %d: %w", path, number, err)
+	}
+	fmt.Printf("added tree %q for pull request %d\n", path, number)
+	return nil
+}
@@ -0,0 +1,189 @@
+package prune
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove stale trees",
+	Long: `Removes the trees whose branches are stale: those merged into the default branch with --merged, those whose last
+commit is older than the given age with --older-than, or - when both are given - those satisfying both.
+
+Nothing is removed unless --yes is provided; instead, the trees which would be removed are listed. Branches are kept,
+only their trees are removed.
+
+The main worktree, trees with a detached HEAD, and the tree of the base branch are never pruned. Trees which are locked,
+have uncommitted changes or untracked files, or contain the current working directory are kept, and listed with the
+reason why. Trees can be limited to those whose branch matches --match, and not --exclude. Each pattern is a glob, such
+as 'release/*', or a regular expression wrapped in slashes, such as '/^v[0-9]+$/'.
+
+Ages are given as a number followed by a unit: 'd' for days or 'w' for weeks, or any unit understood by Go's
+time.ParseDuration, such as '12h'.`,
+	Example: `
+List the trees of branches merged into the default branch more than 30 days ago:
+
+	grove prune --merged --older-than 30d
+
+Remove them:
+
+	grove prune --merged --older-than 30d --yes
+
+Remove the trees of branches merged into 'release/2.0':
+
+	grove prune --merged --base release/2.0 --yes
+
+List the trees of feature branches without a commit in 12 weeks:
+
+	grove prune --older-than 12w --match 'feature/*'
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		sel, err := grove.ParseSelector(match, exclude)
+		if err != nil {
+			return err
+		}
+		opts := grove.PruneOptions{
+			Merged:   merged,
+			Base:     base,
+			Selector: sel,
+		}
+		if olderThan != "" {
+			age, err := parseAge(olderThan)
+			if err != nil {
+				return err
+			}
+			opts.OlderThan = age
+		}
+		if !opts.Merged && opts.OlderThan == 0 {
+			return fmt.Errorf("at least one of --merged or --older-than must be provided")
+		}
+
+		err = Prune(opts, yes)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+var (
+	merged    bool
+	base      string
+	olderThan string
+	yes       bool
+	match     []string
+	exclude   []string
+)
+
+func init() {
+	Command.Flags().BoolVar(&merged, "merged", false, "prune trees whose branch has been merged into the base")
+	Command.Flags().StringVar(&base, "base", "", "revision branches must be merged into (defaults to the grove's default branch)")
+	Command.Flags().StringVar(&olderThan, "older-than", "", "prune trees whose last commit is older than the given age, such as '30d'")
+	Command.Flags().BoolVarP(&yes, "yes", "y", false, "remove the stale trees, rather than only listing them")
+	Command.Flags().StringSliceVar(&match, "match", nil, "only prune trees whose branch matches the given glob or /regex/ (may be repeated)")
+	Command.Flags().StringSliceVar(&exclude, "exclude", nil, "never prune trees whose branch matches the given glob or /regex/ (may be repeated)")
+}
+
+// Prune lists the trees of the current grove which are stale according to opts, removing them if remove is set
+func Prune(opts grove.PruneOptions, remove bool) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return fmt.Errorf("failed to determine grove root: %w", err)
+	}
+
+	now := time.Now()
+	trees, err := g.StaleTrees(opts, now)
+	if err != nil {
+		return fmt.Errorf("failed to find stale trees: %w", err)
+	}
+	if len(trees) == 0 {
+		fmt.Println("No stale trees found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TREE\tBRANCH\tLAST COMMIT\tACTION")
+	failed := 0
+	for _, tree := range trees {
+		name, err := filepath.Rel(root, tree.Path)
+		if err != nil {
+			name = tree.Path
+		}
+
+		action := "would remove"
+		switch {
+		case tree.Skipped != "":
+			action = "kept: " + tree.Skipped
+		case remove:
+			action = "removed"
+			err = g.RemoveTree(tree.Path)
+			if err != nil {
+				failed++
+				action = "failed"
+				fmt.Fprintf(os.Stderr, "error: failed to remove tree %q: %v\n", name, err)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", name, tree.Branch, age(now.Sub(tree.LastCommit)), action)
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to remove %d of %d trees", failed, len(trees))
+	}
+	if !remove {
+		fmt.Println("\nRun again with --yes to remove these trees")
+	}
+	return nil
+}
+
+// parseAge parses an age such as '30d' or '2w', or any duration understood by time.ParseDuration
+func parseAge(value string) (time.Duration, error) {
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		count, ok := strings.CutSuffix(value, suffix)
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q: expected a number of %s", value, suffix)
+		}
+		return time.Duration(n) * unit, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q: expected a number followed by 'd', 'w', or a unit such as 'h'", value)
+	}
+	return age, nil
+}
+
+// age describes the given duration in whole days, or hours when less than a day
+func age(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	if days > 0 {
+		return fmt.Sprintf("%d days ago", days)
+	}
+	return fmt.Sprintf("%d hours ago", int(d/time.Hour))
+}
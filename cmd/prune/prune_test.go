@@ -0,0 +1,164 @@
+package prune
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "30d", want: 30 * 24 * time.Hour},
+		{value: "2w", want: 14 * 24 * time.Hour},
+		{value: "0d", want: 0},
+		{value: "12h", want: 12 * time.Hour},
+		{value: "90m", want: 90 * time.Minute},
+		{value: "d", wantErr: true},
+		{value: "-1d", wantErr: true},
+		{value: "1.5w", wantErr: true},
+		{value: "-2h", wantErr: true},
+		{value: "soon", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseAge(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseAge(%q) = %v, want an error", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAge(%q) returned error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseAge(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 3 * time.Hour, want: "3 hours ago"},
+		{d: 25 * time.Hour, want: "1 days ago"},
+		{d: 30 * 24 * time.Hour, want: "30 days ago"},
+	}
+	for _, tt := range tests {
+		got := age(tt.d)
+		if got != tt.want {
+			t.Errorf("age(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to resolve temporary directory: %v", err)
+	}
+	mainTree := filepath.Join(root, "main")
+	_, err = git.PlainInit(mainTree, false, git.WithDefaultBranch(plumbing.NewBranchReferenceName("main")))
+	if err != nil {
+		t.Fatalf("failed to initialize repository: %v", err)
+	}
+	commitFile(t, mainTree, "README.md")
+
+	g, err := grove.OpenAt(mainTree)
+	if err != nil {
+		t.Fatalf("failed to open grove: %v", err)
+	}
+	// Every tree's branch is merged into 'main', as none has commits of its own
+	for _, name := range []string{"merged", "locked", "modified", "untracked", "current"} {
+		err = g.AddTree(name, grove.AddTreeOptions{NoHooks: true})
+		if err != nil {
+			t.Fatalf("failed to add tree %q: %v", name, err)
+		}
+	}
+	err = g.LockTree(filepath.Join(root, "locked"), "in use")
+	if err != nil {
+		t.Fatalf("failed to lock tree: %v", err)
+	}
+	writeFile(t, filepath.Join(root, "modified", "README.md"))
+	writeFile(t, filepath.Join(root, "untracked", "newfile.txt"))
+	t.Chdir(filepath.Join(root, "current"))
+
+	// Without --yes, nothing is removed
+	err = Prune(grove.PruneOptions{Merged: true}, false)
+	if err != nil {
+		t.Fatalf("Prune() returned error: %v", err)
+	}
+	_, err = os.Stat(filepath.Join(root, "merged"))
+	if err != nil {
+		t.Fatalf("tree was removed without --yes: %v", err)
+	}
+
+	err = Prune(grove.PruneOptions{Merged: true}, true)
+	if err != nil {
+		t.Fatalf("Prune() returned error: %v", err)
+	}
+	_, err = os.Stat(filepath.Join(root, "merged"))
+	if !os.IsNotExist(err) {
+		t.Errorf("merged tree was not removed: %v", err)
+	}
+	kept := []string{
+		filepath.Join("locked", "README.md"),
+		filepath.Join("modified", "README.md"),
+		filepath.Join("untracked", "newfile.txt"),
+		filepath.Join("current", "README.md"),
+	}
+	for _, path := range kept {
+		_, err = os.Stat(filepath.Join(root, path))
+		if err != nil {
+			t.Errorf("%q was removed, want it kept: %v", path, err)
+		}
+	}
+}
+
+// commitFile writes, then commits the file of the given name in the worktree at path
+func commitFile(t *testing.T, path, name string) {
+	t.Helper()
+
+	writeFile(t, filepath.Join(path, name))
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		t.Fatalf("failed to open repository %q: %v", path, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to open worktree %q: %v", path, err)
+	}
+	_, err = wt.Add(name)
+	if err != nil {
+		t.Fatalf("failed to stage %q: %v", name, err)
+	}
+	signature := &object.Signature{Name: "grove", Email: "grove@example.com", When: time.Now()}
+	_, err = wt.Commit("add "+name, &git.CommitOptions{Author: signature, Committer: signature})
+	if err != nil {
+		t.Fatalf("failed to commit %q: %v", name, err)
+	}
+}
+
+// writeFile writes a file at path, whose content is its own path
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+
+	err := os.WriteFile(path, []byte(path+"\n"), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write %q: %v", path, err)
+	}
+}
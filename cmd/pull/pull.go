@@ -0,0 +1,148 @@
+package pull
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/git/local"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "pull [<tree>]",
+	Short: "Fetch and update a single tree, or every selected tree",
+	Long: `Fetches the upstream of the branch checked out in a tree, then fast-forwards the tree to it. If no tree is
+given, the tree containing the current directory is pulled.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
+
+With --match or --exclude, every tree whose branch matches --match, and not --exclude, is pulled instead. Each pattern
+is a glob, such as 'release/*', or a regular expression wrapped in slashes, such as '/^v[0-9]+$/'. Trees with a detached
+HEAD have no upstream, so are skipped. Every tree is pulled even if pulling some of them fails.
+
+If the tree's branch has diverged from its upstream, the pull is aborted rather than creating a merge commit. Use
+--rebase to replay the tree's local commits on top of its upstream instead.`,
+	Example: `
+Pull every release branch:
+
+	grove pull --match 'release/*'
+	`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		if len(match) > 0 || len(exclude) > 0 {
+			if len(args) > 0 {
+				return fmt.Errorf("cannot pull tree %q along with the trees selected by --match or --exclude", args[0])
+			}
+			sel, err := grove.ParseSelector(match, exclude)
+			if err != nil {
+				return err
+			}
+			return PullSelected(sel, rebase, quiet)
+		}
+
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+		err := Pull(path, rebase, quiet)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+var (
+	rebase  bool
+	quiet   bool
+	match   []string
+	exclude []string
+)
+
+func init() {
+	Command.Flags().BoolVar(&rebase, "rebase", false, "rebase the tree's local commits onto its upstream when they have diverged")
+	Command.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress progress output while fetching")
+	Command.Flags().StringSliceVar(&match, "match", nil, "pull every tree whose branch matches the given glob or /regex/ (may be repeated)")
+	Command.Flags().StringSliceVar(&exclude, "exclude", nil, "pull every tree except those whose branch matches the given glob or /regex/ (may be repeated)")
+}
+
+// Pull fetches and updates the tree at the given path, or the tree containing the current directory if path is empty
+func Pull(path string, rebase, quiet bool) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	if path == "" {
+		tree, err := g.CurrentTree()
+		if err != nil {
+			return fmt.Errorf("failed to determine current tree: %w", err)
+		}
+		path = tree.Path
+	}
+	return pull(g, path, rebase, quiet)
+}
+
+// PullSelected fetches and updates every tree in the grove selected by sel, skipping those with a detached HEAD. An
+// error is returned if any of them failed to be pulled
+func PullSelected(sel grove.Selector, rebase, quiet bool) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	trees, err := g.Trees()
+	if err != nil {
+		return fmt.Errorf("failed to list trees: %w", err)
+	}
+
+	pulled, failed := 0, 0
+	for _, tree := range sel.Select(trees) {
+		if tree.Branch == "" {
+			fmt.Printf("skipped tree %q: HEAD is detached\n", tree.Path)
+			continue
+		}
+		pulled++
+		err = pull(g, tree.Path, rebase, quiet)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to pull %d of %d trees", failed, pulled)
+	}
+	return nil
+}
+
+// pull fetches and updates the tree at the given path, reporting the outcome
+func pull(g *grove.Grove, path string, rebase, quiet bool) error {
+	opts := grove.PullOptions{
+		Rebase:   rebase,
+		Progress: output.NewProgressBar(os.Stdout),
+	}
+	if quiet {
+		opts.Progress = nil
+	}
+	result, err := g.Pull(path, opts)
+	if err != nil {
+		if errors.Is(err, local.ErrDiverged) {
+			return fmt.Errorf("failed to pull tree %q: %w (use --rebase to rebase onto it instead)", path, err)
+		}
+		return fmt.Errorf("failed to pull tree %q: %w", path, err)
+	}
+
+	switch {
+	case result.Tree.Hash == result.Hash:
+		fmt.Printf("tree %q is already up to date with %q\n", result.Tree.Path, result.Upstream)
+	case result.Rebased:
+		fmt.Printf("rebased tree %q onto %q\n", result.Tree.Path, result.Upstream)
+	default:
+		fmt.Printf("fast-forwarded tree %q to %q (%.7s..%.7s)\n", result.Tree.Path, result.Upstream, result.Tree.Hash, result.Hash)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package push
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "push [<tree>]",
+	Short: "Push a tree's branch to its upstream",
+	Long: `Pushes the branch checked out in a tree to its upstream. If no tree is given, the tree containing the current
+directory is pushed.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
+
+If the branch has no upstream, it is pushed to the branch of the same name on the 'origin' remote, which is then
+configured as the branch's upstream.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+		err := Push(path, forceWithLease, quiet)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+var (
+	forceWithLease bool
+	quiet          bool
+)
+
+func init() {
+	Command.Flags().BoolVar(&forceWithLease, "force-with-lease", false, "overwrite the upstream branch, as long as it hasn't changed since it was last fetched")
+	Command.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress progress output while pushing")
+}
+
+// Push pushes the branch of the tree at the given path, or of the tree containing the current directory if path is empty
+func Push(path string, forceWithLease, quiet bool) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	if path == "" {
+		tree, err := g.CurrentTree()
+		if err != nil {
+			return fmt.Errorf("failed to determine current tree: %w", err)
+		}
+		path = tree.Path
+	}
+
+	opts := grove.PushOptions{
+		ForceWithLease: forceWithLease,
+		Progress:       output.NewProgressBar(os.Stdout),
+	}
+	if quiet {
+		opts.Progress = nil
+	}
+	result, err := g.Push(path, opts)
+	if err != nil {
+		return fmt.Errorf("failed to push tree %q: %w", path, err)
+	}
+
+	if result.UpToDate {
+		fmt.Printf("branch %q of tree %q is already up to date with %q on %q\n", result.Tree.Branch, result.Tree.Path, result.Branch, result.Remote)
+	} else {
+		fmt.Printf("pushed branch %q of tree %q to %q on %q\n", result.Tree.Branch, result.Tree.Path, result.Branch, result.Remote)
+	}
+	if result.SetUpstream {
+		fmt.Printf("branch %q now tracks %q on %q\n", result.Tree.Branch, result.Branch, result.Remote)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package relocate
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "relocate <new-root>",
+	Short: "Move the entire grove to a new location",
+	Long: `Moves the entire grove - its repository and every tree - to a new root directory, then repairs the locations git
+records for each tree, so that the grove remains usable once moved. Moving a grove with 'mv' instead leaves every linked
+tree pointing at the old location.
+
+The new root is relative to the current directory, unless absolute. It must either not exist, or be an empty directory.
+It may lie on a different disk, in which case the grove is copied there, then removed from its old location.
+
+Trees which were created outside of the grove's root are left in place, and relinked to the moved repository.`,
+	Example: `
+Move the grove containing the current directory to another disk:
+
+	grove relocate /mnt/data/linux
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		return Relocate(args[0])
+	},
+}
+
+// Relocate moves the grove containing the current directory to destination
+func Relocate(destination string) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return fmt.Errorf("failed to determine grove root: %w", err)
+	}
+
+	moved, err := g.Relocate(destination)
+	if err != nil {
+		return fmt.Errorf("failed to relocate grove %q: %w", root, err)
+	}
+	fmt.Printf("moved grove %q to %q: run 'cd %s' to continue working in it\n", root, moved, moved)
+	return nil
+}
@@ -0,0 +1,259 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/git/local"
+	gitremote "github.com/tnierman/git-grove/pkg/git/remote"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "remote",
+	Short: "Manage the grove's remotes",
+	Long: `Manages the remotes of the grove's repository, which are shared by every tree.
+
+The URL of each remote must use a transport supported by grove: HTTP(S), or SSH.
+
+A remote's URL is changed with 'set-url'. Rather than giving the new URL, --to-ssh or --to-https switches the remote
+between SSH and HTTPS - such as between 'git@github.com:org/repo.git' and 'https://github.com/org/repo.git' - keeping its
+host and path. Hosts which are aliases in ~/.ssh/config are replaced by their HostName when switching to HTTPS.`,
+	Example: `
+Add the repository a fork was created from, and fetch its branches:
+
+	grove remote add --fetch upstream https://github.com/torvalds/linux.git
+
+Switch the remote 'origin' to SSH:
+
+	grove remote set-url --to-ssh origin
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return cmd.Help()
+	},
+}
+
+var addCommand = &cobra.Command{
+	Use:   "add <name> <url>",
+	Short: "Add a remote",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 2 arguments to this command
+		return Add(args[0], args[1], fetch, quiet)
+	},
+}
+
+var listCommand = &cobra.Command{
+	Use:   "list",
+	Short: "List every remote",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return List()
+	},
+}
+
+var setURLCommand = &cobra.Command{
+	Use:   "set-url <name> [<url>]",
+	Short: "Change the URL of a remote",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		url := ""
+		if len(args) > 1 {
+			url = args[1]
+		}
+		switch {
+		case toSSH || toHTTPS:
+			if url != "" {
+				return fmt.Errorf("either a URL, or --to-ssh or --to-https, must be provided, not both")
+			}
+			return ConvertURL(args[0], toSSH)
+		case url == "":
+			return fmt.Errorf("a URL, --to-ssh, or --to-https must be provided")
+		}
+		return SetURL(args[0], url)
+	},
+}
+
+var removeCommand = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a remote, along with its remote-tracking branches",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		return Remove(args[0])
+	},
+}
+
+var (
+	fetch bool
+	quiet bool
+
+	toSSH   bool
+	toHTTPS bool
+)
+
+func init() {
+	addCommand.Flags().BoolVarP(&fetch, "fetch", "f", false, "fetch the remote's branches after adding it")
+	addCommand.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress progress output while fetching")
+	setURLCommand.Flags().BoolVar(&toSSH, "to-ssh", false, "switch the remote's HTTPS URL to SSH, keeping its host and path")
+	setURLCommand.Flags().BoolVar(&toHTTPS, "to-https", false, "switch the remote's SSH URL to HTTPS, keeping its host and path")
+	setURLCommand.MarkFlagsMutuallyExclusive("to-ssh", "to-https")
+	Command.AddCommand(addCommand)
+	Command.AddCommand(listCommand)
+	Command.AddCommand(removeCommand)
+	Command.AddCommand(setURLCommand)
+}
+
+// Add adds a remote with the given name and URL to the grove, and fetches it if fetch is set
+func Add(name, url string, fetch, quiet bool) error {
+	// Validate the URL in the same way as when cloning, so that the remote can be used by grove
+	_, err := gitremote.AuthMethod(url)
+	if err != nil {
+		return err
+	}
+
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	err = g.AddRemote(name, url)
+	if err != nil {
+		return err
+	}
+	if !fetch {
+		return nil
+	}
+
+	repository, err := gitremote.NewRepository(url)
+	if err != nil {
+		return err
+	}
+	auth, err := repository.Auth(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to authenticate with %q: %w", url, err)
+	}
+
+	var progress io.Writer = output.NewProgressBar(os.Stdout)
+	if quiet {
+		progress = io.Discard
+	}
+	return g.Fetch(context.Background(), name, nil, auth, progress)
+}
+
+// List prints every remote of the grove, along with its URLs and the transport used to access it
+func List() error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	remotes, err := g.Remotes()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTRANSPORT\tURL")
+	for _, remote := range remotes {
+		url := strings.Join(remote.URLs, ",")
+		fmt.Fprintf(w, "%s\t%s\t%s\n", remote.Name, transport(remote.URLs), url)
+	}
+	return w.Flush()
+}
+
+// transport describes how grove accesses the remote at the given URLs
+func transport(urls []string) string {
+	if len(urls) == 0 {
+		return "-"
+	}
+	auth, err := gitremote.AuthMethod(urls[0])
+	if err != nil {
+		return "unsupported"
+	}
+	return string(auth.Protocol())
+}
+
+// SetURL replaces the URL of the remote with the given name
+func SetURL(name, url string) error {
+	// Catch mistyped URLs, then validate the URL in the same way as when adding a remote, so that the remote remains
+	// usable by grove
+	url, err := gitremote.NormalizeURL(url)
+	if err != nil {
+		return err
+	}
+	_, err = gitremote.AuthMethod(url)
+	if err != nil {
+		return err
+	}
+
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+	return g.SetRemoteURL(name, url)
+}
+
+// ConvertURL switches the remote with the given name to SSH if toSSH is set, or HTTPS otherwise, rewriting its URL while
+// keeping its host and path
+func ConvertURL(name string, toSSH bool) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	remotes, err := g.Remotes()
+	if err != nil {
+		return err
+	}
+	idx := slices.IndexFunc(remotes, func(remote local.Remote) bool {
+		return remote.Name == name
+	})
+	if idx < 0 || len(remotes[idx].URLs) == 0 {
+		return fmt.Errorf("remote %q does not exist, or has no URL", name)
+	}
+	current := remotes[idx].URLs[0]
+
+	target := gitremote.ProtocolHTTP
+	convert := gitremote.HTTPSURL
+	if toSSH {
+		target = gitremote.ProtocolSSH
+		convert = gitremote.SSHURL
+	}
+	if gitremote.DetectProtocol(current) == target {
+		fmt.Printf("remote %q already uses %s: %s\n", name, strings.ToUpper(string(target)), current)
+		return nil
+	}
+	url, ok := convert(current)
+	if !ok {
+		return fmt.Errorf("cannot switch remote %q to %s: %q is neither an SSH nor HTTP(S) URL", name, strings.ToUpper(string(target)), current)
+	}
+
+	_, err = gitremote.AuthMethod(url)
+	if err != nil {
+		return err
+	}
+	err = g.SetRemoteURL(name, url)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("remote %q: %s -> %s\n", name, current, url)
+	return nil
+}
+
+// Remove removes the remote with the given name from the grove
+func Remove(name string) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+	return g.RemoveRemote(name)
+}
@@ -0,0 +1,47 @@
+package renamebranch
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "rename-branch <tree> <branch>",
+	Short: "Rename a tree's branch, moving the tree to match",
+	Long: `Renames the branch checked out in a tree, and moves the tree to the directory named after the new branch.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
+The tree is moved to the path matching the new branch name relative to the grove's root, keeping each tree's directory in line
+with its branch. The command fails if that directory already exists.`,
+	Example: `
+Rename the branch "feature" to "feature-v2", moving the tree from "<grove>/feature" to "<grove>/feature-v2":
+
+	grove rename-branch feature feature-v2
+	`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 2 arguments to this command
+		tree := args[0]
+		branch := args[1]
+		err := RenameBranch(tree, branch)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+func RenameBranch(tree, branch string) error {
+	grove, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	err = grove.RenameBranch(tree, branch)
+	if err != nil {
+		return fmt.Errorf("failed to rename branch of tree %q: %w", tree, err)
+	}
+	return nil
+}
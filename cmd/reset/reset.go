@@ -0,0 +1,74 @@
+package reset
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "reset [<tree>]",
+	Short: "Discard every local change in a tree",
+	Long: `Discards every uncommitted change in a tree: the tree is hard-reset to the upstream of its branch - or to the
+revision given by --to - and untracked files and directories are removed, as with 'git reset --hard && git clean -fd'.
+Ignored files are left in place. If no tree is given, the tree containing the current directory is reset.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.
+
+This cannot be undone, so confirmation is requested first, unless --force is provided.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+		return Reset(path, revision, force)
+	},
+}
+
+var (
+	revision string
+	force    bool
+)
+
+func init() {
+	Command.Flags().StringVar(&revision, "to", "", "revision to reset the tree to (defaults to the upstream of the tree's branch)")
+	Command.Flags().BoolVarP(&force, "force", "f", false, "reset without asking for confirmation")
+}
+
+// Reset discards every local change in the tree at the given path, or the tree containing the current directory if
+// path is empty. Unless force is set, the user is asked to confirm first
+func Reset(path, revision string, force bool) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	tree, err := g.CurrentOrNamedTree(path)
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		target := revision
+		if target == "" {
+			target = "its upstream"
+		}
+		ok, err := output.Confirm(fmt.Sprintf("Discard all changes and untracked files in tree %q, and reset it to %s?", tree.Path, target))
+		if err != nil {
+			return fmt.Errorf("%w (use --force to skip confirmation)", err)
+		}
+		if !ok {
+			return fmt.Errorf("reset of tree %q cancelled", tree.Path)
+		}
+	}
+
+	commit, err := g.ResetTree(tree.Path, revision)
+	if err != nil {
+		return fmt.Errorf("failed to reset tree %q: %w", tree.Path, err)
+	}
+	fmt.Printf("reset tree %q to %.7s\n", tree.Path, commit)
+	return nil
+}
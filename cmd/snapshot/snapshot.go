@@ -0,0 +1,155 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Record and restore the grove's trees",
+	Long: `Records the grove's trees to a file, and recreates them in another grove of the same repository - such as on
+another machine, or after a fresh clone - making groves reproducible.
+
+A snapshot records the path of each tree relative to the grove's root, the branch checked out in it, and the commit
+its HEAD pointed to, as JSON.
+
+When restoring, each tree not already present is created in order: a branch which exists in the grove, either locally
+or on a remote, is checked out as it is now, while other branches are created at the recorded commit. Trees with a
+detached HEAD are checked out at the recorded commit, which must therefore exist in the grove. Trees already present
+at their recorded path - such as the default tree of a freshly-cloned grove - are skipped.`,
+	Example: `
+Recreate a grove's trees after cloning it on another machine:
+
+	grove snapshot save ~/layout.json
+	grove init https://github.com/me/project.git && cd project
+	grove snapshot restore ~/layout.json
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return cmd.Help()
+	},
+}
+
+var saveCommand = &cobra.Command{
+	Use:   "save [<file>]",
+	Short: "Record the grove's trees to a file, or stdout if none is given",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+		return Save(path)
+	},
+}
+
+var restoreCommand = &cobra.Command{
+	Use:   "restore <file>",
+	Short: "Create each tree recorded in a file, or stdin if '-' is given",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		return Restore(args[0], quiet)
+	},
+}
+
+var quiet bool
+
+func init() {
+	restoreCommand.Flags().BoolVarP(&quiet, "quiet", "q", false, "only report trees which could not be created")
+	Command.AddCommand(saveCommand)
+	Command.AddCommand(restoreCommand)
+}
+
+// Save writes a snapshot of the grove's trees to the file at path, or stdout if path is empty or '-'
+func Save(path string) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	snapshot, err := g.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot grove: %w", err)
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	data = append(data, '\n')
+
+	if path == "" || path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	err = os.WriteFile(path, data, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot %q: %w", path, err)
+	}
+	return nil
+}
+
+// Restore creates each tree recorded by the snapshot at path, or read from stdin if path is '-'
+func Restore(path string, quiet bool) error {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot %q: %w", path, err)
+	}
+	snapshot := grove.Snapshot{}
+	err = json.Unmarshal(data, &snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to parse snapshot %q: %w", path, err)
+	}
+
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	results, err := g.RestoreSnapshot(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot %q: %w", path, err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "failed to create tree %q %s: %v\n", result.Path, describe(result), result.Err)
+			continue
+		}
+		if !quiet {
+			fmt.Printf("created tree %q %s\n", result.Path, describe(result))
+		}
+	}
+
+	if !quiet {
+		fmt.Printf("created %d of %d trees\n", len(results)-failed, len(results))
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to create %d of %d trees", failed, len(results))
+	}
+	return nil
+}
+
+// describe explains what the tree of the given result checks out
+func describe(result grove.TreeResult) string {
+	if result.Branch == "" {
+		return "with a detached HEAD"
+	}
+	return fmt.Sprintf("for branch %q", result.Branch)
+}
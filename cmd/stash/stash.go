@@ -0,0 +1,119 @@
+package stash
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "stash",
+	Short: "Stash the changes of every dirty tree",
+	Long: `Stashes the uncommitted changes of every tree which has any - including untracked files - as a safety net before
+operating on many trees at once, such as pulling or rebasing them. 'grove stash pop' restores them afterwards.
+
+The trees stashed are recorded in the grove's .grove/ directory, so that 'grove stash pop' restores exactly those
+trees - even though git shares a single list of stashes between every tree. Trees cannot be stashed again until they
+have been popped. Trees can be limited to those whose branch matches --match, and not --exclude. Each pattern is a glob,
+such as 'release/*', or a regular expression wrapped in slashes, such as '/^v[0-9]+$/'.
+
+If a tree's stash cannot be popped, such as when it conflicts with changes made since, the stash is kept, and the tree
+stays recorded, so that 'grove stash pop' can be run again once the conflict is resolved.
+
+go-git cannot stash, so the git CLI is used.`,
+	Example: `
+Rebase every tree onto its upstream, without losing any uncommitted work:
+
+	grove stash && grove foreach 'git pull --rebase' && grove stash pop
+
+Stash the changes of every feature branch:
+
+	grove stash --match 'feature/*'
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		sel, err := grove.ParseSelector(match, exclude)
+		if err != nil {
+			return err
+		}
+		return Stash(sel)
+	},
+}
+
+var popCommand = &cobra.Command{
+	Use:   "pop",
+	Short: "Restore the changes of the trees stashed by 'grove stash'",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		return Pop()
+	},
+}
+
+var (
+	match   []string
+	exclude []string
+)
+
+func init() {
+	Command.Flags().StringSliceVar(&match, "match", nil, "only stash trees whose branch matches the given glob or /regex/ (may be repeated)")
+	Command.Flags().StringSliceVar(&exclude, "exclude", nil, "skip trees whose branch matches the given glob or /regex/ (may be repeated)")
+	Command.AddCommand(popCommand)
+}
+
+// Stash stashes the changes of every dirty tree in the grove selected by sel
+func Stash(sel grove.Selector) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	results, err := g.StashTrees(sel)
+	if err != nil {
+		return fmt.Errorf("failed to stash trees: %w", err)
+	}
+	if len(results) == 0 {
+		fmt.Println("no trees have changes to stash")
+		return nil
+	}
+	return report(results, "stash", "stashed")
+}
+
+// Pop restores the changes of each tree stashed by Stash
+func Pop() error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	results, err := g.PopStashes()
+	if err != nil {
+		return fmt.Errorf("failed to pop stashes: %w", err)
+	}
+	if len(results) == 0 {
+		fmt.Println("no trees are stashed")
+		return nil
+	}
+	return report(results, "pop", "popped")
+}
+
+// report prints the outcome of the given action - whose past tense is done - for each tree, returning an error if any
+// failed
+func report(results []grove.TreeResult, action, done string) error {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "failed to %s tree %q: %v\n", action, result.Path, result.Err)
+			continue
+		}
+		fmt.Printf("%s tree %q\n", done, result.Path)
+	}
+
+	fmt.Printf("%s %d of %d trees\n", done, len(results)-failed, len(results))
+	if failed > 0 {
+		return fmt.Errorf("failed to %s %d of %d trees", action, failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package status
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+// porcelainV1 is the only version of the porcelain format so far. Its columns must never change: a new version is
+// added instead
+const porcelainV1 = "v1"
+
+var Command = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state of every tree in the grove",
+	Long: `Shows the state of every tree in the grove: the branch it has checked out, how far the branch has diverged from
+its upstream, and whether the tree has uncommitted changes.
+
+With --short, or --porcelain, one line is printed per tree, without any header or decoration, for use by scripts. The
+format is stable, and versioned: '--porcelain=v1' requests this exact format, which is also the default. Each line holds
+the following tab-separated columns:
+
+	<branch>	<ahead>	<behind>	<dirty>	<path>
+
+--sort, --dirty, --ahead, and --behind order and filter the trees as with 'grove list'. Trees can also be limited to
+those whose branch matches --match, and not --exclude. Each pattern is a glob, such as 'release/*', or a regular
+expression wrapped in slashes, such as '/^v[0-9]+$/'.
+
+<branch> is the tree's branch, or '-' when its HEAD is detached. <ahead> and <behind> count the commits the branch is
+ahead of and behind its upstream, or are '-' when it has none. <dirty> is 'dirty' when the tree has any uncommitted
+changes or untracked files, and 'clean' otherwise. <path> is the absolute path of the tree.
+
+With --output-format json or yaml, the trees are printed as a list of objects instead, holding the same details as
+'grove info'. Unlike the porcelain format, fields without a value - such as the upstream of a branch with none - are
+omitted.`,
+	Example: `
+Pull every tree which is behind its upstream, and has no uncommitted changes:
+
+	grove status --porcelain=v1 | awk -F'\t' '$3 != "-" && $3 > 0 && $4 == "clean" { print $5 }' | xargs -n1 grove pull
+	`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if short && !cmd.Flags().Changed("porcelain") {
+			porcelain = porcelainV1
+		}
+		if porcelain != "" && porcelain != porcelainV1 {
+			return fmt.Errorf("unsupported porcelain version %q: only %q is supported", porcelain, porcelainV1)
+		}
+		format, err := output.ParseResultFormat(cmd.Flag(output.ResultFormatFlag).Value.String())
+		if err != nil {
+			return err
+		}
+		if porcelain != "" && format != output.ResultFormatTable {
+			return fmt.Errorf("--porcelain and --short cannot be combined with --%s %s", output.ResultFormatFlag, format)
+		}
+		key, err := grove.ParseSortKey(sortKey)
+		if err != nil {
+			return err
+		}
+		sel, err := grove.ParseSelector(match, exclude)
+		if err != nil {
+			return err
+		}
+		return Status(porcelain != "", grove.ListOptions{
+			Sort:     key,
+			Dirty:    dirty,
+			Ahead:    ahead,
+			Behind:   behind,
+			Selector: sel,
+			Details:  true,
+		}, format)
+	},
+}
+
+var (
+	short     bool
+	porcelain string
+
+	sortKey string
+	dirty   bool
+	ahead   bool
+	behind  bool
+	match   []string
+	exclude []string
+)
+
+func init() {
+	Command.Flags().BoolVarP(&short, "short", "s", false, "print one tab-separated line per tree, in the porcelain v1 format")
+	Command.Flags().StringVar(&porcelain, "porcelain", "", "print one tab-separated line per tree, in the given version of the porcelain format (defaults to 'v1')")
+	Command.Flags().Lookup("porcelain").NoOptDefVal = porcelainV1
+	Command.Flags().StringVar(&sortKey, "sort", "", "order trees by 'path', 'branch', 'date', 'ahead', or 'behind' (defaults to the main worktree first, followed by each linked worktree)")
+	Command.Flags().BoolVar(&dirty, "dirty", false, "only show trees with uncommitted changes or untracked files")
+	Command.Flags().BoolVar(&ahead, "ahead", false, "only show trees whose branch is ahead of its upstream")
+	Command.Flags().BoolVar(&behind, "behind", false, "only show trees whose branch is behind its upstream")
+	Command.Flags().StringSliceVar(&match, "match", nil, "only show trees whose branch matches the given glob or /regex/ (may be repeated)")
+	Command.Flags().StringSliceVar(&exclude, "exclude", nil, "skip trees whose branch matches the given glob or /regex/ (may be repeated)")
+}
+
+// Status prints the state of each tree in the grove matching opts, either in the given format, or in the porcelain
+// format if porcelain is set
+func Status(porcelain bool, opts grove.ListOptions, format output.ResultFormat) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return fmt.Errorf("failed to determine grove root: %w", err)
+	}
+
+	infos, err := g.ListTrees(opts)
+	if err != nil {
+		return fmt.Errorf("failed to list trees: %w", err)
+	}
+	if porcelain {
+		return printTable(os.Stdout, root, infos, true)
+	}
+	return output.Render(os.Stdout, format, infos, func(out io.Writer) error {
+		return printTable(out, root, infos, false)
+	})
+}
+
+// printTable writes infos to out as a table, with paths relative to the grove's root, or in the porcelain format if
+// porcelain is set
+func printTable(out io.Writer, root string, infos []grove.TreeInfo, porcelain bool) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if !porcelain {
+		fmt.Fprintln(w, "TREE\tBRANCH\tAHEAD\tBEHIND\tSTATUS")
+	}
+	for _, info := range infos {
+		ahead, behind := "-", "-"
+		if info.Upstream != "" {
+			ahead = strconv.Itoa(info.Ahead)
+			behind = strconv.Itoa(info.Behind)
+		}
+		if porcelain {
+			branch := info.Branch
+			if branch == "" {
+				branch = "-"
+			}
+			state := "clean"
+			if info.Dirty() {
+				state = "dirty"
+			}
+			fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\n", branch, ahead, behind, state, info.Path)
+			continue
+		}
+
+		path, err := filepath.Rel(root, info.Path)
+		if err != nil {
+			path = info.Path
+		}
+		branch := info.Branch
+		if branch == "" {
+			branch = "(detached)"
+		}
+		state := "clean"
+		if info.Dirty() {
+			state = fmt.Sprintf("%d changed, %d untracked", info.Status.Changed, info.Status.Untracked)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", path, branch, ahead, behind, state)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,102 @@
+package switchtree
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "switch <tree>",
+	Short: "Print the path of a tree, to change directory into it",
+	Long: `Prints the absolute path of a tree, so that a shell can change directory into it. A process cannot change the
+directory of the shell which ran it, so use 'cd "$(grove switch <tree>)"' - or wrap it in a shell function.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is
+assumed. Otherwise, the tree with the given branch checked out is used.
+
+With --create, the tree is created as by 'grove add' if it does not already exist, checking out the branch given by
+--branch, or otherwise the branch named after the last element of its path. If the tree already exists, its path is
+printed as is - so that 'grove switch --create' can be run again to return to it.`,
+	Example: `
+Change directory into the tree "feature-x":
+
+	cd "$(grove switch feature-x)"
+
+Start working on the new branch feature/login, in the tree "login":
+
+	cd "$(grove switch --create login --branch feature/login)"
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed("branch") && !create {
+			return fmt.Errorf("--branch requires --create")
+		}
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		return Switch(args[0], create, branch)
+	},
+}
+
+var (
+	create bool
+	branch string
+)
+
+func init() {
+	Command.Flags().BoolVarP(&create, "create", "c", false, "create the tree if it does not already exist")
+	Command.Flags().StringVarP(&branch, "branch", "b", "", "branch to check out in the tree when it is created (defaults to the last element of the tree's path)")
+}
+
+// Switch prints the absolute path of the tree at the given path, or with the given branch checked out. If create is set
+// and no such tree exists, one is created at path, checking out branch
+func Switch(path string, create bool, branch string) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	tree, found, err := findTree(g, path)
+	if err != nil {
+		return err
+	}
+	if !found {
+		if !create {
+			return fmt.Errorf("no tree found at %q, or with branch %q checked out: use --create to create it", path, path)
+		}
+		err = g.AddTree(path, grove.AddTreeOptions{Branch: branch})
+		if err != nil {
+			return fmt.Errorf("failed to add tree %q: %w", path, err)
+		}
+		tree, err = g.Tree(path)
+		if err != nil {
+			return err
+		}
+	} else if branch != "" && tree.Branch != branch {
+		fmt.Fprintf(os.Stderr, "warning: tree %q already exists with branch %q checked out, rather than %q\n", tree.Path, tree.Branch, branch)
+	}
+
+	fmt.Println(tree.Path)
+	return nil
+}
+
+// findTree finds the tree at the given path, or otherwise the tree with the branch of the same name checked out.
+// Whether a tree was found is returned
+func findTree(g *grove.Grove, path string) (grove.Tree, bool, error) {
+	tree, err := g.Tree(path)
+	if err == nil {
+		return tree, true, nil
+	}
+
+	trees, err := g.Trees()
+	if err != nil {
+		return grove.Tree{}, false, fmt.Errorf("failed to list trees: %w", err)
+	}
+	for _, tree := range trees {
+		if tree.Branch == path {
+			return tree, true, nil
+		}
+	}
+	return grove.Tree{}, false, nil
+}
@@ -0,0 +1,74 @@
+package treepath
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "tree-path <branch>",
+	Short: "Print the path of the tree holding a branch",
+	Long: `Prints the absolute path of the tree which has the given branch checked out, so that scripts can locate a branch's
+checkout. Unlike 'grove switch', which is given a tree, the tree is found by its branch alone.
+
+If no tree has the branch checked out, nothing is printed to stdout, and grove exits with a non-zero status. With
+--expected, the path the branch's tree would be placed at by 'grove add --branch' - according to --layout - is printed
+instead, whether or not it exists.`,
+	Example: `
+Run the tests of the branch feature/foo, wherever it is checked out:
+
+	(cd "$(grove tree-path feature/foo)" && make test)
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		l, err := grove.ParseLayout(layout)
+		if err != nil {
+			return err
+		}
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		return TreePath(args[0], expected, l)
+	},
+}
+
+var (
+	expected bool
+	layout   string
+)
+
+func init() {
+	Command.Flags().BoolVar(&expected, "expected", false, "print the path the branch's tree would be placed at, if no tree has it checked out")
+	Command.Flags().StringVar(&layout, "layout", grove.LayoutPath, "where the branch's tree would be placed with --expected: 'path', 'flat', or a template such as 'wt/{{.Branch}}'")
+}
+
+// TreePath prints the absolute path of the tree with branch checked out. If no tree has it checked out, the path it
+// would be placed at according to layout is printed if expected is set, or an error returned otherwise
+func TreePath(branch string, expected bool, layout grove.Layout) error {
+	g, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+	g.Layout = layout
+
+	trees, err := g.Trees()
+	if err != nil {
+		return fmt.Errorf("failed to list trees: %w", err)
+	}
+	for _, tree := range trees {
+		if tree.Branch == branch {
+			fmt.Println(tree.Path)
+			return nil
+		}
+	}
+
+	if !expected {
+		return fmt.Errorf("no tree has branch %q checked out", branch)
+	}
+	path, err := g.BranchTreePath(branch)
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+	return nil
+}
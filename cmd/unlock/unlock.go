@@ -0,0 +1,39 @@
+package unlock
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/grove"
+)
+
+var Command = &cobra.Command{
+	Use:   "unlock <tree>",
+	Short: "Unlock a previously locked tree",
+	Long: `Unlocks a tree, allowing it to be pruned once again.
+
+The tree is given as a path relative to the grove's root, unless prefixed by '/' - in which case, an absolute path is assumed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		path := args[0]
+		err := UnlockTree(path)
+		if err != nil {
+			return err
+		}
+		return nil
+	},
+}
+
+func UnlockTree(path string) error {
+	grove, err := grove.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open grove: %w", err)
+	}
+
+	err = grove.UnlockTree(path)
+	if err != nil {
+		return fmt.Errorf("failed to unlock tree %q: %w", path, err)
+	}
+	return nil
+}
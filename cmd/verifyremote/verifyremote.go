@@ -0,0 +1,136 @@
+package verifyremote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/spf13/cobra"
+	"github.com/tnierman/git-grove/pkg/git/remote"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var Command = &cobra.Command{
+	Use:   "verify-remote <url>",
+	Short: "Check that a remote can be reached and authenticated with",
+	Long: `Checks that the repository at the given URL can be reached and authenticated with, in the same way as when creating a
+grove with 'grove init', but without cloning anything: only the remote's refs are listed. The protocol used to access
+the remote and its default branch are printed.
+
+This is a quick diagnostic for network and authentication problems - such as an expired token, or a key missing from
+the SSH agent - before starting a long clone. The command fails if the remote cannot be accessed, so it can be used as
+a gate in CI.`,
+	Example: `
+Check that a repository can be cloned over SSH with the keys in the SSH agent:
+
+	grove verify-remote git@github.com:torvalds/linux.git
+	`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		format, err := output.ParseFormat(outputFormat)
+		if err != nil {
+			return err
+		}
+		opts := remote.Options{
+			IdentityFile:    identityFile,
+			Username:        username,
+			Credential:      token,
+			SSHCommand:      sshCommand,
+			InsecureSkipTLS: insecure,
+		}
+		// cobra ExactArgs guarantees exactly 1 argument to this command
+		return Verify(args[0], opts, timeout, format)
+	},
+}
+
+var (
+	identityFile string
+	username     string
+	token        string
+	sshCommand   string
+	insecure     bool
+	timeout      time.Duration
+	outputFormat string
+)
+
+func init() {
+	Command.Flags().StringVarP(&identityFile, "identity-file", "i", "", "private key used to authenticate against SSH remotes (defaults to using the SSH agent)")
+	Command.Flags().StringVarP(&username, "username", "u", "", "user to authenticate as against HTTP(S) remotes when --token is provided")
+	Command.Flags().StringVarP(&token, "token", "t", "", "password or access token used to authenticate against HTTP(S) remotes (defaults to prompting)")
+	Command.Flags().StringVar(&sshCommand, "ssh-command", "", "command run to connect to SSH remotes instead of the built-in client, as with $GIT_SSH_COMMAND (defaults to $GIT_SSH_COMMAND)")
+	Command.MarkFlagsMutuallyExclusive("ssh-command", "identity-file")
+	Command.Flags().BoolVar(&insecure, "insecure", false, "skip TLS certificate verification for HTTPS remotes (dangerous)")
+	Command.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "how long to wait for the remote to respond")
+	Command.Flags().StringVar(&outputFormat, "output", string(output.FormatText), "format of the result: 'text' for human-readable output, or 'json' for a JSON object")
+}
+
+// result describes a remote which was successfully verified
+type result struct {
+	URL           string `json:"url"`
+	Protocol      string `json:"protocol"`
+	Access        string `json:"access"`
+	Empty         bool   `json:"empty"`
+	DefaultBranch string `json:"defaultBranch"`
+}
+
+// Verify checks that the repository at the given URL can be reached and authenticated with, by listing its refs.
+// The protocol used to access it, and its default branch, are printed in the given format
+func Verify(url string, opts remote.Options, timeout time.Duration, format output.Format) error {
+	repository, err := remote.NewRepositoryWithOptions(url, opts)
+	if err != nil {
+		return fmt.Errorf("failed to connect to remote repository: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	auth, err := repository.Auth(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to authenticate with %q: %w", url, err)
+	}
+	empty, err := repository.IsEmpty(ctx)
+	if err != nil {
+		return err
+	}
+	branch, err := repository.DefaultBranch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to determine default branch for repository %q: %w", url, err)
+	}
+
+	res := result{
+		URL:           url,
+		Protocol:      string(repository.Protocol()),
+		Access:        access(repository, auth),
+		Empty:         empty,
+		DefaultBranch: branch,
+	}
+	if format == output.FormatJSON {
+		return json.NewEncoder(os.Stdout).Encode(res)
+	}
+
+	if empty {
+		branch += " (empty repository)"
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "URL:\t%s\n", res.URL)
+	fmt.Fprintf(w, "Protocol:\t%s\n", res.Protocol)
+	fmt.Fprintf(w, "Access:\t%s\n", res.Access)
+	fmt.Fprintf(w, "Default branch:\t%s\n", branch)
+	return w.Flush()
+}
+
+// access describes how the remote was accessed: anonymously, with credentials, or via an SSH command
+func access(repository *remote.Repository, auth transport.AuthMethod) string {
+	sshAuth, ok := repository.Authentication.(*remote.SSHAuthentication)
+	if ok && sshAuth.Command != "" {
+		return "ssh-command"
+	}
+	if auth == nil {
+		return "anonymous"
+	}
+	return "credentials"
+}
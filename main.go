@@ -1,16 +1,28 @@
 package main
 
 import (
+	"errors"
 	"log"
 	"os"
 
 	"github.com/tnierman/git-grove/cmd"
+	"github.com/tnierman/git-grove/cmd/exectree"
 )
 
 func main() {
 	err := cmd.Grove()
 	if err != nil {
 		log.Printf("ERROR: %v", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
+
+// exitCode determines the status grove exits with after failing with err: the exit status of the command run by
+// 'grove exec', if that is what failed, or otherwise 1
+func exitCode(err error) int {
+	exitErr := &exectree.ExitError{}
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/tnierman/git-grove/cmd/exectree"
+)
+
+func TestExitCode(t *testing.T) {
+	exitErr := &exec.ExitError{}
+	err := exec.Command("sh", "-c", "exit 3").Run()
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("failed to run command: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "error", err: errors.New("failed"), want: 1},
+		{name: "exec", err: &exectree.ExitError{Command: "sh", Tree: "tree", Err: exitErr}, want: 3},
+		{name: "wrapped exec", err: fmt.Errorf("failed: %w", &exectree.ExitError{Command: "sh", Tree: "tree", Err: exitErr}), want: 3},
+		// Commands grove runs for itself, such as git, do not determine its exit status
+		{name: "other command", err: fmt.Errorf("'git stash push' failed: %w", exitErr), want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := exitCode(tt.err)
+			if got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+/*
+config manages grove's configuration files, which hold user-defined defaults for grove's flags.
+
+Configuration is stored in git's config file format, as '<command>.<flag>' keys - for example, the following sets the
+default of 'grove init --depth':
+
+	[init]
+		depth = 1
+
+The keys of subcommands hold the full path of the command, as '<command>.<subcommand>.<flag>', which git's format
+stores as a subsection - for example, the following sets the default of 'grove remote add --fetch':
+
+	[remote "add"]
+		fetch = true
+
+Settings in a grove's own configuration file take precedence over the global configuration file.
+
+Values may refer to environment variables as '${NAME}', which are expanded when the configuration is read - so that,
+for example, 'identity-file = ${HOME}/.ssh/id_ed25519' suits machines with different home directories
+*/
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	format "github.com/go-git/go-git/v6/plumbing/format/config"
+)
+
+const (
+	// GroveDir is the directory at the root of a grove which holds its configuration file
+	GroveDir = ".grove"
+
+	// FileName is the name of grove's configuration files
+	FileName = "config"
+)
+
+// envVarPattern matches references to environment variables within values, such as '${HOME}'. Only the braced form
+// is expanded, so that values may otherwise contain '$'
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Expand replaces each reference to an environment variable in value, such as '${HOME}', with the variable's value.
+// Unset variables expand to the empty string, as in the shell
+func Expand(value string) string {
+	return envVarPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		return os.Getenv(envVarPattern.FindStringSubmatch(ref)[1])
+	})
+}
+
+// explicitFlags are the flags confirming destructive operations - such as 'grove prune --yes', or 'grove reset --force'
+// skipping its confirmation - which are only honored when given on the command line, so that a setting left in a
+// shell profile or configuration file can't remove or discard anything unasked
+var explicitFlags = []string{"yes", "force", "force-with-lease"}
+
+// ExplicitOnly reports whether the named flag must be given on the command line, so may not be defaulted by the
+// environment or a configuration file
+func ExplicitOnly(flag string) bool {
+	return slices.Contains(explicitFlags, flag)
+}
+
+// Scope identifies which configuration file a setting belongs to
+type Scope string
+
+const (
+	// ScopeGlobal settings apply to every grove
+	ScopeGlobal Scope = "global"
+	// ScopeGrove settings apply to a single grove, and take precedence over ScopeGlobal settings
+	ScopeGrove Scope = "grove"
+)
+
+// GlobalPath returns the path to the global configuration file, within the user's configuration directory
+func GlobalPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine user configuration directory: %w", err)
+	}
+	return filepath.Join(configDir, "grove", FileName), nil
+}
+
+// GrovePath returns the path to the configuration file of the grove rooted at root
+func GrovePath(root string) string {
+	return filepath.Join(root, GroveDir, FileName)
+}
+
+// File is a single configuration file
+type File struct {
+	// Path is the location of the file
+	Path string
+	// Scope is the scope the file's settings apply to
+	Scope Scope
+
+	config *format.Config
+}
+
+// Load reads the configuration file at path. A missing file is treated as an empty one
+func Load(path string, scope Scope) (*File, error) {
+	f := &File{
+		Path:   path,
+		Scope:  scope,
+		config: format.New(),
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return f, nil
+		}
+		return nil, fmt.Errorf("failed to read configuration file %q: %w", path, err)
+	}
+	err = format.NewDecoder(bytes.NewReader(content)).Decode(f.config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse configuration file %q: %w", path, err)
+	}
+	return f, nil
+}
+
+// Get returns the value of the given key, and whether it is set
+func (f *File) Get(key string) (string, bool, error) {
+	section, subsection, name, err := ParseKey(key)
+	if err != nil {
+		return "", false, err
+	}
+	options, ok := f.options(section, subsection)
+	if !ok || !options.Has(name) {
+		return "", false, nil
+	}
+	return options.Get(name), true, nil
+}
+
+// Set sets the given key to value
+func (f *File) Set(key, value string) error {
+	section, subsection, name, err := ParseKey(key)
+	if err != nil {
+		return err
+	}
+	if subsection == "" {
+		f.config.Section(section).SetOption(name, value)
+		return nil
+	}
+	f.config.Section(section).Subsection(subsection).SetOption(name, value)
+	return nil
+}
+
+// Unset removes the given key, reporting whether it was set
+func (f *File) Unset(key string) (bool, error) {
+	section, subsection, name, err := ParseKey(key)
+	if err != nil {
+		return false, err
+	}
+	options, ok := f.options(section, subsection)
+	if !ok || !options.Has(name) {
+		return false, nil
+	}
+	if subsection == "" {
+		f.config.Section(section).RemoveOption(name)
+		return true, nil
+	}
+	f.config.Section(section).Subsection(subsection).RemoveOption(name)
+	return true, nil
+}
+
+// options returns the options of the given section, or of its subsection if subsection is not empty, and whether it
+// exists
+func (f *File) options(section, subsection string) (format.Options, bool) {
+	if !f.config.HasSection(section) {
+		return nil, false
+	}
+	s := f.config.Section(section)
+	if subsection == "" {
+		return s.Options, true
+	}
+	if !s.HasSubsection(subsection) {
+		return nil, false
+	}
+	return s.Subsection(subsection).Options, true
+}
+
+// Setting is a single key and its value
+type Setting struct {
+	Key   string
+	Value string
+	Scope Scope
+}
+
+// Settings lists every key set in the file, sorted by key
+func (f *File) Settings() []Setting {
+	settings := []Setting{}
+	for _, section := range f.config.Sections {
+		for _, option := range section.Options {
+			settings = append(settings, Setting{
+				Key:   strings.ToLower(section.Name + "." + option.Key),
+				Value: option.Value,
+				Scope: f.Scope,
+			})
+		}
+		for _, subsection := range section.Subsections {
+			for _, option := range subsection.Options {
+				settings = append(settings, Setting{
+					Key:   strings.ToLower(section.Name) + "." + subsection.Name + "." + strings.ToLower(option.Key),
+					Value: option.Value,
+					Scope: f.Scope,
+				})
+			}
+		}
+	}
+	sort.SliceStable(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings
+}
+
+// Save writes the file to disk, creating its parent directory if necessary
+func (f *File) Save() error {
+	buf := &bytes.Buffer{}
+	err := format.NewEncoder(buf).Encode(f.config)
+	if err != nil {
+		return fmt.Errorf("failed to encode configuration: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(f.Path), 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", filepath.Dir(f.Path), err)
+	}
+	err = os.WriteFile(f.Path, buf.Bytes(), 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write configuration file %q: %w", f.Path, err)
+	}
+	return nil
+}
+
+// ParseKey splits the given '<section>.<name>' or '<section>.<subsection>.<name>' key into its section, subsection,
+// and name. As in git, the subsection is everything between the first and last '.', and is "" if the key has none
+func ParseKey(key string) (string, string, string, error) {
+	section, rest, ok := strings.Cut(key, ".")
+	subsection := ""
+	name := rest
+	i := strings.LastIndex(rest, ".")
+	if i >= 0 {
+		subsection, name = rest[:i], rest[i+1:]
+	}
+	if !ok || section == "" || name == "" || (i >= 0 && subsection == "") {
+		return "", "", "", fmt.Errorf("invalid key %q: expected the form '<command>.<flag>' or '<command>.<subcommand>.<flag>'", key)
+	}
+	return strings.ToLower(section), subsection, strings.ToLower(name), nil
+}
+
+// Config is the combined configuration of every applicable File, in order of increasing precedence
+type Config struct {
+	Files []*File
+}
+
+// LoadAll reads the global configuration file, along with the configuration file of the grove rooted at root, if
+// root is not empty
+func LoadAll(root string) (*Config, error) {
+	globalPath, err := GlobalPath()
+	if err != nil {
+		return nil, err
+	}
+	global, err := Load(globalPath, ScopeGlobal)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &Config{
+		Files: []*File{global},
+	}
+
+	if root != "" {
+		grove, err := Load(GrovePath(root), ScopeGrove)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Files = append(cfg.Files, grove)
+	}
+	return cfg, nil
+}
+
+// Get returns the value of the given key from the file with the highest precedence which sets it, with environment
+// variables expanded, and whether any did
+func (c *Config) Get(key string) (string, bool, error) {
+	for i := len(c.Files) - 1; i >= 0; i-- {
+		value, ok, err := c.Files[i].Get(key)
+		if err != nil || ok {
+			return Expand(value), ok, err
+		}
+	}
+	return "", false, nil
+}
+
+// Settings lists the effective value of every key set in any file, with environment variables expanded, sorted by key
+func (c *Config) Settings() []Setting {
+	effective := map[string]Setting{}
+	for _, file := range c.Files {
+		for _, setting := range file.Settings() {
+			setting.Value = Expand(setting.Value)
+			effective[setting.Key] = setting
+		}
+	}
+
+	settings := make([]Setting, 0, len(effective))
+	for _, setting := range effective {
+		settings = append(settings, setting)
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings
+}
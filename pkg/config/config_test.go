@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key            string
+		wantSection    string
+		wantSubsection string
+		wantName       string
+		wantErr        bool
+	}{
+		{key: "init.depth", wantSection: "init", wantName: "depth"},
+		{key: "Init.Depth", wantSection: "init", wantName: "depth"},
+		{key: "remote.add.fetch", wantSection: "remote", wantSubsection: "add", wantName: "fetch"},
+		{key: "a.b.c.d", wantSection: "a", wantSubsection: "b.c", wantName: "d"},
+		{key: "init", wantErr: true},
+		{key: ".depth", wantErr: true},
+		{key: "init.", wantErr: true},
+		{key: "remote..fetch", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			section, subsection, name, err := ParseKey(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseKey(%q) = %q, %q, %q, want an error", tt.key, section, subsection, name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseKey(%q) returned error: %v", tt.key, err)
+			}
+			if section != tt.wantSection || subsection != tt.wantSubsection || name != tt.wantName {
+				t.Errorf("ParseKey(%q) = %q, %q, %q, want %q, %q, %q", tt.key, section, subsection, name, tt.wantSection, tt.wantSubsection, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestFileSubcommandKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	f, err := Load(path, ScopeGlobal)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	settings := map[string]string{
+		"add.fetch":        "false",
+		"remote.add.fetch": "true",
+		"remote.verbose":   "true",
+	}
+	for key, value := range settings {
+		err := f.Set(key, value)
+		if err != nil {
+			t.Fatalf("Set(%q) returned error: %v", key, err)
+		}
+	}
+	err = f.Save()
+	if err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %q: %v", path, err)
+	}
+	if !strings.Contains(string(content), `[remote "add"]`) {
+		t.Errorf("saved configuration has no subsection for 'remote add':\n%s", content)
+	}
+
+	f, err = Load(path, ScopeGlobal)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	for key, want := range settings {
+		got, ok, err := f.Get(key)
+		if err != nil || !ok || got != want {
+			t.Errorf("Get(%q) = %q, %v, %v, want %q", key, got, ok, err, want)
+		}
+	}
+	listed := map[string]string{}
+	for _, setting := range f.Settings() {
+		listed[setting.Key] = setting.Value
+	}
+	if len(listed) != len(settings) {
+		t.Errorf("Settings() = %v, want %v", listed, settings)
+	}
+	for key, want := range settings {
+		if listed[key] != want {
+			t.Errorf("Settings() listed %q = %q, want %q", key, listed[key], want)
+		}
+	}
+
+	unset, err := f.Unset("remote.add.fetch")
+	if err != nil || !unset {
+		t.Fatalf("Unset() = %v, %v, want true", unset, err)
+	}
+	_, ok, _ := f.Get("remote.add.fetch")
+	if ok {
+		t.Error("Get() found a key after it was unset")
+	}
+	got, _, _ := f.Get("add.fetch")
+	if got != "false" {
+		t.Errorf("Unset() of 'remote.add.fetch' changed 'add.fetch' to %q", got)
+	}
+}
@@ -0,0 +1,189 @@
+package local
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/filemode"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// ArchiveFormat is the file format of an archive written by Archive
+type ArchiveFormat string
+
+const (
+	// ArchiveFormatTar is an uncompressed tarball
+	ArchiveFormatTar ArchiveFormat = "tar"
+	// ArchiveFormatTarGz is a gzip-compressed tarball
+	ArchiveFormatTarGz ArchiveFormat = "tar.gz"
+	// ArchiveFormatZip is a zip file
+	ArchiveFormatZip ArchiveFormat = "zip"
+)
+
+// ParseArchiveFormat converts the given value into an ArchiveFormat. 'tgz' is accepted as an alias of ArchiveFormatTarGz
+func ParseArchiveFormat(value string) (ArchiveFormat, error) {
+	switch ArchiveFormat(value) {
+	case ArchiveFormatTar, ArchiveFormatTarGz, ArchiveFormatZip:
+		return ArchiveFormat(value), nil
+	case "tgz":
+		return ArchiveFormatTarGz, nil
+	}
+	return "", fmt.Errorf("invalid archive format %q: expected one of %q, %q, or %q", value, ArchiveFormatTar, ArchiveFormatTarGz, ArchiveFormatZip)
+}
+
+// ArchiveFormatOf infers the ArchiveFormat from the extension of the given file name, returning false if it has none
+// of the supported extensions
+func ArchiveFormatOf(name string) (ArchiveFormat, bool) {
+	switch {
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return ArchiveFormatTarGz, true
+	case strings.HasSuffix(name, ".tar"):
+		return ArchiveFormatTar, true
+	case strings.HasSuffix(name, ".zip"):
+		return ArchiveFormatZip, true
+	}
+	return "", false
+}
+
+// archiveWriter adds the files of a commit to an archive
+type archiveWriter interface {
+	// add adds the file with the given name, mode, and contents to the archive. Symbolic links are added with their
+	// target as contents
+	add(name string, mode filemode.FileMode, size int64, contents io.Reader) error
+	// Close finishes writing the archive
+	Close() error
+}
+
+// Archive writes every file in the given commit's tree to w as an archive of the given format, without any history.
+// Each file's path within the archive is prefixed with prefix, if given, and stamped with the commit's time
+func (r *Repository) Archive(w io.Writer, commit plumbing.Hash, format ArchiveFormat, prefix string) error {
+	c, err := r.repo.CommitObject(commit)
+	if err != nil {
+		return fmt.Errorf("failed to read commit %q: %w", commit, err)
+	}
+	tree, err := c.Tree()
+	if err != nil {
+		return fmt.Errorf("failed to read tree of commit %q: %w", commit, err)
+	}
+
+	var archive archiveWriter
+	switch format {
+	case ArchiveFormatTar:
+		archive = &tarArchive{w: tar.NewWriter(w), modTime: c.Committer.When}
+	case ArchiveFormatTarGz:
+		compressed := gzip.NewWriter(w)
+		archive = &tarArchive{w: tar.NewWriter(compressed), modTime: c.Committer.When, compressed: compressed}
+	case ArchiveFormatZip:
+		archive = &zipArchive{w: zip.NewWriter(w), modTime: c.Committer.When}
+	default:
+		return fmt.Errorf("unsupported archive format %q", format)
+	}
+
+	// Submodules are recorded as commits rather than files, so are omitted - as with 'git archive'
+	err = tree.Files().ForEach(func(file *object.File) error {
+		contents, err := file.Reader()
+		if err != nil {
+			return fmt.Errorf("failed to read %q: %w", file.Name, err)
+		}
+		defer contents.Close()
+
+		err = archive.add(path.Join(prefix, file.Name), file.Mode, file.Size, contents)
+		if err != nil {
+			return fmt.Errorf("failed to archive %q: %w", file.Name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// tarArchive writes a tarball, optionally compressed
+type tarArchive struct {
+	w          *tar.Writer
+	modTime    time.Time
+	compressed io.WriteCloser
+}
+
+func (a *tarArchive) add(name string, mode filemode.FileMode, size int64, contents io.Reader) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    size,
+		ModTime: a.modTime,
+	}
+	switch mode {
+	case filemode.Executable:
+		header.Mode = 0o755
+	case filemode.Symlink:
+		target, err := io.ReadAll(contents)
+		if err != nil {
+			return err
+		}
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = string(target)
+		header.Mode = 0o777
+		header.Size = 0
+		return a.w.WriteHeader(header)
+	}
+
+	err := a.w.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(a.w, contents)
+	return err
+}
+
+func (a *tarArchive) Close() error {
+	err := a.w.Close()
+	if err != nil {
+		return err
+	}
+	if a.compressed != nil {
+		return a.compressed.Close()
+	}
+	return nil
+}
+
+// zipArchive writes a zip file
+type zipArchive struct {
+	w       *zip.Writer
+	modTime time.Time
+}
+
+func (a *zipArchive) add(name string, mode filemode.FileMode, _ int64, contents io.Reader) error {
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: a.modTime,
+	}
+	switch mode {
+	case filemode.Executable:
+		header.SetMode(0o755)
+	case filemode.Symlink:
+		header.SetMode(os.ModeSymlink | 0o777)
+	default:
+		header.SetMode(0o644)
+	}
+
+	w, err := a.w.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, contents)
+	return err
+}
+
+func (a *zipArchive) Close() error {
+	return a.w.Close()
+}
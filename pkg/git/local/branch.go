@@ -0,0 +1,193 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// RenameBranch renames the local branch oldName to newName, carrying over its tracking configuration.
+//
+// Any worktree which has oldName checked out is updated to check out newName instead. An error is returned if
+// oldName does not exist, or if newName already exists
+func (r *Repository) RenameBranch(oldName, newName string) error {
+	oldRef := plumbing.NewBranchReferenceName(oldName)
+	newRef := plumbing.NewBranchReferenceName(newName)
+
+	ref, err := r.repo.Reference(oldRef, false)
+	if err != nil {
+		return fmt.Errorf("failed to look up branch %q: %w", oldName, err)
+	}
+
+	_, err = r.repo.Reference(newRef, false)
+	if err == nil {
+		return fmt.Errorf("branch %q already exists", newName)
+	}
+	if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return fmt.Errorf("failed to look up branch %q: %w", newName, err)
+	}
+
+	worktrees, err := r.Worktrees()
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	err = r.repo.Storer.SetReference(plumbing.NewHashReference(newRef, ref.Hash()))
+	if err != nil {
+		return fmt.Errorf("failed to create branch %q: %w", newName, err)
+	}
+
+	// Point each worktree with the old branch checked out at the new branch before the old branch is removed
+	for _, worktree := range worktrees {
+		if worktree.Branch != oldName {
+			continue
+		}
+		headPath := filepath.Join(worktree.GitDir, "HEAD")
+		err = os.WriteFile(headPath, []byte(fmt.Sprintf("%s %s\n", GitHeadPrefix, newRef)), 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to update HEAD of worktree %q: %w", worktree.Path, err)
+		}
+	}
+
+	err = r.repo.Storer.RemoveReference(oldRef)
+	if err != nil {
+		return fmt.Errorf("failed to remove branch %q: %w", oldName, err)
+	}
+
+	// Carry over the branch's tracking configuration, if any
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+	branchConfig, err := r.repo.Branch(oldName)
+	if err != nil {
+		if errors.Is(err, git.ErrBranchNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to read configuration of branch %q: %w", oldName, err)
+	}
+	err = r.repo.DeleteBranch(oldName)
+	if err != nil {
+		return fmt.Errorf("failed to remove configuration of branch %q: %w", oldName, err)
+	}
+	err = r.repo.CreateBranch(&config.Branch{
+		Name:        newName,
+		Remote:      branchConfig.Remote,
+		Merge:       branchConfig.Merge,
+		Rebase:      branchConfig.Rebase,
+		Description: branchConfig.Description,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write configuration of branch %q: %w", newName, err)
+	}
+
+	return nil
+}
+
+// LocalBranches lists the short names of the repository's local branches
+func (r *Repository) LocalBranches() ([]string, error) {
+	refs, err := r.repo.Branches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	branches := []string{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		branches = append(branches, ref.Name().Short())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	return branches, nil
+}
+
+// RemoteBranch describes a branch of a remote repository, as of the last fetch
+type RemoteBranch struct {
+	// Remote is the name of the remote the branch belongs to
+	Remote string
+	// Name is the short name of the branch on the remote, ie: 'main' rather than 'origin/main'
+	Name string
+	// Head is the commit the branch pointed to when last fetched
+	Head plumbing.Hash
+}
+
+// RemoteBranches lists the remote-tracking branches of the given remote
+func (r *Repository) RemoteBranches(remote string) ([]RemoteBranch, error) {
+	refs, err := r.repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references: %w", err)
+	}
+
+	prefix := fmt.Sprintf("refs/remotes/%s/", remote)
+	branches := []RemoteBranch{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().String()
+		if !ref.Name().IsRemote() || !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		// Skip symbolic refs, such as refs/remotes/origin/HEAD
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		branches = append(branches, RemoteBranch{
+			Remote: remote,
+			Name:   strings.TrimPrefix(name, prefix),
+			Head:   ref.Hash(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches of remote %q: %w", remote, err)
+	}
+	return branches, nil
+}
+
+// TrackingBranch finds the remote-tracking branch a new local branch should start at and track when branch is checked
+// out, as 'git worktree add' does: either branch names a remote-tracking branch itself, such as 'origin/feature', or
+// one of the repository's remotes - preferring 'origin' - has a branch of the same name. The local branch is named after
+// the remote's branch, as given by the Name of the returned RemoteBranch.
+//
+// false is returned if a local branch named branch already exists, or no remote-tracking branch matches it
+func (r *Repository) TrackingBranch(branch string) (RemoteBranch, bool, error) {
+	_, err := r.repo.Reference(plumbing.NewBranchReferenceName(branch), false)
+	if err == nil {
+		return RemoteBranch{}, false, nil
+	}
+	if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return RemoteBranch{}, false, fmt.Errorf("failed to look up branch %q: %w", branch, err)
+	}
+
+	remotes, err := r.Remotes()
+	if err != nil {
+		return RemoteBranch{}, false, err
+	}
+	remotes = preferOrigin(remotes)
+
+	candidates := []RemoteBranch{}
+	for _, remote := range remotes {
+		name, found := strings.CutPrefix(branch, remote.Name+"/")
+		if found {
+			candidates = append(candidates, RemoteBranch{Remote: remote.Name, Name: name})
+		}
+	}
+	for _, remote := range remotes {
+		candidates = append(candidates, RemoteBranch{Remote: remote.Name, Name: branch})
+	}
+
+	for _, candidate := range candidates {
+		ref, err := r.repo.Reference(plumbing.NewRemoteReferenceName(candidate.Remote, candidate.Name), true)
+		if err != nil {
+			if errors.Is(err, plumbing.ErrReferenceNotFound) {
+				continue
+			}
+			return RemoteBranch{}, false, fmt.Errorf("failed to look up branch %q of remote %q: %w", candidate.Name, candidate.Remote, err)
+		}
+		candidate.Head = ref.Hash()
+		return candidate, true, nil
+	}
+	return RemoteBranch{}, false, nil
+}
@@ -0,0 +1,62 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// setReference points the reference of the given name at hash
+func setReference(t *testing.T, r *Repository, name plumbing.ReferenceName, hash plumbing.Hash) {
+	t.Helper()
+
+	err := r.repo.Storer.SetReference(plumbing.NewHashReference(name, hash))
+	if err != nil {
+		t.Fatalf("failed to set reference %q: %v", name, err)
+	}
+}
+
+func TestTrackingBranch(t *testing.T) {
+	repo, head := newTestRepository(t)
+	for _, remote := range []string{"upstream", "origin"} {
+		err := repo.AddRemote(remote, "https://example.com/"+remote+".git")
+		if err != nil {
+			t.Fatalf("failed to add remote %q: %v", remote, err)
+		}
+	}
+	setReference(t, repo, plumbing.NewRemoteReferenceName("origin", "shared"), head)
+	setReference(t, repo, plumbing.NewRemoteReferenceName("upstream", "shared"), head)
+	setReference(t, repo, plumbing.NewRemoteReferenceName("upstream", "feature/foo"), head)
+	setReference(t, repo, plumbing.NewRemoteReferenceName("origin", "main"), head)
+
+	tests := []struct {
+		name      string
+		branch    string
+		want      RemoteBranch
+		wantFound bool
+	}{
+		{name: "prefers origin", branch: "shared", want: RemoteBranch{Remote: "origin", Name: "shared"}, wantFound: true},
+		{name: "other remote", branch: "feature/foo", want: RemoteBranch{Remote: "upstream", Name: "feature/foo"}, wantFound: true},
+		{name: "named by remote", branch: "upstream/shared", want: RemoteBranch{Remote: "upstream", Name: "shared"}, wantFound: true},
+		{name: "local branch exists", branch: "main", wantFound: false},
+		{name: "no remote branch", branch: "missing", wantFound: false},
+		{name: "unknown remote", branch: "fork/shared", wantFound: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found, err := repo.TrackingBranch(tt.branch)
+			if err != nil {
+				t.Fatalf("TrackingBranch(%q) returned error: %v", tt.branch, err)
+			}
+			if found != tt.wantFound {
+				t.Fatalf("TrackingBranch(%q) found = %v, want %v", tt.branch, found, tt.wantFound)
+			}
+			if !found {
+				return
+			}
+			if got.Remote != tt.want.Remote || got.Name != tt.want.Name || got.Head != head {
+				t.Errorf("TrackingBranch(%q) = %+v, want %s/%s at %s", tt.branch, got, tt.want.Remote, tt.want.Name, head)
+			}
+		})
+	}
+}
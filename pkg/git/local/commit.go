@@ -0,0 +1,50 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// ErrNothingToCommit is returned when a commit is attempted without any staged changes
+var ErrNothingToCommit = errors.New("nothing to commit")
+
+// Commit records the changes staged in the worktree rooted at path as a new commit with the given message, advancing
+// the worktree's checked out branch. If all is set, changes to tracked files are staged first, as with 'git commit -a'.
+// The commit is attributed to the identity returned by Signature.
+//
+// ErrNothingToCommit is returned if no changes are staged
+func (r *Repository) Commit(path, message string, all bool) (plumbing.Hash, error) {
+	if strings.TrimSpace(message) == "" {
+		return plumbing.ZeroHash, fmt.Errorf("commit message must not be empty")
+	}
+	signature, err := r.Signature()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	worktreeRepo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to open worktree %q: %w", path, err)
+	}
+	wt, err := worktreeRepo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to open worktree %q: %w", path, err)
+	}
+
+	hash, err := wt.Commit(message, &git.CommitOptions{
+		All:       all,
+		Author:    signature,
+		Committer: signature,
+	})
+	if err != nil {
+		if errors.Is(err, git.ErrEmptyCommit) {
+			return plumbing.ZeroHash, ErrNothingToCommit
+		}
+		return plumbing.ZeroHash, fmt.Errorf("failed to commit in worktree %q: %w", path, err)
+	}
+	return hash, nil
+}
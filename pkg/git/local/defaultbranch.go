@@ -0,0 +1,120 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// ErrNoDefaultBranch is returned when the repository's default branch cannot be determined
+var ErrNoDefaultBranch = errors.New("no default branch could be determined")
+
+// DefaultBranch determines the repository's default branch. As with git, the branch the remote's HEAD points to -
+// recorded by 'refs/remotes/<remote>/HEAD' - is preferred, with 'origin' taking precedence over any other remote.
+// Otherwise, the branch the shared HEAD points to is used: for bare repositories, this is the branch checked out
+// when cloned, and otherwise the branch checked out in the main worktree.
+//
+// ErrNoDefaultBranch is returned if HEAD is detached and no remote's HEAD is recorded
+func (r *Repository) DefaultBranch() (string, error) {
+	remotes, err := r.Remotes()
+	if err != nil {
+		return "", err
+	}
+	for _, remote := range preferOrigin(remotes) {
+		ref, err := r.repo.Storer.Reference(remoteHead(remote.Name))
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read %q: %w", remoteHead(remote.Name), err)
+		}
+		if ref.Type() != plumbing.SymbolicReference {
+			continue
+		}
+		branch, ok := strings.CutPrefix(ref.Target().String(), plumbing.NewRemoteReferenceName(remote.Name, "").String())
+		if ok && branch != "" {
+			return branch, nil
+		}
+	}
+
+	// The shared HEAD is read directly, as the repository may have been opened from a linked worktree with its own HEAD
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return "", err
+	}
+	head, err := readFirstLine(filepath.Join(commonDir, "HEAD"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	target, ok := strings.CutPrefix(head, GitHeadPrefix)
+	if !ok {
+		return "", fmt.Errorf("%w: HEAD is detached and no remote's HEAD is recorded", ErrNoDefaultBranch)
+	}
+	return plumbing.ReferenceName(strings.TrimSpace(target)).Short(), nil
+}
+
+// SetDefaultBranch records branch as the default branch of the given remote, by pointing 'refs/remotes/<remote>/HEAD'
+// to its remote-tracking branch - as 'git remote set-head' does. The remote-tracking branch must exist
+func (r *Repository) SetDefaultBranch(remote, branch string) error {
+	_, err := r.repo.Remote(remote)
+	if err != nil {
+		if errors.Is(err, git.ErrRemoteNotFound) {
+			return fmt.Errorf("remote %q does not exist", remote)
+		}
+		return fmt.Errorf("failed to read configuration of remote %q: %w", remote, err)
+	}
+
+	tracking := plumbing.NewRemoteReferenceName(remote, branch)
+	_, err = r.repo.Storer.Reference(tracking)
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return fmt.Errorf("branch %q does not exist on remote %q: fetch it first", branch, remote)
+		}
+		return fmt.Errorf("failed to read %q: %w", tracking, err)
+	}
+
+	err = r.repo.Storer.SetReference(plumbing.NewSymbolicReference(remoteHead(remote), tracking))
+	if err != nil {
+		return fmt.Errorf("failed to set default branch of remote %q to %q: %w", remote, branch, err)
+	}
+	return nil
+}
+
+// DefaultRemote returns the name of the remote the repository's default branch is recorded for: 'origin', if it
+// exists, otherwise the first remote by name. An error is returned if the repository has no remotes
+func (r *Repository) DefaultRemote() (string, error) {
+	remotes, err := r.Remotes()
+	if err != nil {
+		return "", err
+	}
+	if len(remotes) == 0 {
+		return "", fmt.Errorf("repository has no remotes")
+	}
+	return preferOrigin(remotes)[0].Name, nil
+}
+
+// remoteHead returns the name of the reference recording the default branch of the given remote
+func remoteHead(remote string) plumbing.ReferenceName {
+	return plumbing.NewRemoteReferenceName(remote, plumbing.HEAD.String())
+}
+
+// preferOrigin returns a copy of the given remotes, sorted by name, with the remote named 'origin' moved to the front
+func preferOrigin(remotes []Remote) []Remote {
+	sorted := slices.Clone(remotes)
+	slices.SortFunc(sorted, func(a, b Remote) int {
+		aOrigin, bOrigin := a.Name == git.DefaultRemoteName, b.Name == git.DefaultRemoteName
+		if aOrigin != bOrigin {
+			if aOrigin {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return sorted
+}
@@ -0,0 +1,45 @@
+package local
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPreferOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		remotes []string
+		want    []string
+	}{
+		{name: "none", remotes: []string{}, want: []string{}},
+		{name: "origin only", remotes: []string{"origin"}, want: []string{"origin"}},
+		{name: "sorted", remotes: []string{"fork", "upstream"}, want: []string{"fork", "upstream"}},
+		{name: "unsorted", remotes: []string{"upstream", "fork", "backup"}, want: []string{"backup", "fork", "upstream"}},
+		{name: "origin first", remotes: []string{"upstream", "origin", "fork"}, want: []string{"origin", "fork", "upstream"}},
+		{name: "origin last", remotes: []string{"fork", "upstream", "origin"}, want: []string{"origin", "fork", "upstream"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remotes := []Remote{}
+			for _, name := range tt.remotes {
+				remotes = append(remotes, Remote{Name: name})
+			}
+			original := slices.Clone(remotes)
+
+			got := []string{}
+			for _, remote := range preferOrigin(remotes) {
+				got = append(got, remote.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("preferOrigin(%v) = %v, want %v", tt.remotes, got, tt.want)
+			}
+			// The caller's remotes are left as they were
+			for i := range remotes {
+				if remotes[i].Name != original[i].Name {
+					t.Errorf("preferOrigin(%v) reordered its argument", tt.remotes)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package local
+
+import (
+	"io/fs"
+)
+
+// diskUsage returns the size of the file described by info, as the space allocated on disk cannot be determined on
+// this platform
+func diskUsage(info fs.FileInfo) int64 {
+	return info.Size()
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package local
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// diskUsage returns the number of bytes allocated on disk for the file described by info, which may exceed its size
+func diskUsage(info fs.FileInfo) int64 {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return info.Size()
+	}
+	// st_blocks is always counted in 512-byte units, regardless of the filesystem's block size
+	return int64(stat.Blocks) * 512
+}
@@ -0,0 +1,95 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v6"
+)
+
+// ErrAggressiveUnsupported is returned when an aggressive garbage collection is requested without the git CLI
+var ErrAggressiveUnsupported = errors.New("aggressive garbage collection requires the git CLI")
+
+// GarbageCollect compacts the repository's object store, which is shared by every worktree. Output from the
+// collection is written to out.
+//
+// When the git CLI is available, 'git gc' is run: it packs loose objects and removes unreachable ones, accounting for
+// the HEAD, index, and reflogs of every worktree. If aggressive is set, deltas are recomputed more thoroughly, which
+// is much slower.
+//
+// Otherwise, go-git repacks every object reachable from the repository's references into a single pack, leaving
+// loose objects in place. go-git only considers references, so this is refused while any worktree has a detached HEAD,
+// as the commits it points to could be lost. ErrAggressiveUnsupported is returned if aggressive is set
+func (r *Repository) GarbageCollect(aggressive bool, out io.Writer) error {
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return err
+	}
+
+	_, err = exec.LookPath("git")
+	if err == nil {
+		args := []string{"--git-dir", commonDir, "gc"}
+		if aggressive {
+			args = append(args, "--aggressive")
+		}
+		cmd := exec.Command("git", args...)
+		cmd.Stdout = out
+		cmd.Stderr = out
+		err = cmd.Run()
+		if err != nil {
+			return fmt.Errorf("failed to run 'git gc' in %q: %w", commonDir, err)
+		}
+		return nil
+	}
+
+	if aggressive {
+		return ErrAggressiveUnsupported
+	}
+	worktrees, err := r.Worktrees()
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	for _, worktree := range worktrees {
+		if worktree.Branch == "" {
+			return fmt.Errorf("cannot repack without the git CLI while worktree %q has a detached HEAD: check out a branch in it first", worktree.Path)
+		}
+	}
+	err = r.repo.RepackObjects(&git.RepackConfig{})
+	if err != nil {
+		return fmt.Errorf("failed to repack objects in %q: %w", commonDir, err)
+	}
+	return nil
+}
+
+// StoreSize measures the number of bytes allocated on disk for the repository's common git directory, which holds its
+// objects and references
+func (r *Repository) StoreSize() (int64, error) {
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return 0, err
+	}
+
+	size := int64(0)
+	err = filepath.WalkDir(commonDir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += diskUsage(info)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure size of %q: %w", commonDir, err)
+	}
+	return size, nil
+}
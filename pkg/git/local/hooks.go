@@ -0,0 +1,80 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/go-git/go-git/v6/config"
+)
+
+// PostCheckoutHook is run once a worktree has been created and its files checked out, as 'git worktree add' does
+const PostCheckoutHook = "post-checkout"
+
+// hooksDir is the directory within the common git directory holding hooks, unless core.hooksPath is configured
+const hooksDir = "hooks"
+
+// HooksDir returns the directory hooks are run from for the worktree rooted at path: core.hooksPath, if configured -
+// relative to the worktree, unless absolute - or otherwise the hooks/ directory of the common git directory
+func (r *Repository) HooksDir(path string) (string, error) {
+	cfg, err := r.repo.ConfigScoped(config.SystemScope)
+	if err != nil {
+		return "", fmt.Errorf("failed to read git configuration: %w", err)
+	}
+	hooksPath := cfg.Raw.Section("core").Option("hooksPath")
+	if hooksPath == "" {
+		commonDir, err := r.CommonDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(commonDir, hooksDir), nil
+	}
+
+	if rest, found := strings.CutPrefix(hooksPath, "~/"); found {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine home directory: %w", err)
+		}
+		hooksPath = filepath.Join(home, rest)
+	}
+	if !filepath.IsAbs(hooksPath) {
+		hooksPath = filepath.Join(path, hooksPath)
+	}
+	return hooksPath, nil
+}
+
+// RunHook runs the named hook with the given arguments from the root of the worktree at path, if the hook exists and is
+// executable. As with git, the hook's output is written to stderr. Whether the hook was run is returned; an error is
+// returned if it fails
+func (r *Repository) RunHook(path, name string, args ...string) (bool, error) {
+	dir, err := r.HooksDir(path)
+	if err != nil {
+		return false, err
+	}
+	hook := filepath.Join(dir, name)
+	info, err := os.Stat(hook)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check hook %q: %w", hook, err)
+	}
+	// Hooks which are not executable are ignored, as git does - such as the samples created alongside each repository
+	if info.IsDir() || (runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0) {
+		return false, nil
+	}
+
+	cmd := exec.Command(hook, args...)
+	cmd.Dir = path
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return true, fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,34 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// ErrNoIdentity is returned when an operation which creates commits is attempted without a configured identity
+var ErrNoIdentity = errors.New(`no identity configured: set one with 'git config --global user.name "Your Name"' and 'git config --global user.email "you@example.com"'`)
+
+// Signature returns the identity grove's commits are attributed to, timestamped with the current time. The identity
+// is read from user.name and user.email in the repository's configuration, falling back to the global and system
+// configuration, as git does.
+//
+// ErrNoIdentity is returned if either is unset
+func (r *Repository) Signature() (*object.Signature, error) {
+	cfg, err := r.repo.ConfigScoped(config.SystemScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git configuration: %w", err)
+	}
+	if cfg.User.Name == "" || cfg.User.Email == "" {
+		return nil, ErrNoIdentity
+	}
+
+	return &object.Signature{
+		Name:  cfg.User.Name,
+		Email: cfg.User.Email,
+		When:  time.Now(),
+	}, nil
+}
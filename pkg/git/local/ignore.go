@@ -0,0 +1,95 @@
+package local
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-billy/v6/osfs"
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/format/gitignore"
+)
+
+// openWorktree opens the worktree rooted at path, which may be a linked worktree, with every pattern git ignores files
+// by loaded into its Excludes, so that ignored files are neither reported as untracked nor removed when cleaning it.
+//
+// go-git only reads the worktree's .gitignore files, and info/exclude when the worktree holds the repository's .git/
+// directory. The patterns of the system and global excludes files, and the shared info/exclude, are read here too
+func openWorktree(path string) (*git.Repository, *git.Worktree, error) {
+	worktreeRepo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open worktree %q: %w", path, err)
+	}
+	wt, err := worktreeRepo.Worktree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open worktree %q: %w", path, err)
+	}
+
+	patterns, err := ignorePatterns(path, wt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read ignored files of worktree %q: %w", path, err)
+	}
+	wt.Excludes = patterns
+	return worktreeRepo, wt, nil
+}
+
+// ignorePatterns reads the patterns of ignored files for the worktree at path, in ascending order of priority: the
+// system, then global excludes files, the repository's info/exclude, and finally the worktree's .gitignore files
+func ignorePatterns(path string, wt *git.Worktree) ([]gitignore.Pattern, error) {
+	root := osfs.New("/")
+	patterns, err := gitignore.LoadSystemPatterns(root)
+	if err != nil {
+		return nil, err
+	}
+	global, err := gitignore.LoadGlobalPatterns(root)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	patterns = append(patterns, global...)
+
+	repo, err := NewRepository(path)
+	if err != nil {
+		return nil, err
+	}
+	commonDir, err := repo.CommonDir()
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := readPatterns(filepath.Join(commonDir, "info", "exclude"))
+	if err != nil {
+		return nil, err
+	}
+	patterns = append(patterns, excluded...)
+
+	ignored, err := gitignore.ReadPatterns(wt.Filesystem, nil)
+	if err != nil {
+		return nil, err
+	}
+	return append(patterns, ignored...), nil
+}
+
+// readPatterns reads the patterns in the ignore file at path, which may not exist
+func readPatterns(path string) ([]gitignore.Pattern, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	patterns := []gitignore.Pattern{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, nil))
+	}
+	return patterns, scanner.Err()
+}
@@ -0,0 +1,57 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/plumbing/storer"
+)
+
+// LogEntry describes a single commit in the history of a branch
+type LogEntry struct {
+	// Hash is the commit's full hash
+	Hash string `json:"hash"`
+	// Author is the name of the commit's author
+	Author string `json:"author"`
+	// Email is the email address of the commit's author
+	Email string `json:"email"`
+	// Date is the time the commit was authored
+	Date time.Time `json:"date"`
+	// Subject is the first line of the commit's message
+	Subject string `json:"subject"`
+}
+
+// Log lists the commits reachable from the commit from, most recently committed first, as with 'git log'. If limit
+// is positive, at most limit commits are listed
+func (r *Repository) Log(from plumbing.Hash, limit int) ([]LogEntry, error) {
+	commits, err := r.repo.Log(&git.LogOptions{From: from, Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history of %q: %w", from, err)
+	}
+	defer commits.Close()
+
+	entries := []LogEntry{}
+	err = commits.ForEach(func(commit *object.Commit) error {
+		if limit > 0 && len(entries) >= limit {
+			return storer.ErrStop
+		}
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		entries = append(entries, LogEntry{
+			Hash:    commit.Hash.String(),
+			Author:  commit.Author.Name,
+			Email:   commit.Author.Email,
+			Date:    commit.Author.When,
+			Subject: subject,
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, storer.ErrStop) {
+		return nil, fmt.Errorf("failed to read history of %q: %w", from, err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,108 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// ErrDiverged is returned when a branch and its upstream have each gained commits the other lacks, so that updating
+// the branch would require a merge commit
+var ErrDiverged = errors.New("branch has diverged from its upstream: updating it would require a merge commit")
+
+// IsAncestor reports whether the commit ancestor is reachable from - or equal to - the commit descendant
+func (r *Repository) IsAncestor(ancestor, descendant plumbing.Hash) (bool, error) {
+	ancestorCommit, err := r.repo.CommitObject(ancestor)
+	if err != nil {
+		return false, fmt.Errorf("failed to read commit %q: %w", ancestor, err)
+	}
+	descendantCommit, err := r.repo.CommitObject(descendant)
+	if err != nil {
+		return false, fmt.Errorf("failed to read commit %q: %w", descendant, err)
+	}
+	return ancestorCommit.IsAncestor(descendantCommit)
+}
+
+// FastForward advances the branch checked out in the worktree rooted at path to the commit target, updating the
+// worktree's files to match. ErrDiverged is returned if target does not descend from the worktree's HEAD.
+//
+// An error is returned if the worktree has uncommitted changes to tracked files, as they would be overwritten
+func (r *Repository) FastForward(path string, target plumbing.Hash) error {
+	worktreeRepo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return fmt.Errorf("failed to open worktree %q: %w", path, err)
+	}
+	head, err := worktreeRepo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to resolve HEAD of worktree %q: %w", path, err)
+	}
+
+	ok, err := r.IsAncestor(head.Hash(), target)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrDiverged
+	}
+
+	wt, err := worktreeRepo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree %q: %w", path, err)
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return fmt.Errorf("failed to determine status of worktree %q: %w", path, err)
+	}
+	for file, fileStatus := range status {
+		if !isUntracked(fileStatus) {
+			return fmt.Errorf("worktree %q has uncommitted changes to %q: commit or stash them first", path, file)
+		}
+	}
+
+	// Resetting moves the checked out branch along with HEAD
+	err = wt.Reset(&git.ResetOptions{
+		Commit: target,
+		Mode:   git.HardReset,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update worktree %q to %q: %w", path, target, err)
+	}
+	return nil
+}
+
+// Rebase replays the commits of the branch checked out in the worktree rooted at path on top of the commit onto.
+//
+// go-git cannot rebase, so the git CLI is used. If the rebase stops due to a conflict, it is aborted so that the
+// worktree is left as it was
+func (r *Repository) Rebase(path string, onto plumbing.Hash) error {
+	// Rebasing rewrites commits, so fail early with a helpful error rather than partway through
+	signature, err := r.Signature()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", "rebase", onto.String())
+	cmd.Env = append(os.Environ(),
+		"GIT_COMMITTER_NAME="+signature.Name,
+		"GIT_COMMITTER_EMAIL="+signature.Email,
+	)
+	cmd.Dir = path
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err == nil {
+		return nil
+	}
+
+	abort := exec.Command("git", "rebase", "--abort")
+	abort.Dir = path
+	abortErr := abort.Run()
+	if abortErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to abort rebase in worktree %q: %v\n", path, abortErr)
+	}
+	return fmt.Errorf("failed to rebase worktree %q onto %q: %w", path, onto, err)
+}
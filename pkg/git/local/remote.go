@@ -0,0 +1,319 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/cache"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/go-git/go-git/v6/storage/filesystem"
+)
+
+// ErrNoUpstream is returned when a branch has no upstream branch configured
+var ErrNoUpstream = errors.New("no upstream branch configured")
+
+// Upstream describes the branch of a remote which a local branch tracks
+type Upstream struct {
+	// Remote is the name of the remote the upstream branch belongs to
+	Remote string
+	// Merge is the full name of the upstream branch on the remote
+	Merge plumbing.ReferenceName
+	// TrackingRef is the full name of the local remote-tracking reference which mirrors Merge
+	TrackingRef plumbing.ReferenceName
+}
+
+// Upstream returns the upstream branch tracked by the given local branch. ErrNoUpstream is returned if the branch
+// has no upstream configured
+func (r *Repository) Upstream(branch string) (Upstream, error) {
+	branchConfig, err := r.repo.Branch(branch)
+	if err != nil {
+		if errors.Is(err, git.ErrBranchNotFound) {
+			return Upstream{}, fmt.Errorf("branch %q: %w", branch, ErrNoUpstream)
+		}
+		return Upstream{}, fmt.Errorf("failed to read configuration of branch %q: %w", branch, err)
+	}
+	if branchConfig.Remote == "" || branchConfig.Merge == "" {
+		return Upstream{}, fmt.Errorf("branch %q: %w", branch, ErrNoUpstream)
+	}
+
+	remoteConfig, err := r.repo.Remote(branchConfig.Remote)
+	if err != nil {
+		return Upstream{}, fmt.Errorf("failed to read configuration of remote %q: %w", branchConfig.Remote, err)
+	}
+
+	// The remote's fetch refspecs determine which local reference mirrors the upstream branch
+	for _, refSpec := range remoteConfig.Config().Fetch {
+		if refSpec.Match(branchConfig.Merge) {
+			return Upstream{
+				Remote:      branchConfig.Remote,
+				Merge:       branchConfig.Merge,
+				TrackingRef: refSpec.Dst(branchConfig.Merge),
+			}, nil
+		}
+	}
+	return Upstream{}, fmt.Errorf("upstream %q of branch %q is not fetched by remote %q", branchConfig.Merge, branch, branchConfig.Remote)
+}
+
+// RemoteURL returns the URL of the remote with the given name
+func (r *Repository) RemoteURL(name string) (string, error) {
+	remote, err := r.repo.Remote(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to read configuration of remote %q: %w", name, err)
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", fmt.Errorf("remote %q has no URL configured", name)
+	}
+	return urls[0], nil
+}
+
+// AddRemote adds a remote with the given name and URL to the repository, fetching every branch of the remote into
+// remote-tracking references beneath refs/remotes/<name>/
+func (r *Repository) AddRemote(name, url string) error {
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+
+	_, err := r.repo.CreateRemote(&config.RemoteConfig{
+		Name: name,
+		URLs: []string{url},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add remote %q: %w", name, err)
+	}
+	return nil
+}
+
+// SetRemoteURL replaces the URL of the remote with the given name, which it is both fetched from and pushed to. Any
+// further URLs configured for the remote are left in place, as with 'git remote set-url'
+func (r *Repository) SetRemoteURL(name, url string) error {
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read repository configuration: %w", err)
+	}
+	remoteConfig, found := cfg.Remotes[name]
+	if !found {
+		return fmt.Errorf("failed to set URL of remote %q: %w", name, git.ErrRemoteNotFound)
+	}
+	if len(remoteConfig.URLs) == 0 {
+		remoteConfig.URLs = []string{url}
+	} else {
+		remoteConfig.URLs[0] = url
+	}
+	err = r.repo.SetConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to set URL of remote %q: %w", name, err)
+	}
+	return nil
+}
+
+// Remote describes a remote configured in the repository
+type Remote struct {
+	// Name is the name of the remote
+	Name string
+	// URLs are the URLs the remote is fetched from, the first of which is also pushed to
+	URLs []string
+}
+
+// Remotes lists every remote configured in the repository, sorted by name
+func (r *Repository) Remotes() ([]Remote, error) {
+	// The config is rewritten in place, so is only read once no one is writing it
+	metadataMu.Lock()
+	cfg, err := r.repo.Config()
+	metadataMu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repository configuration: %w", err)
+	}
+
+	remotes := make([]Remote, 0, len(cfg.Remotes))
+	for name, remoteConfig := range cfg.Remotes {
+		remotes = append(remotes, Remote{Name: name, URLs: remoteConfig.URLs})
+	}
+	slices.SortFunc(remotes, func(a, b Remote) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return remotes, nil
+}
+
+// RemoveRemote removes the remote with the given name from the repository, along with its remote-tracking
+// references. Branches tracking the remote are left in place, but no longer have an upstream
+func (r *Repository) RemoveRemote(name string) error {
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+
+	err := r.repo.DeleteRemote(name)
+	if err != nil {
+		return fmt.Errorf("failed to remove remote %q: %w", name, err)
+	}
+
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read repository configuration: %w", err)
+	}
+	changed := false
+	for _, branchConfig := range cfg.Branches {
+		if branchConfig.Remote == name {
+			branchConfig.Remote = ""
+			branchConfig.Merge = ""
+			changed = true
+		}
+	}
+	if changed {
+		err = r.repo.SetConfig(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to remove upstreams of branches tracking remote %q: %w", name, err)
+		}
+	}
+
+	refs, err := r.repo.References()
+	if err != nil {
+		return fmt.Errorf("failed to list references: %w", err)
+	}
+	prefix := "refs/remotes/" + name + "/"
+	stale := []plumbing.ReferenceName{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if strings.HasPrefix(ref.Name().String(), prefix) {
+			stale = append(stale, ref.Name())
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list references: %w", err)
+	}
+	for _, ref := range stale {
+		err = r.repo.Storer.RemoveReference(ref)
+		if err != nil {
+			return fmt.Errorf("failed to remove reference %q: %w", ref, err)
+		}
+	}
+	return nil
+}
+
+// Fetch updates the remote-tracking references of the remote with the given name, authenticating with auth. Fetching
+// stops if ctx is cancelled. Progress information sent by the remote is written to progress, if not nil.
+//
+// If refSpecs are given, they are fetched instead of the remote's configured refspecs, allowing refs such as
+// 'refs/pull/*/head' which the remote doesn't advertise as branches to be fetched
+func (r *Repository) Fetch(ctx context.Context, remote string, refSpecs []string, auth transport.AuthMethod, progress io.Writer) error {
+	specs := []config.RefSpec{}
+	for _, refSpec := range refSpecs {
+		spec := config.RefSpec(refSpec)
+		err := spec.Validate()
+		if err != nil {
+			return fmt.Errorf("invalid refspec %q: %w", refSpec, err)
+		}
+		specs = append(specs, spec)
+	}
+
+	err := r.repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: remote,
+		RefSpecs:   specs,
+		Auth:       auth,
+		Progress:   progress,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to fetch from remote %q: %w", remote, err)
+	}
+	return nil
+}
+
+// Reopen opens a separate instance of the repository, backed by the same storage on disk but sharing nothing in memory
+// with r, so that it can be used concurrently with r - for example, to fetch from several remotes at once. The returned
+// instance has no worktree
+func (r *Repository) Reopen() (*Repository, error) {
+	storage, ok := r.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, fmt.Errorf("repository %q is not stored on disk", r.initPath)
+	}
+	repo, err := git.Open(filesystem.NewStorage(storage.Filesystem(), cache.NewObjectLRUDefault()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reopen repository %q: %w", r.initPath, err)
+	}
+	return &Repository{
+		initPath: r.initPath,
+		repo:     repo,
+	}, nil
+}
+
+// ResolveReference returns the commit the given reference currently points to
+func (r *Repository) ResolveReference(name plumbing.ReferenceName) (plumbing.Hash, error) {
+	ref, err := r.repo.Reference(name, true)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to resolve %q: %w", name, err)
+	}
+	return ref.Hash(), nil
+}
+
+// ErrAlreadyUpToDate is returned when a push has no changes to send to the remote
+var ErrAlreadyUpToDate = errors.New("already up to date")
+
+// PushOptions configures how Push updates a remote branch
+type PushOptions struct {
+	// Auth authenticates with the remote
+	Auth transport.AuthMethod
+	// Progress receives the human-readable progress information sent by the remote, if not nil
+	Progress io.Writer
+	// ForceWithLease allows the remote branch to be overwritten with a commit which does not descend from it, as
+	// long as the remote branch still matches the local remote-tracking reference mirroring it
+	ForceWithLease bool
+}
+
+// Push updates the branch merge on the given remote to match the local branch. ErrAlreadyUpToDate is returned if
+// the remote branch already matches
+func (r *Repository) Push(remote, branch string, merge plumbing.ReferenceName, opts PushOptions) error {
+	refSpec := config.RefSpec(fmt.Sprintf("%s:%s", plumbing.NewBranchReferenceName(branch), merge))
+	pushOpts := &git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{refSpec},
+		Auth:       opts.Auth,
+		Progress:   opts.Progress,
+	}
+	if opts.ForceWithLease {
+		pushOpts.RefSpecs = []config.RefSpec{"+" + refSpec}
+		// Without a specific ref or hash, every pushed ref is protected by its remote-tracking reference
+		pushOpts.ForceWithLease = &git.ForceWithLease{}
+	}
+
+	err := r.repo.Push(pushOpts)
+	if err != nil {
+		if errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return ErrAlreadyUpToDate
+		}
+		return fmt.Errorf("failed to push branch %q to %q on remote %q: %w", branch, merge, remote, err)
+	}
+	return nil
+}
+
+// SetUpstream configures the given local branch to track the branch merge on the given remote
+func (r *Repository) SetUpstream(branch, remote string, merge plumbing.ReferenceName) error {
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read repository configuration: %w", err)
+	}
+
+	branchConfig, ok := cfg.Branches[branch]
+	if !ok {
+		branchConfig = &config.Branch{Name: branch}
+		cfg.Branches[branch] = branchConfig
+	}
+	branchConfig.Remote = remote
+	branchConfig.Merge = merge
+
+	err = r.repo.SetConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to configure upstream of branch %q: %w", branch, err)
+	}
+	return nil
+}
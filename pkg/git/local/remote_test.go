@@ -0,0 +1,109 @@
+package local
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+func TestUpstream(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	err := repo.AddRemote("origin", "https://example.com/origin.git")
+	if err != nil {
+		t.Fatalf("failed to add remote: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		branch string
+		// remote and merge are the upstream configured for the branch, if remote is set
+		remote  string
+		merge   plumbing.ReferenceName
+		want    Upstream
+		wantErr error
+	}{
+		{
+			name:   "same name",
+			branch: "main",
+			remote: "origin",
+			merge:  plumbing.NewBranchReferenceName("main"),
+			want: Upstream{
+				Remote:      "origin",
+				Merge:       plumbing.NewBranchReferenceName("main"),
+				TrackingRef: plumbing.NewRemoteReferenceName("origin", "main"),
+			},
+		},
+		{
+			name:   "other name",
+			branch: "feature",
+			remote: "origin",
+			merge:  plumbing.NewBranchReferenceName("team/feature"),
+			want: Upstream{
+				Remote:      "origin",
+				Merge:       plumbing.NewBranchReferenceName("team/feature"),
+				TrackingRef: plumbing.NewRemoteReferenceName("origin", "team/feature"),
+			},
+		},
+		{name: "not configured", branch: "unconfigured", wantErr: ErrNoUpstream},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.remote != "" {
+				err := repo.SetUpstream(tt.branch, tt.remote, tt.merge)
+				if err != nil {
+					t.Fatalf("SetUpstream() returned error: %v", err)
+				}
+			}
+
+			got, err := repo.Upstream(tt.branch)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Upstream(%q) returned error %v, want %v", tt.branch, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Upstream(%q) returned error: %v", tt.branch, err)
+			}
+			if got != tt.want {
+				t.Errorf("Upstream(%q) = %+v, want %+v", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetUpstreamKeepsOtherBranches(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	err := repo.AddRemote("origin", "https://example.com/origin.git")
+	if err != nil {
+		t.Fatalf("failed to add remote: %v", err)
+	}
+
+	branches := []string{"main", "feature", "fix/a"}
+	for _, branch := range branches {
+		err := repo.SetUpstream(branch, "origin", plumbing.NewBranchReferenceName(branch))
+		if err != nil {
+			t.Fatalf("SetUpstream(%q) returned error: %v", branch, err)
+		}
+	}
+	// Moving a branch's upstream replaces it, rather than adding another
+	err = repo.SetUpstream("main", "origin", plumbing.NewBranchReferenceName("trunk"))
+	if err != nil {
+		t.Fatalf("SetUpstream() returned error: %v", err)
+	}
+
+	for _, branch := range branches {
+		want := plumbing.NewBranchReferenceName(branch)
+		if branch == "main" {
+			want = plumbing.NewBranchReferenceName("trunk")
+		}
+		upstream, err := repo.Upstream(branch)
+		if err != nil {
+			t.Fatalf("Upstream(%q) returned error: %v", branch, err)
+		}
+		if upstream.Merge != want {
+			t.Errorf("Upstream(%q).Merge = %q, want %q", branch, upstream.Merge, want)
+		}
+	}
+}
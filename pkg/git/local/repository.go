@@ -5,13 +5,18 @@ package local
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 
+	"github.com/go-git/go-billy/v6"
 	"github.com/go-git/go-billy/v6/osfs"
 	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
 	"github.com/go-git/go-git/v6/storage/filesystem"
 	"github.com/go-git/go-git/v6/x/plumbing/worktree"
 )
@@ -27,8 +32,33 @@ const (
 	// GitFilePrefix refers to the standard prefix that must be present in any linked worktree's
 	// .git txt file
 	GitFilePrefix = "gitdir:"
+
+	// GitHeadPrefix refers to the standard prefix of a HEAD file which points to a branch, rather than
+	// directly to a commit
+	GitHeadPrefix = "ref:"
+
+	// linkedWorktreesDir is the directory within the main worktree's .git/ directory which holds the
+	// administrative files for each linked worktree
+	linkedWorktreesDir = "worktrees"
+
+	// lockFile is the file within a linked worktree's administrative directory which marks the worktree as
+	// locked. Its content is the reason the worktree was locked
+	lockFile = "locked"
 )
 
+var (
+	// metadataMu serializes access to the metadata shared by all of a repository's worktrees: the repository's config,
+	// which is read, modified, then rewritten in place, and the names of linked worktrees' administrative directories.
+	// Several trees may be created at once, each initializing its submodules
+	metadataMu sync.Mutex
+	// pendingWorktrees holds the administrative directories of worktrees which are still being created, so are left
+	// out of listings until complete
+	pendingWorktrees = map[string]bool{}
+)
+
+// invalidWorktreeNameChars matches the characters which may not appear in a worktree's name
+var invalidWorktreeNameChars = regexp.MustCompile(`[^a-zA-Z0-9\-]`)
+
 type Repository struct {
 	// initPath is the filepath the repository was opened from
 	initPath string
@@ -40,6 +70,8 @@ type Repository struct {
 func NewRepository(path string) (*Repository, error) {
 	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{
 		DetectDotGit: true,
+		// Linked worktrees store their refs and objects in the main worktree's .git/ directory
+		EnableDotGitCommonDir: true,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to read git repository %q (is %q a git repo or grove environment?): %w", path, path, err)
@@ -51,53 +83,132 @@ func NewRepository(path string) (*Repository, error) {
 	return r, nil
 }
 
-func (r *Repository) DefaultBranch() (string, error) {
-	return "main", nil
+// FromRepository wraps a repository which has already been opened or cloned. The given path is only used to identify
+// the repository in errors
+func FromRepository(repo *git.Repository, path string) *Repository {
+	return &Repository{
+		initPath: path,
+		repo:     repo,
+	}
 }
 
+// ErrNoWorktree is returned when a path is not within any worktree
+var ErrNoWorktree = errors.New("not within a git worktree")
+
+// FindWorktreeRoot walks up from the given path to find the root of the innermost worktree containing it, similar
+// to 'git rev-parse --show-toplevel'. A worktree's root is the first directory containing a .git directory or txt file.
+//
+// ErrNoWorktree is returned if the filesystem root is reached without finding one
+func FindWorktreeRoot(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine absolute path of %q: %w", path, err)
+	}
+
+	for dir := path; ; dir = filepath.Dir(dir) {
+		_, err = os.Stat(GitPath(dir))
+		if err == nil {
+			return dir, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to check %q: %w", GitPath(dir), err)
+		}
+		if filepath.Dir(dir) == dir {
+			return "", ErrNoWorktree
+		}
+	}
+}
+
+// ErrBareRepository is returned when an operation requires the main worktree of a bare repository, which has none
+var ErrBareRepository = errors.New("repository is bare: it has no main worktree")
+
+// MainWorktree returns the absolute path to the root of the repository's main worktree - the worktree which
+// holds the .git/ directory shared by every linked worktree.
+//
+// ErrBareRepository is returned if the repository is bare, in which case every worktree is a linked worktree
 func (r *Repository) MainWorktree() (string, error) {
-	CurrentWorktreePath, err := r.CurrentWorktree()
+	bare, err := r.IsBare()
+	if err != nil {
+		return "", err
+	}
+	if bare {
+		return "", ErrBareRepository
+	}
+
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return "", err
+	}
+
+	// The main worktree is the directory directly above the shared .git/ directory
+	return filepath.Dir(commonDir), nil
+}
+
+// IsBare reports whether the repository is bare
+func (r *Repository) IsBare() (bool, error) {
+	metadataMu.Lock()
+	cfg, err := r.repo.Config()
+	metadataMu.Unlock()
+	if err != nil {
+		return false, fmt.Errorf("failed to read configuration of repository %q: %w", r.initPath, err)
+	}
+	return cfg.Core.IsBare, nil
+}
+
+// CommonDir returns the absolute path to the git directory shared by every worktree of the repository: the main
+// worktree's .git/ directory, or the repository itself when it is bare
+func (r *Repository) CommonDir() (string, error) {
+	currentWorktreePath, err := r.CurrentWorktree()
 	if err != nil {
 		return "", fmt.Errorf("failed to determine path of current worktree: %w", err)
 	}
-	gitPath := GitPath(CurrentWorktreePath)
+	gitPath := GitPath(currentWorktreePath)
 
 	// Determine if current worktree has a .git/ directory - if so,
 	// the current worktree is the main worktree for the repo. If not,
-	// we're in a linked worktree, so we'll have to parse out the .git
-	// txt file to find the location of the main worktree
+	// we're in a linked worktree, or at the root of a bare grove, so
+	// we'll have to parse out the .git txt file
 	info, err := os.Stat(gitPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read %q: %w", CurrentWorktreePath, err)
+		return "", fmt.Errorf("failed to read %q: %w", currentWorktreePath, err)
 	}
 	if info.IsDir() {
-		return CurrentWorktreePath, nil
+		return gitPath, nil
 	}
 
-	// We're in a linked worktree - find the main worktree's absolute path
-	mainWorktreePath, err := r.readGitFile(gitPath)
+	// The .git txt file points either to a linked worktree's administrative directory within the common
+	// git directory, or directly to a bare repository
+	gitDir, err := r.readGitFile(gitPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to determine the path to the main worktree from %q: %w", gitPath, err)
+		return "", fmt.Errorf("failed to determine the common git directory from %q: %w", gitPath, err)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(currentWorktreePath, gitDir)
 	}
 
-	// Construct the absolute path if the returned path is relative
-	if !filepath.IsAbs(mainWorktreePath) {
-		mainWorktreePath, err = filepath.Abs(filepath.Join(CurrentWorktreePath, mainWorktreePath))
-		if err != nil {
-			return "", fmt.Errorf("failed to convert relative path to main worktree %q to absolute path: %w", mainWorktreePath, err)
+	// An administrative directory's 'commondir' file holds the path to the shared git directory, typically
+	// relative to the administrative directory itself. A bare repository has no such file
+	commonDir, err := readFirstLine(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return filepath.Clean(gitDir), nil
 		}
+		return "", fmt.Errorf("failed to determine the common git directory of worktree %q: %w", currentWorktreePath, err)
 	}
-
-	// The returned path typically points to our linked worktree's location within the .git/ directory of the main worktree.
-	// In order to find the root of the main worktree, we must work backwards through the path until we reach the directory
-	// directly above the lowest-level .git/ entry
-	tokens := strings.Split(mainWorktreePath, GitStorePath)
-	if len(tokens) == 1 {
-		return tokens[0], nil
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
 	}
+	return filepath.Clean(commonDir), nil
+}
 
-	cleanedPath := strings.Join(tokens[:len(tokens)-1], GitStorePath)
-	return cleanedPath, nil
+// WriteGitFile creates the .git txt file at the root of the directory at path, linking it to the git directory gitDir
+func WriteGitFile(path, gitDir string) error {
+	gitFilePath := GitPath(path)
+	err := os.WriteFile(gitFilePath, []byte(fmt.Sprintf("%s %s\n", GitFilePrefix, gitDir)), 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write %q: %w", gitFilePath, err)
+	}
+	return nil
 }
 
 // readGitFile opens the .git txt file at the provided path and parses the content.
@@ -147,10 +258,21 @@ func (r *Repository) CurrentWorktree() (string, error) {
 	return wt.Filesystem.Root(), nil
 }
 
+// AddWorktreeOptions configures how AddWorktree creates a new worktree
+type AddWorktreeOptions struct {
+	// Branch is the name of the branch checked out in the new worktree. If the branch does not already
+	// exist, it is created at Commit. Defaults to the last element of the worktree's path
+	Branch string
+	// Commit is the commit a newly-created Branch starts at. Defaults to the main worktree's HEAD
+	Commit plumbing.Hash
+	// Detach checks out Commit in the new worktree with a detached HEAD, rather than checking out Branch
+	Detach bool
+}
+
 // AddWorktree creates a new worktree at the provided path named after the last element in the given path
 //
 // If the given path does not already exist as an empty directory in the local filesystem, an error is returned
-func (r *Repository) AddWorktree(path string) error {
+func (r *Repository) AddWorktree(path string, opts AddWorktreeOptions) error {
 	// Validate the directory exists & is empty
 	files, err := os.ReadDir(path)
 	if err != nil {
@@ -160,29 +282,374 @@ func (r *Repository) AddWorktree(path string) error {
 		return fmt.Errorf("directory %q is not empty", path)
 	}
 
-	rootDir, err := r.MainWorktree()
+	branch := opts.Branch
+	if branch == "" {
+		branch = filepath.Base(path)
+	}
+
+	name, release, err := r.reserveWorktreeName(filepath.Base(path))
 	if err != nil {
-		return fmt.Errorf("failed to determine root directory of repository: %w", err)
+		return err
 	}
+	defer release()
 
-	gitFs := osfs.New(filepath.Join(rootDir, ".git"), osfs.WithBoundOS())
-	repoStore := filesystem.NewStorageWithOptions(gitFs, nil, filesystem.Options{})
-	worktreeMgr, err := worktree.New(repoStore)
+	// A repository without commits - such as one cloned from an empty remote - has nothing to check out, so the
+	// branch is left unborn until the worktree's first commit, as with 'git worktree add --orphan'
+	if opts.Commit.IsZero() && !opts.Detach {
+		unborn, err := r.isUnborn()
+		if err != nil {
+			return err
+		}
+		if unborn {
+			return r.addOrphanWorktree(path, name, branch)
+		}
+	}
+
+	worktreeMgr, err := r.worktreeManager()
 	if err != nil {
-		return fmt.Errorf("failed to initialize new worktree manager for %q: %w", r.initPath, err)
+		return err
 	}
 
-	// Create new worktree in the provided directory
+	// Create new worktree in the provided directory. The worktree manager always names the new branch
+	// after the worktree, so start from a detached HEAD and check out the desired branch afterwards
 	fs := osfs.New(path)
-	name := filepath.Base(path)
-	err = worktreeMgr.Add(fs, name)
+	addOpts := []worktree.Option{worktree.WithDetachedHead()}
+	if !opts.Commit.IsZero() {
+		addOpts = append(addOpts, worktree.WithCommit(opts.Commit))
+	}
+	err = worktreeMgr.Add(fs, name, addOpts...)
 	if err != nil {
 		return fmt.Errorf("failed to create new worktree: %w", err)
 	}
+	if opts.Detach {
+		return nil
+	}
 
+	err = r.checkoutBranch(worktreeMgr, fs, branch)
+	if err != nil {
+		// Remove the new worktree's metadata, so the failed worktree isn't left registered with the repository, and its
+		// files, so that the directory - which was empty - can be reused
+		removeErr := worktreeMgr.Remove(name)
+		if removeErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to remove metadata of worktree %q: %v\n", name, removeErr)
+		}
+		removeErr = emptyDir(path)
+		if removeErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to remove the files of worktree %q: %v\n", name, removeErr)
+		}
+		return fmt.Errorf("failed to check out branch %q in worktree %q: %w", branch, path, err)
+	}
+
+	return nil
+}
+
+// emptyDir removes everything within the directory at path, leaving the directory itself in place
+func emptyDir(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		err = os.RemoveAll(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isUnborn reports whether the repository's HEAD refers to a branch which does not exist yet, as it has no commits
+func (r *Repository) isUnborn() (bool, error) {
+	_, err := r.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	return false, nil
+}
+
+// addOrphanWorktree registers the empty directory at path as a linked worktree with the given name, whose HEAD refers
+// to the unborn branch. The branch is created by the worktree's first commit
+func (r *Repository) addOrphanWorktree(path, name, branch string) error {
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to determine absolute path of %q: %w", path, err)
+	}
+
+	adminDir := filepath.Join(commonDir, "worktrees", name)
+	err = os.MkdirAll(adminDir, 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create worktree metadata %q: %w", adminDir, err)
+	}
+	files := map[string]string{
+		"gitdir":    GitPath(absPath),
+		"commondir": filepath.Join("..", ".."),
+		"HEAD":      fmt.Sprintf("%s %s", GitHeadPrefix, plumbing.NewBranchReferenceName(branch)),
+	}
+	for file, content := range files {
+		err = os.WriteFile(filepath.Join(adminDir, file), []byte(content+"\n"), 0o644)
+		if err != nil {
+			_ = os.RemoveAll(adminDir)
+			return fmt.Errorf("failed to write worktree metadata %q: %w", file, err)
+		}
+	}
+
+	err = WriteGitFile(absPath, adminDir)
+	if err != nil {
+		_ = os.RemoveAll(adminDir)
+		return err
+	}
 	return nil
 }
 
+// checkoutBranch checks out the given branch in the linked worktree on the filesystem fs, creating the branch
+// at the worktree's current commit if it does not already exist
+func (r *Repository) checkoutBranch(worktreeMgr *worktree.Worktree, fs billy.Filesystem, branch string) error {
+	linkedRepo, err := worktreeMgr.Open(fs)
+	if err != nil {
+		return fmt.Errorf("failed to open worktree: %w", err)
+	}
+	linkedWorktree, err := linkedRepo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree: %w", err)
+	}
+
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	checkoutOpts := &git.CheckoutOptions{
+		Branch: branchRef,
+	}
+	_, err = r.repo.Reference(branchRef, false)
+	if err != nil {
+		if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return fmt.Errorf("failed to look up branch %q: %w", branch, err)
+		}
+		// Branch does not exist yet - create it at the worktree's current commit
+		checkoutOpts.Create = true
+	}
+
+	return linkedWorktree.Checkout(checkoutOpts)
+}
+
+// ResolveCommit resolves the given revision - such as a branch, tag, or (abbreviated) commit hash - to the
+// commit it refers to. Annotated tags are resolved to the commit they point to
+func (r *Repository) ResolveCommit(revision string) (plumbing.Hash, error) {
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to resolve %q to a commit: %w", revision, err)
+	}
+	return *hash, nil
+}
+
+// ResolveCommitIn resolves the given revision to the commit it refers to, as ResolveCommit does, but from the worktree
+// rooted at path: revisions relative to HEAD, such as 'HEAD~1', refer to that worktree's HEAD
+func (r *Repository) ResolveCommitIn(path, revision string) (plumbing.Hash, error) {
+	worktreeRepo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to open worktree %q: %w", path, err)
+	}
+	hash, err := worktreeRepo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to resolve %q to a commit: %w", revision, err)
+	}
+	return *hash, nil
+}
+
+// reserveWorktreeName derives a unique name for a new worktree's administrative directory from the given base name.
+// The name is reserved until the returned func is called, once the directory has been created, so that worktrees added
+// concurrently are never given the same name.
+//
+// Worktree names may only contain alphanumeric characters and '-', so any other character is replaced with '-'.
+// If a worktree with the resulting name already exists, a numeric suffix is appended, as git itself does
+func (r *Repository) reserveWorktreeName(base string) (string, func(), error) {
+	name := invalidWorktreeNameChars.ReplaceAllString(base, "-")
+	if name == "" {
+		name = "worktree"
+	}
+
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return "", nil, err
+	}
+	adminDir := filepath.Join(commonDir, linkedWorktreesDir)
+
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+	candidate := name
+	for i := 1; ; i++ {
+		candidateDir := filepath.Join(adminDir, candidate)
+		_, err = os.Stat(candidateDir)
+		if errors.Is(err, os.ErrNotExist) && !pendingWorktrees[candidateDir] {
+			pendingWorktrees[candidateDir] = true
+			release := func() {
+				metadataMu.Lock()
+				delete(pendingWorktrees, candidateDir)
+				metadataMu.Unlock()
+			}
+			return candidate, release, nil
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", nil, fmt.Errorf("failed to check for existing worktree %q: %w", candidate, err)
+		}
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+}
+
+// worktreeManager initializes a go-git worktree manager backed by the repository's common git directory
+func (r *Repository) worktreeManager() (*worktree.Worktree, error) {
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return nil, err
+	}
+
+	gitFs := osfs.New(commonDir, osfs.WithBoundOS())
+	repoStore := filesystem.NewStorageWithOptions(gitFs, nil, filesystem.Options{})
+	worktreeMgr, err := worktree.New(repoStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize new worktree manager for %q: %w", r.initPath, err)
+	}
+	return worktreeMgr, nil
+}
+
+// Worktree describes a single working tree attached to the repository
+type Worktree struct {
+	// Path is the absolute path to the root of the worktree
+	Path string
+	// Branch is the short name of the branch checked out in the worktree. It is empty when HEAD is detached
+	Branch string
+	// Head is the commit the worktree's HEAD currently resolves to. It is the zero hash when HEAD refers to an
+	// unborn branch
+	Head plumbing.Hash
+	// GitDir is the worktree's git directory: the .git/ directory for the main worktree, or the worktree's
+	// administrative directory within the common git directory for linked worktrees
+	GitDir string
+	// Locked indicates whether the worktree is locked, protecting it from being pruned
+	Locked bool
+	// LockReason is the reason given when the worktree was locked, if any
+	LockReason string
+	// Main indicates whether this is the repository's main worktree
+	Main bool
+}
+
+// Worktrees lists every worktree of the repository, starting with the main worktree followed by each linked worktree.
+// Bare repositories have no main worktree, so only their linked worktrees are listed
+func (r *Repository) Worktrees() ([]Worktree, error) {
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return nil, err
+	}
+	bare, err := r.IsBare()
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees := []Worktree{}
+	if !bare {
+		mainWorktree, err := r.readWorktree(filepath.Dir(commonDir), commonDir)
+		if err != nil {
+			return nil, err
+		}
+		mainWorktree.Main = true
+		worktrees = append(worktrees, mainWorktree)
+	}
+
+	// Each linked worktree has an administrative directory under worktrees/ in the common git directory,
+	// containing its own HEAD and a 'gitdir' file pointing back to the worktree's .git txt file
+	adminDir := filepath.Join(commonDir, linkedWorktreesDir)
+	entries, err := os.ReadDir(adminDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			// No linked worktrees have been created
+			return worktrees, nil
+		}
+		return nil, fmt.Errorf("failed to read linked worktrees from %q: %w", adminDir, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		worktreeAdminDir := filepath.Join(adminDir, entry.Name())
+		metadataMu.Lock()
+		pending := pendingWorktrees[worktreeAdminDir]
+		metadataMu.Unlock()
+		if pending {
+			continue
+		}
+
+		gitFilePath, err := readFirstLine(filepath.Join(worktreeAdminDir, "gitdir"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine location of linked worktree %q: %w", entry.Name(), err)
+		}
+
+		worktree, err := r.readWorktree(filepath.Dir(gitFilePath), worktreeAdminDir)
+		if err != nil {
+			return nil, err
+		}
+		worktrees = append(worktrees, worktree)
+	}
+
+	return worktrees, nil
+}
+
+// readWorktree builds a Worktree for the worktree rooted at path, using the HEAD file found in gitDir
+func (r *Repository) readWorktree(path, gitDir string) (Worktree, error) {
+	headPath := filepath.Join(gitDir, "HEAD")
+	head, err := readFirstLine(headPath)
+	if err != nil {
+		return Worktree{}, fmt.Errorf("failed to read HEAD of worktree %q: %w", path, err)
+	}
+
+	worktree := Worktree{
+		Path:   path,
+		GitDir: gitDir,
+	}
+
+	reason, err := os.ReadFile(filepath.Join(gitDir, lockFile))
+	if err == nil {
+		worktree.Locked = true
+		worktree.LockReason = strings.TrimSpace(string(reason))
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return Worktree{}, fmt.Errorf("failed to determine lock state of worktree %q: %w", path, err)
+	}
+
+	// A detached HEAD contains the commit hash directly
+	if !strings.HasPrefix(head, GitHeadPrefix) {
+		worktree.Head = plumbing.NewHash(head)
+		return worktree, nil
+	}
+
+	refName := plumbing.ReferenceName(strings.TrimSpace(strings.TrimPrefix(head, GitHeadPrefix)))
+	worktree.Branch = refName.Short()
+
+	ref, err := r.repo.Reference(refName, true)
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			// The branch is unborn - no commits have been made to it yet
+			return worktree, nil
+		}
+		return Worktree{}, fmt.Errorf("failed to resolve %q for worktree %q: %w", refName, path, err)
+	}
+	worktree.Head = ref.Hash()
+
+	return worktree, nil
+}
+
+// readFirstLine returns the first line of the file at the given path, with surrounding whitespace removed
+func readFirstLine(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %q: %w", path, err)
+	}
+	line, _, _ := strings.Cut(string(content), "\n")
+	return strings.TrimSpace(line), nil
+}
+
 // GitPath returns the canonical path to the .git directory or .git txt file, given the root
 // directory of a repository
 func GitPath(path string) string {
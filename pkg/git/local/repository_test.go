@@ -0,0 +1,373 @@
+package local
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// newTestRepository creates a repository in a temporary directory, with the branch 'main' checked out and a single
+// commit, whose hash is returned
+func newTestRepository(t *testing.T) (*Repository, plumbing.Hash) {
+	t.Helper()
+
+	path := t.TempDir()
+	_, err := git.PlainInit(path, false, git.WithDefaultBranch(plumbing.NewBranchReferenceName("main")))
+	if err != nil {
+		t.Fatalf("failed to initialize repository: %v", err)
+	}
+	head := commitFile(t, path, "README.md", "repository\n")
+
+	repo, err := NewRepository(path)
+	if err != nil {
+		t.Fatalf("failed to open repository: %v", err)
+	}
+	return repo, head
+}
+
+// commitFile writes the file of the given name in the worktree at path, and commits it
+func commitFile(t *testing.T, path, name, content string) plumbing.Hash {
+	t.Helper()
+
+	err := os.WriteFile(filepath.Join(path, name), []byte(content), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write %q: %v", name, err)
+	}
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		t.Fatalf("failed to open worktree %q: %v", path, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to open worktree %q: %v", path, err)
+	}
+	_, err = wt.Add(name)
+	if err != nil {
+		t.Fatalf("failed to stage %q: %v", name, err)
+	}
+	signature := &object.Signature{Name: "grove", Email: "grove@example.com", When: time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)}
+	hash, err := wt.Commit("add "+name, &git.CommitOptions{Author: signature, Committer: signature})
+	if err != nil {
+		t.Fatalf("failed to commit %q: %v", name, err)
+	}
+	return hash
+}
+
+// newTestBareRepository clones a repository created by newTestRepository into a bare repository in '.bare', beneath a
+// temporary directory linking to it through a .git file - as in a bare grove. The repository is opened from that
+// directory, which is returned along with the repository's HEAD
+func newTestBareRepository(t *testing.T) (*Repository, string, plumbing.Hash) {
+	t.Helper()
+
+	source, head := newTestRepository(t)
+	root := t.TempDir()
+	bareDir := filepath.Join(root, ".bare")
+	_, err := git.PlainClone(bareDir, &git.CloneOptions{URL: source.initPath, Bare: true})
+	if err != nil {
+		t.Fatalf("failed to clone bare repository: %v", err)
+	}
+	err = WriteGitFile(root, bareDir)
+	if err != nil {
+		t.Fatalf("failed to link bare repository: %v", err)
+	}
+
+	repo, err := NewRepository(root)
+	if err != nil {
+		t.Fatalf("failed to open bare repository: %v", err)
+	}
+	return repo, root, head
+}
+
+// addTestWorktree adds a linked worktree at the given path, failing the test otherwise
+func addTestWorktree(t *testing.T, r *Repository, path string, opts AddWorktreeOptions) {
+	t.Helper()
+
+	err := os.MkdirAll(path, 0o755)
+	if err != nil {
+		t.Fatalf("failed to create directory %q: %v", path, err)
+	}
+	err = r.AddWorktree(path, opts)
+	if err != nil {
+		t.Fatalf("failed to add worktree %q: %v", path, err)
+	}
+}
+
+func TestAddWorktree(t *testing.T) {
+	tests := []struct {
+		name string
+		// path is where the worktree is added, relative to a temporary directory
+		path string
+		opts AddWorktreeOptions
+		// prepare creates whatever exists at the worktree's path beforehand, and any branches it checks out
+		prepare    func(t *testing.T, r *Repository, path string, first, second plumbing.Hash)
+		wantBranch string
+		// wantSecond is whether the worktree's HEAD should be the repository's second commit, rather than its first
+		wantSecond bool
+		wantErr    bool
+	}{
+		{name: "named after path", path: "feature", wantBranch: "feature"},
+		{name: "nested path", path: filepath.Join("fix", "bug"), wantBranch: "bug"},
+		{name: "branch", path: "tree", opts: AddWorktreeOptions{Branch: "fix/bug"}, wantBranch: "fix/bug"},
+		{
+			name: "existing branch",
+			path: "existing",
+			prepare: func(t *testing.T, r *Repository, _ string, _, second plumbing.Hash) {
+				err := r.repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("existing"), second))
+				if err != nil {
+					t.Fatalf("failed to create branch: %v", err)
+				}
+			},
+			wantBranch: "existing",
+			wantSecond: true,
+		},
+		{name: "detached", path: "detached", opts: AddWorktreeOptions{Detach: true}},
+		{
+			name: "not empty",
+			path: "dirty",
+			prepare: func(t *testing.T, _ *Repository, path string, _, _ plumbing.Hash) {
+				err := os.WriteFile(filepath.Join(path, "file"), nil, 0o644)
+				if err != nil {
+					t.Fatalf("failed to write file: %v", err)
+				}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, first := newTestRepository(t)
+			mainWorktree, err := repo.MainWorktree()
+			if err != nil {
+				t.Fatalf("MainWorktree() returned error: %v", err)
+			}
+			second := commitFile(t, mainWorktree, "CHANGELOG.md", "changes\n")
+			path := filepath.Join(t.TempDir(), tt.path)
+			err = os.MkdirAll(path, 0o755)
+			if err != nil {
+				t.Fatalf("failed to create directory %q: %v", path, err)
+			}
+			if tt.prepare != nil {
+				tt.prepare(t, repo, path, first, second)
+			}
+			opts := tt.opts
+			if opts.Commit.IsZero() && !tt.wantSecond {
+				opts.Commit = first
+			}
+
+			err = repo.AddWorktree(path, opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("AddWorktree() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddWorktree() returned error: %v", err)
+			}
+			worktree := findWorktree(t, repo, path)
+			want := first
+			if tt.wantSecond {
+				want = second
+			}
+			if worktree.Branch != tt.wantBranch || worktree.Head != want || worktree.Main {
+				t.Errorf("added worktree %+v, want branch %q at %s", worktree, tt.wantBranch, want)
+			}
+			_, err = os.Stat(filepath.Join(path, "README.md"))
+			if err != nil {
+				t.Errorf("AddWorktree() did not check out files: %v", err)
+			}
+		})
+	}
+}
+
+func TestAddWorktreeMissingDirectory(t *testing.T) {
+	repo, _ := newTestRepository(t)
+	err := repo.AddWorktree(filepath.Join(t.TempDir(), "missing"), AddWorktreeOptions{})
+	if err == nil {
+		t.Fatal("AddWorktree() succeeded without a directory to add the worktree in")
+	}
+}
+
+func TestAddWorktreeUnborn(t *testing.T) {
+	tests := []struct {
+		name       string
+		bare       bool
+		path       string
+		opts       AddWorktreeOptions
+		wantBranch string
+	}{
+		{name: "branch named after path", path: "feature", wantBranch: "feature"},
+		{name: "branch", path: "tree", opts: AddWorktreeOptions{Branch: "feature/x"}, wantBranch: "feature/x"},
+		{name: "bare", bare: true, path: "main", wantBranch: "main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+			// A repository without commits, such as one cloned from an empty remote
+			repoPath := filepath.Join(t.TempDir(), "repo")
+			initPath := repoPath
+			if tt.bare {
+				// As in bare groves, the repository is found from the directory holding it
+				initPath = filepath.Join(repoPath, ".bare")
+			}
+			_, err := git.PlainInit(initPath, tt.bare, git.WithDefaultBranch(plumbing.NewBranchReferenceName("main")))
+			if err != nil {
+				t.Fatalf("failed to initialize repository: %v", err)
+			}
+			if tt.bare {
+				err = WriteGitFile(repoPath, initPath)
+				if err != nil {
+					t.Fatalf("failed to link repository: %v", err)
+				}
+			}
+			repo, err := NewRepository(repoPath)
+			if err != nil {
+				t.Fatalf("failed to open repository: %v", err)
+			}
+			path := filepath.Join(t.TempDir(), tt.path)
+			addTestWorktree(t, repo, path, tt.opts)
+
+			// The worktree's HEAD refers to its branch, which is only created by the first commit
+			head := strings.TrimSpace(runTestGit(t, path, "symbolic-ref", "HEAD"))
+			if head != plumbing.NewBranchReferenceName(tt.wantBranch).String() {
+				t.Errorf("HEAD of worktree = %q, want branch %q", head, tt.wantBranch)
+			}
+			_, err = runGit(path, nil, "rev-parse", "--verify", "HEAD")
+			if err == nil {
+				t.Errorf("branch %q exists before the worktree's first commit", tt.wantBranch)
+			}
+			worktree := findWorktree(t, repo, path)
+			if worktree.Branch != tt.wantBranch || !worktree.Head.IsZero() || worktree.Main {
+				t.Errorf("worktree = %+v, want linked worktree of unborn branch %q", worktree, tt.wantBranch)
+			}
+			commonDir := strings.TrimSpace(runTestGit(t, path, "rev-parse", "--path-format=absolute", "--git-common-dir"))
+			if filepath.Dir(worktree.GitDir) != filepath.Join(commonDir, "worktrees") {
+				t.Errorf("git directory of worktree = %q, want one within %q", worktree.GitDir, commonDir)
+			}
+
+			// The first commit creates the branch, leaving the main worktree's HEAD as it was
+			runTestGit(t, path, "-c", "user.name=grove", "-c", "user.email=grove@example.com", "commit", "--allow-empty", "-m", "first")
+			runTestGit(t, path, "rev-parse", "--verify", "refs/heads/"+tt.wantBranch)
+			if !tt.bare {
+				mainHead := strings.TrimSpace(runTestGit(t, repoPath, "symbolic-ref", "HEAD"))
+				if mainHead != "refs/heads/main" {
+					t.Errorf("HEAD of main worktree = %q, want refs/heads/main", mainHead)
+				}
+			}
+		})
+	}
+}
+
+func TestWorktrees(t *testing.T) {
+	tests := []struct {
+		name string
+		bare bool
+	}{
+		{name: "main worktree", bare: false},
+		{name: "bare", bare: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				repo *Repository
+				root string
+				head plumbing.Hash
+			)
+			if tt.bare {
+				repo, root, head = newTestBareRepository(t)
+			} else {
+				repo, head = newTestRepository(t)
+				root = t.TempDir()
+			}
+			feature := filepath.Join(root, "feature")
+			addTestWorktree(t, repo, feature, AddWorktreeOptions{Commit: head})
+			detached := filepath.Join(root, "detached")
+			addTestWorktree(t, repo, detached, AddWorktreeOptions{Commit: head, Detach: true})
+			err := repo.LockWorktree(detached, "in use")
+			if err != nil {
+				t.Fatalf("failed to lock worktree: %v", err)
+			}
+
+			// Linked worktrees are listed in order of their administrative directories' names
+			want := []Worktree{
+				{Path: detached, Head: head, Locked: true, LockReason: "in use"},
+				{Path: feature, Branch: "feature", Head: head},
+			}
+			mainWorktree, err := repo.MainWorktree()
+			if tt.bare {
+				if !errors.Is(err, ErrBareRepository) {
+					t.Errorf("MainWorktree() returned %q, %v, want ErrBareRepository", mainWorktree, err)
+				}
+			} else {
+				if err != nil || mainWorktree != repo.initPath {
+					t.Errorf("MainWorktree() = %q, %v, want %q", mainWorktree, err, repo.initPath)
+				}
+				want = append([]Worktree{{Path: repo.initPath, Branch: "main", Head: head, Main: true}}, want...)
+			}
+
+			// The worktrees are the same wherever the repository is opened from
+			for _, openedFrom := range []string{repo.initPath, feature} {
+				opened, err := NewRepository(openedFrom)
+				if err != nil {
+					t.Fatalf("failed to open repository from %q: %v", openedFrom, err)
+				}
+				got, err := opened.Worktrees()
+				if err != nil {
+					t.Fatalf("Worktrees() returned error: %v", err)
+				}
+				if len(got) != len(want) {
+					t.Fatalf("Worktrees() from %q = %+v, want %+v", openedFrom, got, want)
+				}
+				for i := range want {
+					got[i].GitDir = ""
+					if got[i] != want[i] {
+						t.Errorf("Worktrees()[%d] from %q = %+v, want %+v", i, openedFrom, got[i], want[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestMainWorktreeFromLinkedWorktree(t *testing.T) {
+	repo, head := newTestRepository(t)
+	linked := filepath.Join(t.TempDir(), "linked")
+	addTestWorktree(t, repo, linked, AddWorktreeOptions{Commit: head})
+
+	opened, err := NewRepository(linked)
+	if err != nil {
+		t.Fatalf("failed to open linked worktree: %v", err)
+	}
+	got, err := opened.MainWorktree()
+	if err != nil {
+		t.Fatalf("MainWorktree() returned error: %v", err)
+	}
+	if got != repo.initPath {
+		t.Errorf("MainWorktree() = %q, want %q", got, repo.initPath)
+	}
+}
+
+// findWorktree returns the worktree of the repository at path, failing the test if there is none
+func findWorktree(t *testing.T, r *Repository, path string) Worktree {
+	t.Helper()
+
+	worktrees, err := r.Worktrees()
+	if err != nil {
+		t.Fatalf("Worktrees() returned error: %v", err)
+	}
+	for _, worktree := range worktrees {
+		if worktree.Path == path {
+			return worktree
+		}
+	}
+	t.Fatalf("no worktree at %q in %+v", path, worktrees)
+	return Worktree{}
+}
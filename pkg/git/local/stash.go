@@ -0,0 +1,110 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// ErrStashNotFound is returned when a stash to be popped no longer exists, such as when it was popped or dropped with
+// git directly
+var ErrStashNotFound = errors.New("stash not found")
+
+// ErrNothingToStash is returned when a stash is attempted in a worktree without changes git would stash, such as when
+// its only differences are ones go-git reports but git does not
+var ErrNothingToStash = errors.New("nothing to stash")
+
+// Stash stashes the uncommitted changes in the worktree rooted at path, including untracked files, with the given
+// message, returning the commit recording the stash.
+//
+// go-git cannot stash, so the git CLI is used. The stash list is shared by every worktree of the repository, so the
+// returned commit identifies the stash to PopStash, however many others are pushed afterwards.
+//
+// ErrNothingToStash is returned if git stashed nothing, so that an older stash is never mistaken for this one
+func (r *Repository) Stash(path, message string) (plumbing.Hash, error) {
+	// Stashing records commits, so fail early with a helpful error rather than partway through
+	signature, err := r.Signature()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	before, err := stashHead(path)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	_, err = runGit(path, []string{
+		"GIT_COMMITTER_NAME=" + signature.Name,
+		"GIT_COMMITTER_EMAIL=" + signature.Email,
+	}, "stash", "push", "--include-untracked", "--message", message)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to stash changes in worktree %q: %w", path, err)
+	}
+	// 'git stash push' succeeds without pushing a stash when it finds no changes
+	after, err := stashHead(path)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if after.IsZero() || after == before {
+		return plumbing.ZeroHash, fmt.Errorf("%w in worktree %q", ErrNothingToStash, path)
+	}
+	return after, nil
+}
+
+// stashHead returns the newest stash of the repository containing the worktree rooted at path, or plumbing.ZeroHash
+// if there are none
+func stashHead(path string) (plumbing.Hash, error) {
+	out, err := runGit(path, nil, "for-each-ref", "--format=%(objectname)", "refs/stash")
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to resolve stash of worktree %q: %w", path, err)
+	}
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return plumbing.ZeroHash, nil
+	}
+	return plumbing.NewHash(out), nil
+}
+
+// PopStash applies the given stash, recorded by Stash, to the worktree rooted at path, and drops it. If applying the
+// stash conflicts with the worktree's changes, the stash is kept, so that nothing is lost.
+//
+// ErrStashNotFound is returned if the stash no longer exists
+func (r *Repository) PopStash(path string, stash plumbing.Hash) error {
+	out, err := runGit(path, nil, "stash", "list", "--format=%H")
+	if err != nil {
+		return fmt.Errorf("failed to list stashes: %w", err)
+	}
+	index := slices.Index(strings.Fields(out), stash.String())
+	if index < 0 {
+		return fmt.Errorf("%w: %q", ErrStashNotFound, stash)
+	}
+
+	_, err = runGit(path, nil, "stash", "pop", fmt.Sprintf("stash@{%d}", index))
+	if err != nil {
+		return fmt.Errorf("failed to pop stash %q in worktree %q: %w", stash, path, err)
+	}
+	return nil
+}
+
+// runGit runs the git CLI with the given arguments in the directory dir, adding env to its environment, and returns its
+// output. If it fails, the error includes what git printed to stderr
+func runGit(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message != "" {
+			return "", fmt.Errorf("'git %s' failed: %w: %s", strings.Join(args, " "), err, message)
+		}
+		return "", fmt.Errorf("'git %s' failed: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
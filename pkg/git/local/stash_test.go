@@ -0,0 +1,87 @@
+package local
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStash(t *testing.T) {
+	tests := []struct {
+		name string
+		// older pushes a stash before the one under test
+		older bool
+		// change is written to README.md before stashing, if not empty
+		change  string
+		wantErr error
+	}{
+		{name: "changes", change: "changed\n"},
+		{name: "changes with an older stash", older: true, change: "changed\n"},
+		{name: "no changes", wantErr: ErrNothingToStash},
+		{name: "no changes with an older stash", older: true, wantErr: ErrNothingToStash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+			repo, _ := newTestRepository(t)
+			path := repo.initPath
+			runTestGit(t, path, "config", "user.name", "grove")
+			runTestGit(t, path, "config", "user.email", "grove@example.com")
+
+			older := ""
+			if tt.older {
+				writeReadme(t, path, "older\n")
+				stash, err := repo.Stash(path, "older")
+				if err != nil {
+					t.Fatalf("failed to push older stash: %v", err)
+				}
+				older = stash.String()
+			}
+			if tt.change != "" {
+				writeReadme(t, path, tt.change)
+			}
+
+			stash, err := repo.Stash(path, "grove stash")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Stash() returned error %v, want %v", err, tt.wantErr)
+			}
+			stashes := strings.Fields(runTestGit(t, path, "stash", "list", "--format=%H"))
+			if tt.wantErr != nil {
+				if !stash.IsZero() {
+					t.Errorf("Stash() = %s, want zero hash", stash)
+				}
+				if tt.older && (len(stashes) != 1 || stashes[0] != older) {
+					t.Errorf("stashes = %v, want only the older stash %s", stashes, older)
+				}
+				return
+			}
+			if len(stashes) == 0 || stash.String() != stashes[0] || stash.String() == older {
+				t.Errorf("Stash() = %s, want the newest of stashes %v", stash, stashes)
+			}
+		})
+	}
+}
+
+// runTestGit runs the git CLI with the given arguments in dir, returning its output
+func runTestGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	out, err := runGit(dir, nil, args...)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return out
+}
+
+// writeReadme overwrites README.md in the worktree at path with content
+func writeReadme(t *testing.T, path, content string) {
+	t.Helper()
+
+	err := os.WriteFile(filepath.Join(path, "README.md"), []byte(content), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write README.md: %v", err)
+	}
+}
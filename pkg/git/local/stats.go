@@ -0,0 +1,124 @@
+package local
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// packIndexMagic begins every pack index of version 2 or later. Version 1 indexes begin directly with their fanout table
+var packIndexMagic = []byte{0xff, 't', 'O', 'c'}
+
+// RepositoryStats summarizes the contents of a repository
+type RepositoryStats struct {
+	// Refs is the number of references, excluding symbolic references such as HEAD
+	Refs int
+	// Branches is the number of remote-tracking branches
+	Branches int
+	// Objects is the number of objects stored, whether packed or loose
+	Objects int
+}
+
+// Stats summarizes the contents of the repository. Objects are counted from the repository's pack indexes and
+// object directories, as 'git count-objects' does, rather than by reading each object
+func (r *Repository) Stats() (RepositoryStats, error) {
+	stats := RepositoryStats{}
+
+	refs, err := r.repo.References()
+	if err != nil {
+		return RepositoryStats{}, fmt.Errorf("failed to list references: %w", err)
+	}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		stats.Refs++
+		if ref.Name().IsRemote() {
+			stats.Branches++
+		}
+		return nil
+	})
+	if err != nil {
+		return RepositoryStats{}, fmt.Errorf("failed to list references: %w", err)
+	}
+
+	commonDir, err := r.CommonDir()
+	if err != nil {
+		return RepositoryStats{}, err
+	}
+	stats.Objects, err = countObjects(filepath.Join(commonDir, "objects"))
+	if err != nil {
+		return RepositoryStats{}, err
+	}
+	return stats, nil
+}
+
+// countObjects counts the objects in the given object directory: those listed by each pack index, and each loose object
+func countObjects(objectsDir string) (int, error) {
+	count := 0
+
+	indexes, err := filepath.Glob(filepath.Join(objectsDir, "pack", "*.idx"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pack indexes: %w", err)
+	}
+	for _, index := range indexes {
+		n, err := packIndexObjects(index)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+
+	entries, err := os.ReadDir(objectsDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read object directory %q: %w", objectsDir, err)
+	}
+	for _, entry := range entries {
+		// Loose objects are stored beneath a directory named for the first byte of their hash
+		if !entry.IsDir() || len(entry.Name()) != 2 {
+			continue
+		}
+		loose, err := os.ReadDir(filepath.Join(objectsDir, entry.Name()))
+		if err != nil {
+			return 0, fmt.Errorf("failed to read object directory %q: %w", entry.Name(), err)
+		}
+		count += len(loose)
+	}
+	return count, nil
+}
+
+// packIndexObjects reads the number of objects in a pack from its index: the last entry of the index's fanout table
+func packIndexObjects(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open pack index %q: %w", path, err)
+	}
+	defer file.Close()
+
+	header := make([]byte, 8)
+	_, err = io.ReadFull(file, header)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read pack index %q: %w", path, err)
+	}
+	// The fanout table follows the magic number and version, if present
+	fanout := int64(0)
+	if bytes.Equal(header[:4], packIndexMagic) {
+		fanout = int64(len(header))
+	}
+
+	last := make([]byte, 4)
+	_, err = file.ReadAt(last, fanout+255*4)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, fmt.Errorf("pack index %q is truncated", path)
+		}
+		return 0, fmt.Errorf("failed to read pack index %q: %w", path, err)
+	}
+	return int(binary.BigEndian.Uint32(last)), nil
+}
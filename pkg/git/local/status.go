@@ -0,0 +1,240 @@
+package local
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// WorktreeStatus summarizes the uncommitted changes in a worktree
+type WorktreeStatus struct {
+	// Changed is the number of tracked files with staged or unstaged changes
+	Changed int `json:"changed"`
+	// Untracked is the number of files which are not tracked
+	Untracked int `json:"untracked"`
+}
+
+// Clean reports whether the worktree has no uncommitted changes, ignoring untracked files
+func (s WorktreeStatus) Clean() bool {
+	return s.Changed == 0
+}
+
+// Status determines the uncommitted changes in the worktree rooted at path
+func (r *Repository) Status(path string) (WorktreeStatus, error) {
+	_, wt, err := openWorktree(path)
+	if err != nil {
+		return WorktreeStatus{}, err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return WorktreeStatus{}, fmt.Errorf("failed to determine status of worktree %q: %w", path, err)
+	}
+
+	summary := WorktreeStatus{}
+	for _, fileStatus := range status {
+		if isUntracked(fileStatus) {
+			summary.Untracked++
+		} else {
+			summary.Changed++
+		}
+	}
+	return summary, nil
+}
+
+// isUntracked reports whether the given file status refers to an untracked file
+func isUntracked(status *git.FileStatus) bool {
+	return status.Staging == git.Untracked && status.Worktree == git.Untracked
+}
+
+// AheadBehind counts the commits reachable from local but not from upstream (ahead), and the commits reachable from
+// upstream but not from local (behind)
+func (r *Repository) AheadBehind(local, upstream plumbing.Hash) (int, int, error) {
+	d, err := r.diverge(local, upstream)
+	if err != nil {
+		return 0, 0, err
+	}
+	return d.ahead, d.behind, nil
+}
+
+// MergeBase returns the best common ancestor of the two given commits, from which their histories diverged. If the
+// commits share no history - or none which was cloned, in a shallow repository - plumbing.ZeroHash is returned
+func (r *Repository) MergeBase(a, b plumbing.Hash) (plumbing.Hash, error) {
+	d, err := r.diverge(a, b)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return d.mergeBase, nil
+}
+
+// divergence describes how the histories of two commits differ
+type divergence struct {
+	// ahead is the number of commits reachable from the first commit but not from the second
+	ahead int
+	// behind is the number of commits reachable from the second commit but not from the first
+	behind int
+	// mergeBase is the newest common ancestor of the two, or plumbing.ZeroHash if there is none
+	mergeBase plumbing.Hash
+}
+
+// Flags marking how a commit was reached by diverge
+const (
+	// reachedFromA marks commits reachable from the first commit
+	reachedFromA uint8 = 1 << iota
+	// reachedFromB marks commits reachable from the second commit
+	reachedFromB
+	// reachedFromBase marks commits reachable from a common ancestor, whose own ancestors are common too
+	reachedFromBase
+)
+
+// diverge compares the histories of a and b, as 'git rev-list --left-right --count a...b' does. Both are walked
+// together, newest commit first, and only until every commit left to walk is known to be a common ancestor - so that
+// the cost depends on how far they have diverged, rather than on the length of their history. Commits whose parents
+// were not cloned, in a shallow repository, are treated as the start of history.
+//
+// As with git, commit dates determine the order of the walk, so commits whose dates are badly skewed may be miscounted
+func (r *Repository) diverge(a, b plumbing.Hash) (divergence, error) {
+	shallow, err := r.repo.Storer.Shallow()
+	if err != nil {
+		return divergence{}, fmt.Errorf("failed to read shallow commits: %w", err)
+	}
+	boundary := map[plumbing.Hash]bool{}
+	for _, hash := range shallow {
+		boundary[hash] = true
+	}
+
+	flags := map[plumbing.Hash]uint8{}
+	queue := &commitQueue{}
+	for _, tip := range []struct {
+		hash plumbing.Hash
+		flag uint8
+	}{{a, reachedFromA}, {b, reachedFromB}} {
+		commit, err := r.repo.CommitObject(tip.hash)
+		if err != nil {
+			return divergence{}, fmt.Errorf("failed to read commit %q: %w", tip.hash, err)
+		}
+		flags[tip.hash] |= tip.flag
+		heap.Push(queue, commit)
+	}
+
+	d := divergence{}
+	for queue.Len() > 0 && !queue.onlyCommon(flags) {
+		commit := heap.Pop(queue).(*object.Commit)
+		flag := flags[commit.Hash]
+		if flag&(reachedFromA|reachedFromB) == reachedFromA|reachedFromB && flag&reachedFromBase == 0 {
+			// The first common ancestor reached is the newest
+			if d.mergeBase.IsZero() {
+				d.mergeBase = commit.Hash
+			}
+			flag |= reachedFromBase
+			flags[commit.Hash] = flag
+		}
+		if boundary[commit.Hash] {
+			continue
+		}
+
+		for _, parentHash := range commit.ParentHashes {
+			if flags[parentHash]|flag == flags[parentHash] {
+				continue
+			}
+			parent, err := r.repo.CommitObject(parentHash)
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				continue
+			}
+			if err != nil {
+				return divergence{}, fmt.Errorf("failed to read commit %q: %w", parentHash, err)
+			}
+			flags[parentHash] |= flag
+			heap.Push(queue, parent)
+		}
+	}
+
+	for _, flag := range flags {
+		switch flag & (reachedFromA | reachedFromB) {
+		case reachedFromA:
+			d.ahead++
+		case reachedFromB:
+			d.behind++
+		}
+	}
+	return d, nil
+}
+
+// commitQueue is a heap of commits, ordered newest first by commit date
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int {
+	return len(q)
+}
+
+func (q commitQueue) Less(i, j int) bool {
+	return q[i].Committer.When.After(q[j].Committer.When)
+}
+
+func (q commitQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *commitQueue) Push(x any) {
+	*q = append(*q, x.(*object.Commit))
+}
+
+func (q *commitQueue) Pop() any {
+	old := *q
+	commit := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return commit
+}
+
+// onlyCommon reports whether every commit left in the queue is known to be a common ancestor, so that walking them
+// cannot change which commits are reachable from only one side
+func (q commitQueue) onlyCommon(flags map[plumbing.Hash]uint8) bool {
+	for _, commit := range q {
+		if flags[commit.Hash]&reachedFromBase == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// CommitSubject returns the first line of the message of the given commit
+func (r *Repository) CommitSubject(hash plumbing.Hash) (string, error) {
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit %q: %w", hash, err)
+	}
+	subject, _, _ := strings.Cut(commit.Message, "\n")
+	return subject, nil
+}
+
+// CommitTime returns the time the given commit was committed
+func (r *Repository) CommitTime(hash plumbing.Hash) (time.Time, error) {
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read commit %q: %w", hash, err)
+	}
+	return commit.Committer.When, nil
+}
+
+// Diff computes the changes needed to turn the tree of the commit from into that of the commit to
+func (r *Repository) Diff(from, to plumbing.Hash) (*object.Patch, error) {
+	fromCommit, err := r.repo.CommitObject(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %q: %w", from, err)
+	}
+	toCommit, err := r.repo.CommitObject(to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %q: %w", to, err)
+	}
+
+	patch, err := fromCommit.Patch(toCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %q with %q: %w", from, to, err)
+	}
+	return patch, nil
+}
@@ -0,0 +1,303 @@
+package local
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// history builds commits with arbitrary parents in a repository's object store, each with an empty tree
+type history struct {
+	t       *testing.T
+	repo    *Repository
+	commits map[string]plumbing.Hash
+	next    time.Time
+}
+
+// newHistory creates a history in a new repository
+func newHistory(t *testing.T) *history {
+	repo, _ := newTestRepository(t)
+	return &history{
+		t:       t,
+		repo:    repo,
+		commits: map[string]plumbing.Hash{},
+		next:    time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// commit adds the commit of the given name with the given parents, which must already have been added, committed a
+// minute after the previous commit
+func (h *history) commit(name string, parents ...string) plumbing.Hash {
+	h.t.Helper()
+
+	hashes := []plumbing.Hash{}
+	for _, parent := range parents {
+		hash, ok := h.commits[parent]
+		if !ok {
+			h.t.Fatalf("parent %q of commit %q has not been added", parent, name)
+		}
+		hashes = append(hashes, hash)
+	}
+	h.commits[name] = h.store(name, hashes...)
+	return h.commits[name]
+}
+
+// store writes a commit with the given message and parents, returning its hash
+func (h *history) store(message string, parents ...plumbing.Hash) plumbing.Hash {
+	h.t.Helper()
+
+	h.next = h.next.Add(time.Minute)
+	signature := object.Signature{Name: "grove", Email: "grove@example.com", When: h.next}
+	commit := &object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      message,
+		TreeHash:     plumbing.NewHash("4b825dc642cb6eb9a060e54bf8d69288fbee4904"),
+		ParentHashes: parents,
+	}
+	obj := h.repo.repo.Storer.NewEncodedObject()
+	err := commit.Encode(obj)
+	if err != nil {
+		h.t.Fatalf("failed to encode commit %q: %v", message, err)
+	}
+	hash, err := h.repo.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		h.t.Fatalf("failed to store commit %q: %v", message, err)
+	}
+	return hash
+}
+
+func TestAheadBehind(t *testing.T) {
+	tests := []struct {
+		name string
+		// build adds the commits compared, 'local' and 'upstream', along with their history
+		build      func(h *history)
+		wantAhead  int
+		wantBehind int
+		// wantBase is the name of the expected merge base, or "" if there should be none
+		wantBase string
+	}{
+		{
+			name: "same",
+			build: func(h *history) {
+				h.commit("root")
+				h.commits["local"] = h.commit("a", "root")
+				h.commits["upstream"] = h.commits["a"]
+			},
+			wantBase: "a",
+		},
+		{
+			name: "ahead",
+			build: func(h *history) {
+				h.commit("root")
+				h.commit("upstream", "root")
+				h.commit("b", "upstream")
+				h.commit("local", "b")
+			},
+			wantAhead: 2,
+			wantBase:  "upstream",
+		},
+		{
+			name: "behind",
+			build: func(h *history) {
+				h.commit("root")
+				h.commit("local", "root")
+				h.commit("b", "local")
+				h.commit("c", "b")
+				h.commit("upstream", "c")
+			},
+			wantBehind: 3,
+			wantBase:   "local",
+		},
+		{
+			name: "diverged",
+			build: func(h *history) {
+				h.commit("root")
+				h.commit("base", "root")
+				h.commit("a", "base")
+				h.commit("local", "a")
+				h.commit("b", "base")
+				h.commit("c", "b")
+				h.commit("upstream", "c")
+			},
+			wantAhead:  2,
+			wantBehind: 3,
+			wantBase:   "base",
+		},
+		{
+			name: "merged upstream",
+			build: func(h *history) {
+				h.commit("root")
+				h.commit("a", "root")
+				h.commit("b", "root")
+				h.commit("upstream", "b")
+				h.commit("local", "a", "upstream")
+			},
+			// 'a' and the merge itself
+			wantAhead: 2,
+			wantBase:  "upstream",
+		},
+		{
+			name: "merged side branch",
+			build: func(h *history) {
+				h.commit("root")
+				h.commit("base", "root")
+				h.commit("side1", "root")
+				h.commit("side2", "side1")
+				h.commit("local", "base", "side2")
+				h.commit("upstream", "base")
+			},
+			wantAhead:  3,
+			wantBehind: 1,
+			wantBase:   "base",
+		},
+		{
+			name: "unrelated",
+			build: func(h *history) {
+				h.commit("a")
+				h.commit("local", "a")
+				h.commit("b")
+				h.commit("upstream", "b")
+			},
+			wantAhead:  2,
+			wantBehind: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHistory(t)
+			tt.build(h)
+
+			ahead, behind, err := h.repo.AheadBehind(h.commits["local"], h.commits["upstream"])
+			if err != nil {
+				t.Fatalf("AheadBehind() returned error: %v", err)
+			}
+			if ahead != tt.wantAhead || behind != tt.wantBehind {
+				t.Errorf("AheadBehind() = %d, %d, want %d, %d", ahead, behind, tt.wantAhead, tt.wantBehind)
+			}
+			base, err := h.repo.MergeBase(h.commits["local"], h.commits["upstream"])
+			if err != nil {
+				t.Fatalf("MergeBase() returned error: %v", err)
+			}
+			want := plumbing.ZeroHash
+			if tt.wantBase != "" {
+				want = h.commits[tt.wantBase]
+			}
+			if base != want {
+				t.Errorf("MergeBase() = %s, want %s (%q)", base, want, tt.wantBase)
+			}
+		})
+	}
+}
+
+func TestAheadBehindShallow(t *testing.T) {
+	tests := []struct {
+		name string
+		// shallow marks the boundary of the history as shallow commits, rather than omitting their parents
+		shallow bool
+	}{
+		{name: "shallow commits", shallow: true},
+		{name: "missing parents", shallow: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHistory(t)
+			// The history before 'boundary' was not cloned
+			missing := plumbing.NewHash("0123456789012345678901234567890123456789")
+			boundary := h.store("boundary", missing)
+			if tt.shallow {
+				boundary = h.store("boundary", h.store("uncloned"))
+				err := h.repo.repo.Storer.SetShallow([]plumbing.Hash{boundary})
+				if err != nil {
+					t.Fatalf("failed to mark commit as shallow: %v", err)
+				}
+			}
+			h.commits["boundary"] = boundary
+			h.commit("local", "boundary")
+			h.commit("a", "boundary")
+			h.commit("upstream", "a")
+
+			ahead, behind, err := h.repo.AheadBehind(h.commits["local"], h.commits["upstream"])
+			if err != nil {
+				t.Fatalf("AheadBehind() returned error: %v", err)
+			}
+			if ahead != 1 || behind != 2 {
+				t.Errorf("AheadBehind() = %d, %d, want 1, 2", ahead, behind)
+			}
+			base, err := h.repo.MergeBase(h.commits["local"], h.commits["upstream"])
+			if err != nil {
+				t.Fatalf("MergeBase() returned error: %v", err)
+			}
+			if base != boundary {
+				t.Errorf("MergeBase() = %s, want %s", base, boundary)
+			}
+		})
+	}
+}
+
+// TestAheadBehindMatchesFullWalk compares AheadBehind with the commits reachable from each side, over random histories
+func TestAheadBehindMatchesFullWalk(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for i := range 20 {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			h := newHistory(t)
+			names := []string{"c0"}
+			h.commit("c0")
+			for j := 1; j < 60; j++ {
+				name := fmt.Sprintf("c%d", j)
+				parents := []string{names[random.Intn(len(names))]}
+				if random.Intn(4) == 0 {
+					parents = append(parents, names[random.Intn(len(names))])
+				}
+				h.commit(name, parents...)
+				names = append(names, name)
+			}
+			local := h.commits[names[random.Intn(len(names))]]
+			upstream := h.commits[names[random.Intn(len(names))]]
+
+			ahead, behind, err := h.repo.AheadBehind(local, upstream)
+			if err != nil {
+				t.Fatalf("AheadBehind() returned error: %v", err)
+			}
+			localCommits := reachable(t, h.repo, local)
+			upstreamCommits := reachable(t, h.repo, upstream)
+			wantAhead, wantBehind := 0, 0
+			for hash := range localCommits {
+				if !upstreamCommits[hash] {
+					wantAhead++
+				}
+			}
+			for hash := range upstreamCommits {
+				if !localCommits[hash] {
+					wantBehind++
+				}
+			}
+			if ahead != wantAhead || behind != wantBehind {
+				t.Errorf("AheadBehind() = %d, %d, want %d, %d", ahead, behind, wantAhead, wantBehind)
+			}
+		})
+	}
+}
+
+// reachable returns every commit reachable from the given commit, including itself
+func reachable(t *testing.T, r *Repository, hash plumbing.Hash) map[plumbing.Hash]bool {
+	t.Helper()
+
+	commit, err := r.repo.CommitObject(hash)
+	if err != nil {
+		t.Fatalf("failed to read commit %q: %v", hash, err)
+	}
+	commits := map[plumbing.Hash]bool{}
+	err = object.NewCommitPreorderIter(commit, nil, nil).ForEach(func(c *object.Commit) error {
+		commits[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk history of commit %q: %v", hash, err)
+	}
+	return commits
+}
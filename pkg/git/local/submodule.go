@@ -0,0 +1,131 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+)
+
+// Submodule describes a submodule registered in a worktree's .gitmodules file
+type Submodule struct {
+	// Name is the name the submodule is registered under
+	Name string
+	// Path is the absolute path to the submodule's checkout within the worktree
+	Path string
+	// URL is the URL the submodule is cloned from, with relative URLs resolved against the worktree's default remote
+	URL string
+}
+
+// Submodules lists the submodules registered in the worktree at path, without initializing them. The worktree need not
+// belong to the repository: it may also be the checkout of one of its submodules
+func (r *Repository) Submodules(path string) ([]Submodule, error) {
+	repo, worktree, err := openSubmoduleWorktree(path)
+	if err != nil {
+		return nil, err
+	}
+	submodules, err := worktree.Submodules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read submodules of worktree %q: %w", path, err)
+	}
+
+	listed := make([]Submodule, 0, len(submodules))
+	for _, submodule := range submodules {
+		config := submodule.Config()
+		moduleURL, err := repo.resolveSubmoduleURL(config.URL)
+		if err != nil {
+			return nil, err
+		}
+		listed = append(listed, Submodule{
+			Name: config.Name,
+			Path: filepath.Join(path, filepath.FromSlash(config.Path)),
+			URL:  moduleURL,
+		})
+	}
+	return listed, nil
+}
+
+// UpdateSubmodule initializes the submodule of the given name registered in the worktree at path, if needed, then
+// clones or fetches it, authenticating with auth, and checks out the commit the worktree records for it. Nested
+// submodules are left untouched. Updating stops if ctx is cancelled.
+//
+// As with git, the submodule's repository is stored within the worktree's own git directory - under modules/ - so it
+// is not shared with any other worktree
+func (r *Repository) UpdateSubmodule(ctx context.Context, path, name string, auth transport.AuthMethod) error {
+	repo, worktree, err := openSubmoduleWorktree(path)
+	if err != nil {
+		return err
+	}
+	submodule, err := worktree.Submodule(name)
+	if err != nil {
+		return fmt.Errorf("failed to find submodule %q in worktree %q: %w", name, path, err)
+	}
+	config := submodule.Config()
+	config.URL, err = repo.resolveSubmoduleURL(config.URL)
+	if err != nil {
+		return err
+	}
+
+	// Initializing a submodule records it in the repository's config
+	metadataMu.Lock()
+	err = submodule.Init()
+	metadataMu.Unlock()
+	if err != nil && !errors.Is(err, git.ErrSubmoduleAlreadyInitialized) {
+		return fmt.Errorf("failed to initialize submodule %q in worktree %q: %w", name, path, err)
+	}
+
+	err = submodule.UpdateContext(ctx, &git.SubmoduleUpdateOptions{Auth: auth})
+	if err != nil {
+		return fmt.Errorf("failed to update submodule %q in worktree %q: %w", name, path, err)
+	}
+	return nil
+}
+
+// openSubmoduleWorktree opens the worktree at path - which may be a linked worktree, or a submodule's checkout - so
+// that its submodules are read from its own index, and relative submodule URLs resolved against its own remotes
+func openSubmoduleWorktree(path string) (*Repository, *git.Worktree, error) {
+	repo, err := NewRepository(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	worktree, err := repo.repo.Worktree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open worktree %q: %w", path, err)
+	}
+	return repo, worktree, nil
+}
+
+// resolveSubmoduleURL resolves a submodule URL relative to the repository's default remote - such as '../lib.git' -
+// as git does. go-git only resolves them against a remote named 'origin', which the grove's remote may not be. Other
+// URLs are returned as is
+func (r *Repository) resolveSubmoduleURL(moduleURL string) (string, error) {
+	if !strings.HasPrefix(moduleURL, "./") && !strings.HasPrefix(moduleURL, "../") {
+		return moduleURL, nil
+	}
+	remote, err := r.DefaultRemote()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve relative submodule URL %q: %w", moduleURL, err)
+	}
+	base, err := r.RemoteURL(remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve relative submodule URL %q: %w", moduleURL, err)
+	}
+
+	parsed, err := url.Parse(base)
+	if err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		parsed.Path = path.Join(parsed.Path, moduleURL)
+		return parsed.String(), nil
+	}
+	// An scp-like URL, such as 'git@host:org/repo.git'
+	host, repoPath, found := strings.Cut(base, ":")
+	if found {
+		return host + ":" + path.Join(repoPath, moduleURL), nil
+	}
+	return path.Join(base, moduleURL), nil
+}
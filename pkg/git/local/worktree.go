@@ -0,0 +1,318 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// MoveWorktree relocates the worktree rooted at from to the path to, updating the repository's worktree
+// metadata to match. The parent directory of to must already exist, and to itself must not.
+//
+// When the main worktree is moved, the .git txt file of every linked worktree is rewritten to reference the
+// relocated .git/ directory. Because the Repository was opened from the main worktree's previous location,
+// it should not be used for further operations after moving the main worktree
+func (r *Repository) MoveWorktree(from, to string) error {
+	_, err := os.Stat(to)
+	if err == nil {
+		return fmt.Errorf("%q already exists", to)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check %q: %w", to, err)
+	}
+
+	worktrees, err := r.Worktrees()
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	from = filepath.Clean(from)
+	for _, worktree := range worktrees {
+		if filepath.Clean(worktree.Path) != from {
+			continue
+		}
+
+		err = os.Rename(from, to)
+		if err != nil {
+			return fmt.Errorf("failed to move %q to %q: %w", from, to, err)
+		}
+
+		if worktree.Main {
+			return relinkWorktrees(GitPath(to), worktrees[1:])
+		}
+
+		// A linked worktree's administrative directory records the location of its .git txt file
+		gitdirPath := filepath.Join(worktree.GitDir, "gitdir")
+		err = os.WriteFile(gitdirPath, []byte(GitPath(to)+"\n"), 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to update %q: %w", gitdirPath, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%q is not a worktree of this repository", from)
+}
+
+// relinkWorktrees rewrites the .git txt file of each linked worktree so that it references its administrative
+// directory within the .git/ directory at mainGitPath
+func relinkWorktrees(mainGitPath string, linked []Worktree) error {
+	for _, worktree := range linked {
+		adminDir := filepath.Join(mainGitPath, linkedWorktreesDir, filepath.Base(worktree.GitDir))
+		err := WriteGitFile(worktree.Path, adminDir)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RepairWorktrees updates the locations recorded for each linked worktree, once the directory from - containing the
+// repository - has been moved to to, similar to 'git worktree repair'. The repository must have been opened from its
+// new location.
+//
+// Linked worktrees within from are assumed to have moved along with it, so both their administrative directory and
+// .git txt file are updated. Those outside of it have only their .git txt file relinked to the moved repository
+func (r *Repository) RepairWorktrees(from, to string) error {
+	worktrees, err := r.Worktrees()
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	for _, worktree := range worktrees {
+		if worktree.Main {
+			continue
+		}
+
+		path := filepath.Clean(worktree.Path)
+		rel, err := filepath.Rel(from, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			path = filepath.Join(to, rel)
+			gitdirPath := filepath.Join(worktree.GitDir, "gitdir")
+			err = os.WriteFile(gitdirPath, []byte(GitPath(path)+"\n"), 0o644)
+			if err != nil {
+				return fmt.Errorf("failed to update %q: %w", gitdirPath, err)
+			}
+		}
+
+		err = WriteGitFile(path, worktree.GitDir)
+		if err != nil {
+			return fmt.Errorf("failed to relink worktree %q: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// LockWorktree locks the linked worktree rooted at path, recording the given reason, which may be empty.
+// Locked worktrees are protected from being pruned, which is useful when they reside on removable media
+// or network mounts that are not always available.
+//
+// The main worktree cannot be locked
+func (r *Repository) LockWorktree(path, reason string) error {
+	worktree, err := r.linkedWorktree(path)
+	if err != nil {
+		return err
+	}
+	if worktree.Locked {
+		return fmt.Errorf("worktree %q is already locked", path)
+	}
+
+	lockPath := filepath.Join(worktree.GitDir, lockFile)
+	content := ""
+	if reason != "" {
+		content = reason + "\n"
+	}
+	err = os.WriteFile(lockPath, []byte(content), 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write %q: %w", lockPath, err)
+	}
+	return nil
+}
+
+// UnlockWorktree unlocks the linked worktree rooted at path
+func (r *Repository) UnlockWorktree(path string) error {
+	worktree, err := r.linkedWorktree(path)
+	if err != nil {
+		return err
+	}
+	if !worktree.Locked {
+		return fmt.Errorf("worktree %q is not locked", path)
+	}
+
+	lockPath := filepath.Join(worktree.GitDir, lockFile)
+	err = os.Remove(lockPath)
+	if err != nil {
+		return fmt.Errorf("failed to remove %q: %w", lockPath, err)
+	}
+	return nil
+}
+
+// RemoveWorktree deletes the linked worktree rooted at path, along with its administrative directory. The branch it
+// had checked out is kept.
+//
+// The main worktree and locked worktrees cannot be removed
+func (r *Repository) RemoveWorktree(path string) error {
+	worktree, err := r.linkedWorktree(path)
+	if err != nil {
+		return err
+	}
+	if worktree.Locked {
+		return fmt.Errorf("worktree %q is locked", path)
+	}
+
+	err = os.RemoveAll(worktree.Path)
+	if err != nil {
+		return fmt.Errorf("failed to remove %q: %w", worktree.Path, err)
+	}
+	err = os.RemoveAll(worktree.GitDir)
+	if err != nil {
+		return fmt.Errorf("failed to remove %q: %w", worktree.GitDir, err)
+	}
+	return nil
+}
+
+// linkedWorktree finds the linked worktree rooted at path. An error is returned if path refers to the main worktree
+func (r *Repository) linkedWorktree(path string) (Worktree, error) {
+	worktrees, err := r.Worktrees()
+	if err != nil {
+		return Worktree{}, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	path = filepath.Clean(path)
+	for _, worktree := range worktrees {
+		if filepath.Clean(worktree.Path) != path {
+			continue
+		}
+		if worktree.Main {
+			return Worktree{}, fmt.Errorf("%q is the main worktree", path)
+		}
+		return worktree, nil
+	}
+	return Worktree{}, fmt.Errorf("%q is not a worktree of this repository", path)
+}
+
+// ResetWorktree discards every uncommitted change in the worktree rooted at path: the worktree is hard-reset to the
+// given commit - moving its checked out branch, if any - and untracked files and directories are removed. Ignored
+// files are left in place
+func (r *Repository) ResetWorktree(path string, commit plumbing.Hash) error {
+	worktreeRepo, wt, err := openWorktree(path)
+	if err != nil {
+		return err
+	}
+
+	// go-git's hard reset deletes every file which isn't tracked - ignored or not - so the reset is limited to the files
+	// tracked either before or after it, leaving untracked files to be cleaned, and ignored files in place
+	files, err := trackedFiles(worktreeRepo, commit)
+	if err != nil {
+		return fmt.Errorf("failed to list tracked files of worktree %q: %w", path, err)
+	}
+	err = wt.Reset(&git.ResetOptions{
+		Commit: commit,
+		Mode:   git.HardReset,
+		Files:  files,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reset worktree %q to %q: %w", path, commit, err)
+	}
+	err = wt.Clean(&git.CleanOptions{Dir: true})
+	if err != nil {
+		return fmt.Errorf("failed to remove untracked files from worktree %q: %w", path, err)
+	}
+	return nil
+}
+
+// trackedFiles lists the path of every file in the worktree's index, along with every file in the given commit
+func trackedFiles(worktreeRepo *git.Repository, commit plumbing.Hash) ([]string, error) {
+	idx, err := worktreeRepo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(idx.Entries))
+	seen := map[string]bool{}
+	for _, entry := range idx.Entries {
+		files = append(files, entry.Name)
+		seen[entry.Name] = true
+	}
+
+	commitObject, err := worktreeRepo.CommitObject(commit)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commitObject.Tree()
+	if err != nil {
+		return nil, err
+	}
+	err = tree.Files().ForEach(func(file *object.File) error {
+		if !seen[file.Name] {
+			files = append(files, file.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// ErrUncommittedChanges is returned when checking out another branch or commit in a worktree would discard its
+// uncommitted changes to tracked files
+var ErrUncommittedChanges = errors.New("worktree has uncommitted changes which would be lost: commit or stash them first, or force the checkout")
+
+// CheckoutOptions configures what CheckoutWorktree checks out
+type CheckoutOptions struct {
+	// Branch is the short name of the branch to check out. If empty, Commit is checked out with a detached HEAD
+	Branch string
+	// Create creates Branch at Commit before checking it out
+	Create bool
+	// Commit is the commit checked out with a detached HEAD, or at which Branch is created
+	Commit plumbing.Hash
+	// Force discards any uncommitted changes to tracked files, rather than returning ErrUncommittedChanges
+	Force bool
+}
+
+// CheckoutWorktree checks out a branch or commit in the worktree rooted at path, updating its files to match, as with
+// 'git checkout'. Untracked files are left in place
+func (r *Repository) CheckoutWorktree(path string, opts CheckoutOptions) error {
+	worktreeRepo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		return fmt.Errorf("failed to open worktree %q: %w", path, err)
+	}
+	wt, err := worktreeRepo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree %q: %w", path, err)
+	}
+
+	if !opts.Force {
+		status, err := wt.Status()
+		if err != nil {
+			return fmt.Errorf("failed to determine status of worktree %q: %w", path, err)
+		}
+		for _, fileStatus := range status {
+			if !isUntracked(fileStatus) {
+				return ErrUncommittedChanges
+			}
+		}
+	}
+
+	checkoutOpts := &git.CheckoutOptions{
+		Hash:  opts.Commit,
+		Force: opts.Force,
+	}
+	if opts.Branch != "" {
+		checkoutOpts.Branch = plumbing.NewBranchReferenceName(opts.Branch)
+		checkoutOpts.Create = opts.Create
+		if !opts.Create {
+			checkoutOpts.Hash = plumbing.ZeroHash
+		}
+	}
+	err = wt.Checkout(checkoutOpts)
+	if err != nil {
+		return fmt.Errorf("failed to check out in worktree %q: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+)
+
+// tokenCLI describes a forge's command-line client which is able to print the access token it has been
+// authenticated with
+type tokenCLI struct {
+	// command is the name of the client's executable
+	command string
+	// args are the arguments which cause the client to print its token for host
+	args func(host string) []string
+	// username is the user the forge expects to be given alongside the token
+	username string
+}
+
+// tokenCLIs maps the hosts of well-known forges to the client which can supply a token for them
+var tokenCLIs = map[string]tokenCLI{
+	"github.com": {
+		command:  "gh",
+		args:     func(host string) []string { return []string{"auth", "token", "--hostname", host} },
+		username: "x-access-token",
+	},
+	"gitlab.com": {
+		command:  "glab",
+		args:     func(host string) []string { return []string{"config", "get", "token", "--host", host} },
+		username: "oauth2",
+	},
+}
+
+// cliTokenAuth asks the forge's command-line client - 'gh' for GitHub, or 'glab' for GitLab - for the token it has been
+// authenticated with, to authenticate against the given HTTP(S) URL.
+//
+// If the URL's host is not a known forge, its client is not installed, or the client has not been authenticated, nil
+// is returned without an error so that callers may fall back to another authentication source
+func cliTokenAuth(remoteURL string) (*http.BasicAuth, error) {
+	parsed, err := url.Parse(remoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", remoteURL, err)
+	}
+	host := strings.ToLower(parsed.Hostname())
+	cli, found := tokenCLIs[host]
+	if !found {
+		return nil, nil
+	}
+
+	path, err := exec.LookPath(cli.command)
+	if err != nil {
+		// The client is not installed
+		return nil, nil
+	}
+
+	output, err := exec.Command(path, cli.args(host)...).Output()
+	if err != nil {
+		exitErr := &exec.ExitError{}
+		if errors.As(err, &exitErr) {
+			// The client exits non-zero when it has not been authenticated with the host
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to execute %q: %w", cli.command, err)
+	}
+
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return nil, nil
+	}
+	return &http.BasicAuth{
+		Username: cli.username,
+		Password: token,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package remote
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+)
+
+// credentialHelperAuth asks git's configured credential helper (via 'git credential fill') for the
+// credentials associated with the given HTTP(S) URL.
+//
+// If git is not installed, or no helper is able to provide both a username and password for the URL,
+// nil is returned without an error so that callers may fall back to another authentication source
+func credentialHelperAuth(remoteURL string) (*http.BasicAuth, error) {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		// git is not installed - no credential helper can be consulted
+		return nil, nil
+	}
+
+	parsed, err := url.Parse(remoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", remoteURL, err)
+	}
+
+	// The credential protocol is a series of key=value lines terminated by a blank line.
+	// See https://git-scm.com/docs/git-credential#IOFMT
+	input := &bytes.Buffer{}
+	fmt.Fprintf(input, "protocol=%s\n", parsed.Scheme)
+	fmt.Fprintf(input, "host=%s\n", parsed.Host)
+	if path := strings.TrimPrefix(parsed.Path, "/"); path != "" {
+		fmt.Fprintf(input, "path=%s\n", path)
+	}
+	if parsed.User != nil && parsed.User.Username() != "" {
+		fmt.Fprintf(input, "username=%s\n", parsed.User.Username())
+	}
+	input.WriteString("\n")
+
+	cmd := exec.Command(gitPath, "credential", "fill")
+	cmd.Stdin = input
+	// Prevent git from prompting on its own when no helper yields credentials - we prompt ourselves instead
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	output, err := cmd.Output()
+	if err != nil {
+		exitErr := &exec.ExitError{}
+		if errors.As(err, &exitErr) {
+			// git exits non-zero when no helper has credentials and prompting is disabled
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to execute 'git credential fill': %w", err)
+	}
+
+	auth := &http.BasicAuth{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "username":
+			auth.Username = value
+		case "password":
+			auth.Password = value
+		}
+	}
+
+	if auth.Username == "" || auth.Password == "" {
+		return nil, nil
+	}
+	return auth, nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package remote
+
+// freeSpace returns -1, as the space available on disk cannot be determined on this platform
+func freeSpace(_ string) int64 {
+	return -1
+}
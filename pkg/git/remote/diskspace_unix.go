@@ -0,0 +1,25 @@
+//go:build unix
+
+package remote
+
+import (
+	"path/filepath"
+	"syscall"
+)
+
+// freeSpace returns the number of bytes available to unprivileged users on the disk holding path, or -1 if it cannot be
+// determined. If path no longer exists, its closest existing parent is used
+func freeSpace(path string) int64 {
+	for {
+		stat := syscall.Statfs_t{}
+		err := syscall.Statfs(path, &stat)
+		if err == nil {
+			return int64(stat.Bavail) * int64(stat.Bsize)
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return -1
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,96 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+)
+
+// fallbackDefaultBranch is the branch created by the first commit to an empty repository when init.defaultBranch is
+// not configured, as with git
+const fallbackDefaultBranch = "master"
+
+// IsEmpty reports whether the remote repository has no refs at all, as when it has just been created
+func (r *Repository) IsEmpty(ctx context.Context) (bool, error) {
+	auth, err := r.authMethod(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to authenticate with %q: %w", r.URL, err)
+	}
+	_, err = r.list(ctx, auth)
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to list refs for %q: %w", r.URL, Classify(err))
+	}
+	return false, nil
+}
+
+// emptyDefaultBranch determines the branch created by the first commit to an empty repository: init.defaultBranch from
+// the user's git configuration, or 'master' if unset, as with git
+func emptyDefaultBranch() string {
+	cfg, err := config.LoadConfig(config.GlobalScope)
+	if err == nil && cfg.Init.DefaultBranch != "" {
+		return plumbing.ReferenceName(cfg.Init.DefaultBranch).Short()
+	}
+	return fallbackDefaultBranch
+}
+
+// cloneEmpty initializes a repository at path for the empty remote, as 'git clone' does: the remote is configured,
+// and HEAD points to the unborn branch, which tracks the same branch on the remote once the first commit is pushed
+func (r *Repository) cloneEmpty(ctx context.Context, path string) error {
+	branch := r.opts.Branch
+	if branch == "" {
+		var err error
+		branch, err = r.DefaultBranch(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	branchRef := plumbing.NewBranchReferenceName(branch)
+
+	repo, err := git.PlainInit(path, r.opts.Bare || r.opts.Mirror, git.WithDefaultBranch(branchRef))
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository at %q: %w", path, err)
+	}
+	remoteName := r.opts.RemoteName
+	if remoteName == "" {
+		remoteName = DefaultRemoteName
+	}
+	remoteConfig := &config.RemoteConfig{
+		Name: remoteName,
+		URLs: []string{r.URL},
+	}
+	if r.opts.Mirror {
+		remoteConfig.Fetch = []config.RefSpec{mirrorRefSpec}
+		remoteConfig.Mirror = true
+	}
+	_, err = repo.CreateRemote(remoteConfig)
+	if err != nil {
+		return fmt.Errorf("failed to add remote %q: %w", remoteName, err)
+	}
+	// Mirrors have no remote-tracking branches for the unborn branch to track
+	if r.opts.Mirror {
+		return nil
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read repository configuration: %w", err)
+	}
+	cfg.Branches[branch] = &config.Branch{
+		Name:   branch,
+		Remote: remoteName,
+		Merge:  branchRef,
+	}
+	err = repo.SetConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to configure upstream of branch %q: %w", branch, err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package remote
+
+import (
+	"context"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v6"
+)
+
+func TestEmptyDefaultBranch(t *testing.T) {
+	tests := []struct {
+		name string
+		// gitConfig is the content of the user's global git configuration, which is absent if empty
+		gitConfig string
+		want      string
+	}{
+		{name: "no configuration", want: "master"},
+		{name: "no default branch configured", gitConfig: "[user]\n\tname = grove\n", want: "master"},
+		{name: "default branch configured", gitConfig: "[init]\n\tdefaultBranch = trunk\n", want: "trunk"},
+		{name: "full reference configured", gitConfig: "[init]\n\tdefaultBranch = refs/heads/main\n", want: "main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGitConfig(t, tt.gitConfig)
+
+			got := emptyDefaultBranch()
+			if got != tt.want {
+				t.Errorf("emptyDefaultBranch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultBranchOfEmptyRemote(t *testing.T) {
+	tests := []struct {
+		name      string
+		gitConfig string
+		want      string
+	}{
+		{name: "no default branch configured", want: "master"},
+		{name: "default branch configured", gitConfig: "[init]\n\tdefaultBranch = trunk\n", want: "trunk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setGitConfig(t, tt.gitConfig)
+			url := serveEmptyRepository(t)
+			repository, err := NewRepository(url)
+			if err != nil {
+				t.Fatalf("NewRepository(%q) returned error: %v", url, err)
+			}
+
+			empty, err := repository.IsEmpty(context.Background())
+			if err != nil {
+				t.Fatalf("IsEmpty() returned error: %v", err)
+			}
+			if !empty {
+				t.Error("IsEmpty() = false, want true")
+			}
+			got, err := repository.DefaultBranch(context.Background())
+			if err != nil {
+				t.Fatalf("DefaultBranch() returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DefaultBranch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// serveEmptyRepository serves a new, empty repository over HTTP with 'git http-backend' for the rest of the test,
+// returning its URL. The test is skipped if git is not installed
+func serveEmptyRepository(t *testing.T) string {
+	t.Helper()
+
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	_, err = git.PlainInit(filepath.Join(root, "empty.git"), true)
+	if err != nil {
+		t.Fatalf("failed to initialize repository: %v", err)
+	}
+
+	server := httptest.NewServer(&cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	})
+	t.Cleanup(server.Close)
+	return server.URL + "/empty.git"
+}
+
+// setGitConfig points the user's home directory to a temporary one for the rest of the test, holding a global git
+// configuration with the given content, unless empty
+func setGitConfig(t *testing.T, content string) {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	if content == "" {
+		return
+	}
+	err := os.WriteFile(filepath.Join(home, ".gitconfig"), []byte(content), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write git configuration: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/tnierman/git-grove/pkg/output"
+)
+
+var (
+	// ErrAuthFailed is returned when the remote requires credentials which were not provided, or rejects those which were
+	ErrAuthFailed = errors.New("authentication failed")
+	// ErrUnreachable is returned when the remote cannot be contacted, such as when its host cannot be resolved or
+	// refuses the connection
+	ErrUnreachable = errors.New("remote unreachable")
+	// ErrNoDefaultBranch is returned when the remote does not advertise a HEAD pointing to a branch, so that its
+	// default branch cannot be determined
+	ErrNoDefaultBranch = errors.New("remote has no default branch")
+)
+
+// Classify wraps the given error from communicating with a remote with ErrAuthFailed or ErrUnreachable, if it
+// belongs to either category, so that callers can tell a rejected password from a connectivity issue with errors.Is.
+// Other errors are returned as is
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	if isAuthError(err) {
+		return fmt.Errorf("%w: %w", ErrAuthFailed, err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrUnreachable, err)
+	}
+	return err
+}
+
+// isAuthError determines whether the given error indicates that the remote rejected, or required, credentials
+func isAuthError(err error) bool {
+	if errors.Is(err, transport.ErrAuthenticationRequired) || errors.Is(err, transport.ErrAuthorizationFailed) {
+		return true
+	}
+	// The SSH client reports authentication failures during the handshake only by message
+	return strings.Contains(err.Error(), "unable to authenticate")
+}
+
+// NoSpaceError is returned when cloning fails because the disk holding the clone has run out of space
+type NoSpaceError struct {
+	// Path is where the repository was being cloned to
+	Path string
+	// Free is the number of bytes available on the disk holding Path once cloning failed, or -1 if unknown
+	Free int64
+	// Err is the underlying write failure
+	Err error
+}
+
+func (e *NoSpaceError) Error() string {
+	if e.Free < 0 {
+		return fmt.Sprintf("no space left on device while cloning to %q: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("no space left on device while cloning to %q (%s free): %v", e.Path, output.FormatBytes(e.Free), e.Err)
+}
+
+func (e *NoSpaceError) Unwrap() error {
+	return e.Err
+}
+
+// isNoSpace determines whether the given error was caused by the disk running out of space. Some errors from go-git
+// lose their underlying error, so the message is checked as well
+func isNoSpace(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || strings.Contains(err.Error(), "no space left on device")
+}
@@ -0,0 +1,28 @@
+package remote
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+)
+
+// hostCredentials caches the credentials obtained for each HTTP(S) host, keyed by credentialHost, so that the user is
+// prompted at most once per host, and each host is only ever sent the credentials obtained for it - even when remotes
+// on several hosts are accessed in the same run. The mutex is held while credentials are obtained, so that prompts for
+// concurrent operations are never interleaved
+var (
+	hostCredentialsMu sync.Mutex
+	hostCredentials   = map[string]transport.AuthMethod{}
+)
+
+// credentialHost returns the key the credentials for the given HTTP(S) URL are cached under: its scheme, host, and
+// port. As with git, credentials are shared by every repository on the same host
+func credentialHost(remoteURL string) string {
+	parsed, err := url.Parse(remoteURL)
+	if err != nil || parsed.Host == "" {
+		return remoteURL
+	}
+	return strings.ToLower(parsed.Scheme + "://" + parsed.Host)
+}
@@ -0,0 +1,143 @@
+package remote
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v6/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v6/plumbing/transport/ssh/knownhosts"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+// HostKeyChecking mirrors OpenSSH's StrictHostKeyChecking option, determining how unknown or changed
+// SSH host keys are treated
+type HostKeyChecking string
+
+const (
+	// HostKeyCheckingStrict refuses to connect to hosts whose key is not already present in known_hosts
+	HostKeyCheckingStrict HostKeyChecking = "yes"
+	// HostKeyCheckingAcceptNew adds the keys of previously-unknown hosts to known_hosts, but refuses to
+	// connect to hosts whose key has changed
+	HostKeyCheckingAcceptNew HostKeyChecking = "accept-new"
+	// HostKeyCheckingOff accepts any host key without verification. This is dangerous, as it leaves the
+	// connection open to man-in-the-middle attacks
+	HostKeyCheckingOff HostKeyChecking = "no"
+
+	knownHostsPermissions    = 0o600
+	knownHostsDirPermissions = 0o700
+)
+
+// ParseHostKeyChecking converts the given StrictHostKeyChecking value into a HostKeyChecking mode.
+// An empty value results in HostKeyCheckingStrict
+func ParseHostKeyChecking(value string) (HostKeyChecking, error) {
+	switch HostKeyChecking(value) {
+	case "", HostKeyCheckingStrict:
+		return HostKeyCheckingStrict, nil
+	case HostKeyCheckingAcceptNew, HostKeyCheckingOff:
+		return HostKeyChecking(value), nil
+	}
+	return "", fmt.Errorf("invalid host key checking mode %q (expected one of %q, %q, or %q)", value, HostKeyCheckingStrict, HostKeyCheckingAcceptNew, HostKeyCheckingOff)
+}
+
+// hostKeyCallback builds the callback used to verify the remote's host key, according to the configured
+// HostKeyChecking mode and KnownHostsFile
+func (a *SSHAuthentication) hostKeyCallback() (cryptossh.HostKeyCallback, error) {
+	if a.HostKeyChecking == HostKeyCheckingOff {
+		return cryptossh.InsecureIgnoreHostKey(), nil
+	}
+
+	files := []string{}
+	if a.KnownHostsFile != "" {
+		files = append(files, a.KnownHostsFile)
+	}
+
+	if a.HostKeyChecking == HostKeyCheckingAcceptNew {
+		// New hosts must be written somewhere: ensure the file exists before loading it
+		path, err := a.knownHostsPath()
+		if err != nil {
+			return nil, err
+		}
+		err = ensureFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	verify, err := ssh.NewKnownHostsCallback(files...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known_hosts: %w", err)
+	}
+
+	callback := func(hostname string, remote net.Addr, key cryptossh.PublicKey) error {
+		err := verify(hostname, remote, key)
+		if err == nil {
+			return nil
+		}
+
+		if knownhosts.IsHostKeyChanged(err) {
+			return fmt.Errorf("host key for %q does not match the key recorded in known_hosts - the host key may have been rotated, or someone may be intercepting the connection: %w", hostname, err)
+		}
+		if !knownhosts.IsHostUnknown(err) {
+			return err
+		}
+
+		if a.HostKeyChecking != HostKeyCheckingAcceptNew {
+			return fmt.Errorf("host %q is not present in known_hosts - connect to it once with ssh, or retry with --strict-host-key-checking=%s to trust it automatically: %w", hostname, HostKeyCheckingAcceptNew, err)
+		}
+		return a.addKnownHost(hostname, remote, key)
+	}
+	return callback, nil
+}
+
+// addKnownHost records the given host's key in known_hosts
+func (a *SSHAuthentication) addKnownHost(hostname string, remote net.Addr, key cryptossh.PublicKey) error {
+	path, err := a.knownHostsPath()
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, knownHostsPermissions)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	defer func() {
+		closeErr := file.Close()
+		if closeErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to close file %q: %v\n", path, closeErr)
+		}
+	}()
+
+	err = knownhosts.WriteKnownHost(file, hostname, remote, key)
+	if err != nil {
+		return fmt.Errorf("failed to add host %q to %q: %w", hostname, path, err)
+	}
+	fmt.Fprintf(os.Stderr, "warning: permanently added %q to the list of known hosts in %q\n", hostname, path)
+	return nil
+}
+
+// knownHostsPath returns the known_hosts file new hosts are written to: the KnownHostsFile, if set, or ~/.ssh/known_hosts
+func (a *SSHAuthentication) knownHostsPath() (string, error) {
+	if a.KnownHostsFile != "" {
+		return a.KnownHostsFile, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".ssh", "known_hosts"), nil
+}
+
+// ensureFile creates an empty file at the given path, along with any missing parent directories, if it does not already exist
+func ensureFile(path string) error {
+	err := os.MkdirAll(filepath.Dir(path), knownHostsDirPermissions)
+	if err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", filepath.Dir(path), err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, knownHostsPermissions)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %w", path, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,141 @@
+package remote
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+)
+
+const (
+	// netrcEnv is the environment variable used to override the location of the user's .netrc file
+	netrcEnv = "NETRC"
+
+	// netrcFile is the default name of the .netrc file within the user's home directory
+	netrcFile = ".netrc"
+)
+
+// netrcAuth looks up the login and password for the given HTTP(S) URL's host in the user's .netrc file.
+//
+// The file is read from $NETRC, if set, or ~/.netrc otherwise. If the file does not exist, or contains no
+// usable entry for the host, nil is returned without an error so that callers may fall back to another
+// authentication source
+func netrcAuth(remoteURL string) (*http.BasicAuth, error) {
+	parsed, err := url.Parse(remoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", remoteURL, err)
+	}
+
+	path := os.Getenv(netrcEnv)
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine home directory: %w", err)
+		}
+		path = filepath.Join(home, netrcFile)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	defer func() {
+		closeErr := file.Close()
+		if closeErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to close file %q: %v\n", path, closeErr)
+		}
+	}()
+
+	entry, err := parseNetrc(bufio.NewScanner(file), parsed.Hostname())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", path, err)
+	}
+	if entry == nil || entry.Username == "" || entry.Password == "" {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// parseNetrc scans the .netrc tokens provided by scanner for the entry matching host. If no 'machine'
+// entry matches, the 'default' entry is returned, if one exists. Otherwise, nil is returned
+func parseNetrc(scanner *bufio.Scanner, host string) (*http.BasicAuth, error) {
+	var (
+		match    *http.BasicAuth
+		fallback *http.BasicAuth
+		current  *http.BasicAuth
+		inMacro  bool
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// Macro definitions run until the next blank line and are otherwise ignored
+		if inMacro {
+			if strings.TrimSpace(line) == "" {
+				inMacro = false
+			}
+			continue
+		}
+
+		tokens := strings.Fields(line)
+		for i := 0; i < len(tokens); i++ {
+			token := tokens[i]
+			if strings.HasPrefix(token, "#") {
+				// Comment runs until the end of the line
+				break
+			}
+
+			switch token {
+			case "machine":
+				i++
+				if i >= len(tokens) {
+					return nil, fmt.Errorf("'machine' keyword is missing a host name")
+				}
+				current = &http.BasicAuth{}
+				if tokens[i] == host && match == nil {
+					match = current
+				}
+			case "default":
+				current = &http.BasicAuth{}
+				if fallback == nil {
+					fallback = current
+				}
+			case "login", "password", "account":
+				i++
+				if i >= len(tokens) {
+					return nil, fmt.Errorf("%q keyword is missing a value", token)
+				}
+				if current == nil {
+					return nil, fmt.Errorf("%q keyword found before any 'machine' or 'default' entry", token)
+				}
+				switch token {
+				case "login":
+					current.Username = tokens[i]
+				case "password":
+					current.Password = tokens[i]
+				}
+			case "macdef":
+				inMacro = true
+				i = len(tokens)
+			default:
+				return nil, fmt.Errorf("unexpected token %q", token)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if match != nil {
+		return match, nil
+	}
+	return fallback, nil
+}
@@ -0,0 +1,112 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+)
+
+// Protocol identifies the transport protocol used to access a remote
+type Protocol string
+
+const (
+	// ProtocolHTTP accesses the remote over HTTP or HTTPS
+	ProtocolHTTP Protocol = "http"
+	// ProtocolSSH accesses the remote over SSH, via the built-in client or an SSH command
+	ProtocolSSH Protocol = "ssh"
+	// ProtocolGit accesses the remote via the unauthenticated git daemon protocol. It is not supported by AuthMethod
+	ProtocolGit Protocol = "git"
+	// ProtocolLocal accesses a repository on the local filesystem. It is not supported by AuthMethod
+	ProtocolLocal Protocol = "local"
+	// ProtocolUnknown is returned for URLs whose protocol cannot be determined
+	ProtocolUnknown Protocol = "unknown"
+)
+
+// scpLikeURL matches scp-like SSH URLs - '[<user>@]<host>:<path>' - whose host, or bracketed IPv6 address, contains no
+// '/'. As with git, anything with a '/' before the first ':' is a local path instead
+var scpLikeURL = regexp.MustCompile(`^(?:[^@/]+@)?(\[[^\]/]+\]|[^@/:\[]+):.+$`)
+
+// schemeURL matches URLs with an explicit scheme, such as 'git://host/repo', which git never treats as scp-like
+var schemeURL = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*://`)
+
+// isSCPLike determines whether url is an scp-like SSH URL, such as 'git@host:org/repo' or 'host:org/repo'. A single
+// letter before the ':' is a Windows drive, such as 'C:/repo', rather than a host
+func isSCPLike(url string) bool {
+	if schemeURL.MatchString(url) {
+		return false
+	}
+	match := scpLikeURL.FindStringSubmatch(url)
+	return match != nil && len(match[1]) > 1
+}
+
+// DetectProtocol determines the protocol the given URL is accessed with, without regard for whether it is supported
+func DetectProtocol(url string) Protocol {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return ProtocolUnknown
+	}
+	switch endpoint.Scheme {
+	case "http", "https":
+		return ProtocolHTTP
+	case "ssh":
+		// Local paths containing a ':', such as './repo:1', are also parsed as scp-like URLs
+		if !strings.HasPrefix(url, "ssh://") && !isSCPLike(url) {
+			return ProtocolLocal
+		}
+		return ProtocolSSH
+	case "git":
+		return ProtocolGit
+	case "file":
+		return ProtocolLocal
+	}
+	return ProtocolUnknown
+}
+
+// announceAuthentication tells the user which protocol and host they are about to authenticate against, so that the
+// prompt which follows can be understood
+func announceAuthentication(protocol Protocol, url string) {
+	host := url
+	endpoint, err := transport.NewEndpoint(url)
+	if err == nil && endpoint.Hostname() != "" {
+		host = endpoint.Hostname()
+	}
+	fmt.Fprintf(os.Stderr, "authenticating via %s to %s\n", strings.ToUpper(string(protocol)), host)
+}
+
+// HTTPSURL rewrites the given SSH URL - either scp-like, such as 'git@host:org/repo', or 'ssh://' - to the HTTPS URL
+// of the same repository, such as 'https://host/org/repo'. Hosts which are aliases in ~/.ssh/config are replaced by
+// their HostName. false is returned for URLs which are not SSH
+func HTTPSURL(url string) (string, bool) {
+	if DetectProtocol(url) != ProtocolSSH {
+		return "", false
+	}
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil || endpoint.Hostname() == "" {
+		return "", false
+	}
+
+	host := endpoint.Hostname()
+	configured := sshConfig.Get(host, "HostName")
+	if configured != "" {
+		host = configured
+	}
+	return fmt.Sprintf("https://%s/%s", host, strings.TrimPrefix(endpoint.Path, "/")), true
+}
+
+// SSHURL rewrites the given HTTP(S) URL, such as 'https://host/org/repo', to the scp-like SSH URL of the same
+// repository, such as 'git@host:org/repo' - 'git' being the user SSH remotes are conventionally accessed as. Any
+// credentials or port within the URL are dropped, as they only apply to HTTP(S). false is returned for URLs which are
+// not HTTP(S)
+func SSHURL(url string) (string, bool) {
+	if DetectProtocol(url) != ProtocolHTTP {
+		return "", false
+	}
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil || endpoint.Hostname() == "" {
+		return "", false
+	}
+	return fmt.Sprintf("git@%s:%s", endpoint.Hostname(), strings.TrimPrefix(endpoint.Path, "/")), true
+}
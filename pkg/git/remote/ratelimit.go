@@ -0,0 +1,193 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+)
+
+// rateLimitChunk is the most data read or written at once by a rate-limited stream, so that transfers are throttled
+// smoothly rather than in bursts
+const rateLimitChunk = 16 * 1024
+
+// limiters throttles each direction of transfer independently
+type limiters struct {
+	read  *limiter
+	write *limiter
+}
+
+// ParseRate parses a transfer rate in bytes per second, optionally suffixed by 'k', 'm', or 'g' for kibibytes,
+// mebibytes, or gibibytes per second, as with 'curl --limit-rate'
+func ParseRate(value string) (int64, error) {
+	multiplier := int64(1)
+	number := strings.ToLower(value)
+	switch {
+	case strings.HasSuffix(number, "k"):
+		multiplier = 1024
+	case strings.HasSuffix(number, "m"):
+		multiplier = 1024 * 1024
+	case strings.HasSuffix(number, "g"):
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier != 1 {
+		number = number[:len(number)-1]
+	}
+
+	rate, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || rate <= 0 {
+		return 0, fmt.Errorf("invalid rate %q: expected a positive number of bytes per second, optionally suffixed by 'k', 'm', or 'g'", value)
+	}
+	return rate * multiplier, nil
+}
+
+// RateLimit throttles transfers to and from remotes to roughly a number of bytes per second in each direction, shared
+// by every operation it is applied to. Limiting is best-effort: it applies to the data sent over the network, but not
+// to the indexing of objects once they are received.
+//
+// HTTP(S) remotes, and SSH remotes accessed via an external SSH command (see Options.SSHCommand), are throttled. The
+// in-process SSH client is not. A nil RateLimit does not throttle anything
+type RateLimit struct {
+	limits *limiters
+	// http is the transport HTTP(S) remotes are accessed with, whose connections are throttled
+	http transport.Transport
+}
+
+// NewRateLimit creates a RateLimit of the given number of bytes per second, or nil if bytesPerSecond is not positive
+func NewRateLimit(bytesPerSecond int64) *RateLimit {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	limits := &limiters{
+		read:  newLimiter(bytesPerSecond),
+		write: newLimiter(bytesPerSecond),
+	}
+
+	// The HTTP transport must remain an *http.Transport, which go-git clones to apply TLS settings, so connections
+	// are throttled as they are dialed
+	base, ok := nethttp.DefaultTransport.(*nethttp.Transport)
+	if !ok {
+		base = &nethttp.Transport{Proxy: nethttp.ProxyFromEnvironment}
+	}
+	httpTransport := base.Clone()
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	httpTransport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		return &rateLimitedConn{Conn: conn, limits: limits}, nil
+	}
+	return &RateLimit{
+		limits: limits,
+		http:   http.NewTransport(&http.TransportOptions{Client: &nethttp.Client{Transport: httpTransport}}),
+	}
+}
+
+// Apply returns an AuthMethod which authenticates as auth does - which may be nil, for anonymous access - and
+// throttles every operation it is used for. Other operations are unaffected. If l is nil, auth is returned as is
+func (l *RateLimit) Apply(auth transport.AuthMethod) transport.AuthMethod {
+	if l == nil {
+		return auth
+	}
+	return scope(auth, func(scoped *scopedAuth) {
+		scoped.rateLimit = l
+	})
+}
+
+// limiter delays transfers so that their throughput does not exceed a rate
+type limiter struct {
+	mu   sync.Mutex
+	rate int64
+	// next is when the data transferred so far would have completed at the limited rate
+	next time.Time
+}
+
+func newLimiter(rate int64) *limiter {
+	return &limiter{rate: rate}
+}
+
+// wait blocks until n more bytes may be transferred without exceeding the rate
+func (l *limiter) wait(n int) {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	l.next = l.next.Add(time.Duration(int64(n) * int64(time.Second) / l.rate))
+	delay := l.next.Sub(now)
+	l.mu.Unlock()
+
+	time.Sleep(delay)
+}
+
+// chunk returns the most data which should be transferred at once
+func (l *limiter) chunk(n int) int {
+	return min(n, rateLimitChunk, max(int(l.rate), 1))
+}
+
+// rateLimitedConn throttles the reads and writes of a network connection
+type rateLimitedConn struct {
+	net.Conn
+	limits *limiters
+}
+
+func (c *rateLimitedConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p[:c.limits.read.chunk(len(p))])
+	c.limits.read.wait(n)
+	return n, err
+}
+
+func (c *rateLimitedConn) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		end := written + c.limits.write.chunk(len(p)-written)
+		c.limits.write.wait(end - written)
+		n, err := c.Conn.Write(p[written:end])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// rateLimitedReader throttles reads from a stream
+type rateLimitedReader struct {
+	io.Reader
+	limit *limiter
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p[:r.limit.chunk(len(p))])
+	r.limit.wait(n)
+	return n, err
+}
+
+// rateLimitedWriter throttles writes to a stream
+type rateLimitedWriter struct {
+	io.WriteCloser
+	limit *limiter
+}
+
+func (w *rateLimitedWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		end := written + w.limit.chunk(len(p)-written)
+		w.limit.wait(end - written)
+		n, err := w.WriteCloser.Write(p[written:end])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
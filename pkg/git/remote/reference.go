@@ -0,0 +1,245 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/tnierman/git-grove/pkg/git/local"
+)
+
+// referenceRefPrefix is the namespace the refs of a reference repository are copied into while cloning, so that the
+// remote only sends the objects the reference lacks
+const referenceRefPrefix = "refs/grove-reference/"
+
+// cloneWithReference clones the repository into path, borrowing every object already present in the local repository
+// at r.opts.Reference rather than retrieving it from the remote again.
+//
+// go-git cannot read objects through alternates outside of the repository, as 'git clone --reference' would use, so the
+// reference's packs are hard-linked into the new repository instead - or copied, if r.opts.Dissociate is set or the
+// reference resides on another filesystem. Either way, the reference can later be removed without affecting the clone
+func (r *Repository) cloneWithReference(ctx context.Context, path string) error {
+	auth, err := r.authMethod(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to authenticate with %q: %w", r.URL, err)
+	}
+	branch := r.opts.Branch
+	if branch == "" {
+		branch, err = r.DefaultBranch(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	remoteName := r.opts.RemoteName
+	if remoteName == "" {
+		remoteName = DefaultRemoteName
+	}
+
+	reference, err := local.NewRepository(r.opts.Reference)
+	if err != nil {
+		return fmt.Errorf("failed to open reference repository: %w", err)
+	}
+	referenceDir, err := reference.CommonDir()
+	if err != nil {
+		return fmt.Errorf("failed to open reference repository: %w", err)
+	}
+	referenceRepo, err := git.PlainOpen(referenceDir)
+	if err != nil {
+		return fmt.Errorf("failed to open reference repository %q: %w", referenceDir, err)
+	}
+
+	branchRef := plumbing.NewBranchReferenceName(branch)
+	repo, err := git.PlainInit(path, r.opts.Bare, git.WithDefaultBranch(branchRef))
+	if err != nil {
+		return fmt.Errorf("failed to initialize repository at %q: %w", path, err)
+	}
+	gitDir := path
+	if !r.opts.Bare {
+		gitDir = filepath.Join(path, git.GitDirName)
+	}
+
+	// Objects the reference itself borrows through alternates could not be borrowed in turn
+	_, err = os.Stat(filepath.Join(referenceDir, "objects", "info", "alternates"))
+	if err == nil {
+		return fmt.Errorf("reference repository %q borrows objects from another repository: use that repository as the reference instead", r.opts.Reference)
+	}
+	err = borrowObjects(filepath.Join(referenceDir, "objects"), filepath.Join(gitDir, "objects"), !r.opts.Dissociate)
+	if err != nil {
+		return err
+	}
+	borrowed, err := borrowRefs(referenceRepo, repo)
+	if err != nil {
+		return err
+	}
+
+	remote, err := repo.CreateRemote(&config.RemoteConfig{
+		Name:  remoteName,
+		URLs:  []string{r.URL},
+		Fetch: cloneRefSpecs(remoteName, branch, r.opts.SingleBranch),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add remote %q: %w", remoteName, err)
+	}
+	err = remote.FetchContext(ctx, &git.FetchOptions{
+		Auth:            auth,
+		Progress:        r.opts.Progress,
+		Depth:           r.opts.Depth,
+		Tags:            r.opts.Tags.plumbing(),
+		InsecureSkipTLS: r.opts.InsecureSkipTLS,
+		CABundle:        r.opts.CABundle,
+	})
+	// The borrowed refs only served to tell the remote which objects are already present
+	for _, name := range borrowed {
+		removeErr := repo.Storer.RemoveReference(name)
+		if removeErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to remove reference %q borrowed from %q: %v\n", name, r.opts.Reference, removeErr)
+		}
+	}
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		if isNoSpace(err) {
+			return &NoSpaceError{Path: path, Free: freeSpace(path), Err: err}
+		}
+		return Classify(err)
+	}
+
+	tracking, err := repo.Reference(plumbing.NewRemoteReferenceName(remoteName, branch), true)
+	if err != nil {
+		return fmt.Errorf("branch %q was not found on remote %q: %w", branch, r.URL, err)
+	}
+	err = repo.Storer.SetReference(plumbing.NewHashReference(branchRef, tracking.Hash()))
+	if err != nil {
+		return fmt.Errorf("failed to create branch %q: %w", branch, err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read repository configuration: %w", err)
+	}
+	cfg.Branches[branch] = &config.Branch{
+		Name:   branch,
+		Remote: remoteName,
+		Merge:  branchRef,
+	}
+	err = repo.SetConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to configure upstream of branch %q: %w", branch, err)
+	}
+
+	if r.opts.Bare || r.opts.NoCheckout {
+		return nil
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to open worktree %q: %w", path, err)
+	}
+	err = wt.Reset(&git.ResetOptions{Commit: tracking.Hash(), Mode: git.HardReset})
+	if err != nil {
+		return fmt.Errorf("failed to check out branch %q: %w", branch, err)
+	}
+	return nil
+}
+
+// mirrorRefSpec fetches every ref of a remote into the ref of the same name, as configured for mirrors
+const mirrorRefSpec config.RefSpec = "+refs/*:refs/*"
+
+// cloneRefSpecs returns the refspecs a clone fetches from the given remote: every branch, or only branch if
+// singleBranch is set
+func cloneRefSpecs(remote, branch string, singleBranch bool) []config.RefSpec {
+	if singleBranch {
+		return []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", plumbing.NewBranchReferenceName(branch), plumbing.NewRemoteReferenceName(remote, branch)))}
+	}
+	return []config.RefSpec{config.RefSpec(fmt.Sprintf(config.DefaultFetchRefSpec, remote))}
+}
+
+// borrowObjects places every object in the object directory src - whether packed or loose - into the object directory
+// dst, hard-linking each file if link is set and possible, and otherwise copying it
+func borrowObjects(src, dst string, link bool) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		// Only packs - with their indexes - and the directories of loose objects hold objects
+		if entry.IsDir() {
+			if rel == "." || rel == "pack" || len(rel) == 2 {
+				return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+			}
+			return filepath.SkipDir
+		}
+		if filepath.Dir(rel) == "pack" && filepath.Ext(rel) != ".pack" && filepath.Ext(rel) != ".idx" {
+			return nil
+		}
+		if filepath.Dir(rel) == "." {
+			return nil
+		}
+
+		target := filepath.Join(dst, rel)
+		if link && os.Link(path, target) == nil {
+			return nil
+		}
+		return copyFile(path, target)
+	})
+}
+
+// copyFile copies the file at src to the new, read-only file dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o444)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %w", dst, err)
+	}
+	_, err = io.Copy(out, in)
+	if err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy %q to %q: %w", src, dst, err)
+	}
+	err = out.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write %q: %w", dst, err)
+	}
+	return nil
+}
+
+// borrowRefs copies every branch, remote-tracking branch, and tag of the reference repository into a temporary
+// namespace of repo, returning the names of the refs created
+func borrowRefs(reference, repo *git.Repository) ([]plumbing.ReferenceName, error) {
+	refs, err := reference.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list references of reference repository: %w", err)
+	}
+
+	borrowed := []plumbing.ReferenceName{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+		if !ref.Name().IsBranch() && !ref.Name().IsRemote() && !ref.Name().IsTag() {
+			return nil
+		}
+		name := plumbing.ReferenceName(referenceRefPrefix + ref.Name().String()[len("refs/"):])
+		err := repo.Storer.SetReference(plumbing.NewHashReference(name, ref.Hash()))
+		if err != nil {
+			return fmt.Errorf("failed to borrow reference %q: %w", ref.Name(), err)
+		}
+		borrowed = append(borrowed, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return borrowed, nil
+}
@@ -3,154 +3,560 @@ package remote
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 
-	"regexp"
 	"strings"
 
 	"github.com/go-git/go-git/v6"
 	"github.com/go-git/go-git/v6/config"
+	"github.com/go-git/go-git/v6/plumbing"
 	"github.com/go-git/go-git/v6/plumbing/transport"
 	"golang.org/x/term"
 
 	"github.com/go-git/go-git/v6/plumbing/transport/http"
 	"github.com/go-git/go-git/v6/plumbing/transport/ssh"
 	"github.com/go-git/go-git/v6/storage/memory"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+const (
+	// DefaultRemoteName is the name given to the remote a repository is cloned from
+	DefaultRemoteName = git.DefaultRemoteName
+	// UpstreamRemoteName is the name given to the repository a cloned fork was created from
+	UpstreamRemoteName = "upstream"
 )
 
 type Repository struct {
 	Authentication
 	URL string
+
+	opts Options
+
+	// probed indicates whether the remote has been checked for anonymous access
+	probed bool
+	// anonymous indicates whether the remote can be accessed without authenticating
+	anonymous bool
+	// refs caches the references advertised by the remote, once they have been listed successfully
+	refs []*plumbing.Reference
+	// rateLimit throttles every transfer to and from the remote, if Options.RateLimit is set
+	rateLimit *RateLimit
+}
+
+// Options customizes how a Repository authenticates against and retrieves data from the remote
+type Options struct {
+	// IdentityFile is the path to the private key used to authenticate against SSH remotes.
+	//
+	// Defaults to "", in which case authentication is done via the SSH agent
+	IdentityFile string
+
+	// Username is the user to authenticate as against HTTP(S) remotes. It is only used when Credential is also provided.
+	//
+	// Defaults to "", in which case "git" is used - most providers ignore the username when authenticating with a token
+	Username string
+
+	// Credential is the password or access token used to authenticate against HTTP(S) remotes.
+	//
+	// Defaults to "", in which case the user is prompted interactively for a username and password
+	Credential string
+
+	// Progress receives the human-readable progress information sent by the remote while cloning.
+	//
+	// Defaults to nil, in which case progress is written to os.Stdout. Use io.Discard to silence it
+	Progress io.Writer
+
+	// Depth limits cloning to the given number of commits from the tip of each branch.
+	//
+	// Defaults to 0, in which case the full history is cloned
+	Depth int
+
+	// Branch is the branch checked out after cloning.
+	//
+	// Defaults to "", in which case the remote's default branch is checked out
+	Branch string
+
+	// InsecureSkipTLS disables TLS certificate verification for HTTPS remotes.
+	//
+	// Defaults to false, in which case certificates are always verified
+	InsecureSkipTLS bool
+
+	// CABundle holds additional PEM-encoded certificate authorities trusted when verifying HTTPS remotes.
+	//
+	// Defaults to nil, in which case only the system's certificate pool is trusted
+	CABundle []byte
+
+	// KnownHostsFile is the known_hosts file used to verify the host keys of SSH remotes.
+	//
+	// Defaults to "", in which case $SSH_KNOWN_HOSTS, ~/.ssh/known_hosts, and /etc/ssh/ssh_known_hosts are used
+	KnownHostsFile string
+
+	// HostKeyChecking determines how unknown or changed SSH host keys are treated.
+	//
+	// Defaults to "", in which case HostKeyCheckingStrict is used
+	HostKeyChecking HostKeyChecking
+
+	// Tags determines which of the remote's tags are cloned.
+	//
+	// Defaults to "", in which case TagModeAll is used
+	Tags TagMode
+
+	// SingleBranch clones only the history of Branch, rather than of every branch of the remote.
+	//
+	// Defaults to false, in which case every branch is cloned
+	SingleBranch bool
+
+	// SSHCommand is the command run to connect to SSH remotes, in place of the in-process SSH client. When set,
+	// IdentityFile, KnownHostsFile, and HostKeyChecking are ignored; authentication is left to the command.
+	//
+	// Defaults to "", in which case $GIT_SSH_COMMAND is used, if set
+	SSHCommand string
+
+	// RemoteName is the name given to the remote the repository is cloned from.
+	//
+	// Defaults to "", in which case DefaultRemoteName is used
+	RemoteName string
+
+	// Bare clones the repository without a working tree.
+	//
+	// Defaults to false, in which case Branch is checked out in a working tree at the clone's path
+	Bare bool
+
+	// NoCheckout clones the repository with a working tree, but without checking out Branch's files into it.
+	//
+	// Defaults to false, in which case Branch's files are checked out
+	NoCheckout bool
+
+	// Mirror clones every ref of the remote - not only its branches and tags - into a bare repository, under the same
+	// names, as 'git clone --mirror' does. The remote is configured so that fetching from it overwrites every ref
+	// again. Implies Bare.
+	//
+	// Defaults to false, in which case the remote's branches are cloned as remote-tracking branches
+	Mirror bool
+
+	// Reference is the path to a local repository - or grove - already holding objects of the remote. Its objects are
+	// borrowed when cloning, so only those it lacks are retrieved from the remote. See Dissociate.
+	//
+	// Defaults to "", in which case every object is retrieved from the remote
+	Reference string
+
+	// Dissociate copies the objects borrowed from Reference, rather than hard-linking them. Hard-linked objects take
+	// no extra space while Reference exists, but the clone never depends on Reference either way.
+	//
+	// Defaults to false, in which case objects are hard-linked where possible
+	Dissociate bool
+
+	// Waiting is called with a description of the operation whenever the remote is asked to list its refs - which takes
+	// a while for remotes with many refs, or slow servers - and reports no progress of its own. The function it returns
+	// is called once listing completes, before any prompt for credentials.
+	//
+	// Defaults to nil, in which case nothing is reported
+	Waiting func(message string) (done func())
+
+	// RateLimit caps the data transferred to and from the remote at roughly the given number of bytes per second. See
+	// the RateLimit type for its limitations.
+	//
+	// Defaults to 0, in which case transfers are not limited
+	RateLimit int64
 }
 
-// NewRepository creates a Repository object for the given remote URL
+// NewRepository creates a Repository object for the given remote URL using the default Options
 func NewRepository(remoteURL string) (*Repository, error) {
+	return NewRepositoryWithOptions(remoteURL, Options{})
+}
+
+// NewRepositoryWithOptions creates a Repository object for the given remote URL, customized by the provided Options.
+// The URL is checked for common mistakes first, by NormalizeURL
+func NewRepositoryWithOptions(remoteURL string, opts Options) (*Repository, error) {
+	remoteURL, err := NormalizeURL(remoteURL)
+	if err != nil {
+		return nil, err
+	}
 	auth, err := AuthMethod(remoteURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize authentication method: %w", err)
 	}
+
+	switch a := auth.(type) {
+	case *HTTPAuthentication:
+		if opts.Credential != "" {
+			a.Username = opts.Username
+			a.Password = opts.Credential
+		}
+	case *SSHAuthentication:
+		a.Command = sshCommand(opts.SSHCommand)
+		a.IdentityFile = opts.IdentityFile
+		a.KnownHostsFile = opts.KnownHostsFile
+		a.HostKeyChecking = opts.HostKeyChecking
+		if opts.RateLimit > 0 && a.Command == "" {
+			fmt.Fprintln(os.Stderr, "warning: transfer rate is not limited for SSH remotes unless an SSH command is used")
+		}
+	}
+
+	if opts.Progress == nil {
+		opts.Progress = os.Stdout
+	}
+
 	r := &Repository{
 		URL:            remoteURL,
 		Authentication: auth,
+		opts:           opts,
+		rateLimit:      NewRateLimit(opts.RateLimit),
 	}
 	return r, nil
 }
 
 // DefaultBranch attempts to determine the default branch for the Repository's URL.
-// This is done by looking at the target branch for the HEAD ref from the remote.
+// This is done by looking at the target branch for the HEAD ref from the remote. Empty remotes have no HEAD to
+// look at, so the branch their first commit would create is returned instead: init.defaultBranch from the user's git
+// configuration, or 'master', as with git.
+//
+// ErrNoDefaultBranch is returned if the remote's HEAD does not point to a branch. Failures to access the remote are
+// wrapped with ErrAuthFailed or ErrUnreachable, where applicable
 func (r *Repository) DefaultBranch(ctx context.Context) (string, error) {
-	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
-		URLs: []string{r.URL},
-	})
-
-	auth, err := r.NewAuthMethod()
+	auth, err := r.authMethod(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to authenticate with %q: %w", r.URL, err)
 	}
 
-	refs, err := remote.ListContext(ctx, &git.ListOptions{
-		Auth: auth,
-	})
+	refs, err := r.list(ctx, auth)
 	if err != nil {
-		return "", fmt.Errorf("failed to list refs for %q: %w", r.URL, err)
+		if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+			return emptyDefaultBranch(), nil
+		}
+		return "", fmt.Errorf("failed to list refs for %q: %w", r.URL, Classify(err))
 	}
 
 	for _, ref := range refs {
 		if ref.Name() == "HEAD" {
 			branch := ref.Target().Short()
 			if branch == "" {
-				return "", fmt.Errorf("HEAD ref for %q is missing target", r.URL)
+				return "", fmt.Errorf("%w: HEAD ref for %q is missing target", ErrNoDefaultBranch, r.URL)
 			}
 			return branch, nil
 		}
 	}
-	return "", fmt.Errorf("no HEAD ref defined for %q", r.URL)
+	return "", fmt.Errorf("%w: no HEAD ref defined for %q", ErrNoDefaultBranch, r.URL)
 }
 
-// Clone authenticates to the Repository and clones it into the given path
-func (r *Repository) Clone(path string) error {
+// list retrieves the refs advertised by the remote, authenticating with auth.
+//
+// The refs are cached, so that the remote is only contacted once: when probing for anonymous access succeeds, for
+// example, DefaultBranch reuses the refs it listed rather than listing them again. Clone still performs its own
+// handshake, so creating a grove contacts the remote twice - once to list its refs, and once to clone it - rather
+// than three times for HTTP(S) remotes which can be accessed anonymously
+func (r *Repository) list(ctx context.Context, auth transport.AuthMethod) ([]*plumbing.Reference, error) {
+	if r.refs != nil {
+		return r.refs, nil
+	}
+
+	if r.opts.Waiting != nil {
+		done := r.opts.Waiting(fmt.Sprintf("listing refs of %s", r.URL))
+		defer done()
+	}
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		URLs: []string{r.URL},
+	})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{
+		Auth:            auth,
+		InsecureSkipTLS: r.opts.InsecureSkipTLS,
+		CABundle:        r.opts.CABundle,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.refs = refs
+	return refs, nil
+}
+
+// authMethod determines how to authenticate with the remote.
+//
+// Unless credentials were provided explicitly, HTTP(S) remotes are first accessed anonymously - as git does - so that
+// public repositories can be cloned without prompting. If anonymous access succeeds, the returned AuthMethod is nil;
+// if the remote requires authentication, credentials are requested as usual. Any other failure - such as the remote
+// being unreachable - is returned without prompting.
+//
+// The user is prompted at most once: the credentials are cached, and reused by every later operation on the Repository,
+// even if the remote rejects them. The returned AuthMethod also applies the Repository's rate limit, if any, to the
+// operations it is used for
+func (r *Repository) authMethod(ctx context.Context) (transport.AuthMethod, error) {
+	httpAuth, ok := r.Authentication.(*HTTPAuthentication)
+	if ok && httpAuth.Password == "" && !r.probed {
+		r.probed = true
+		_, err := r.list(ctx, nil)
+		// An empty repository has no refs to list, but can still be accessed anonymously
+		if err == nil || errors.Is(err, transport.ErrEmptyRemoteRepository) {
+			r.anonymous = true
+		} else if !isAuthError(err) {
+			// There's no point asking for credentials if the remote can't be reached at all
+			return nil, Classify(err)
+		}
+	}
+	if r.anonymous {
+		return r.rateLimit.Apply(nil), nil
+	}
 	auth, err := r.NewAuthMethod()
 	if err != nil {
-		return fmt.Errorf("failed to authenticate with %q: %w", r.URL, err)
+		return nil, err
+	}
+	return r.rateLimit.Apply(auth), nil
+}
 
+// Auth determines how to authenticate with the remote, in the same way as when cloning it. A nil AuthMethod is
+// returned if the remote can be accessed anonymously
+func (r *Repository) Auth(ctx context.Context) (transport.AuthMethod, error) {
+	return r.authMethod(ctx)
+}
+
+// Clone authenticates to the Repository and clones it into the given path. Cloning stops if ctx is cancelled.
+// Empty remotes are cloned as git does: the repository is initialized with the remote configured, and HEAD pointing to
+// the unborn Branch - or the branch DefaultBranch returns - so that the first commit creates it.
+//
+// A NoSpaceError is returned if the disk holding path runs out of space. The partial clone is left in place
+func (r *Repository) Clone(ctx context.Context, path string) error {
+	auth, err := r.authMethod(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to authenticate with %q: %w", r.URL, err)
+	}
+	empty, err := r.IsEmpty(ctx)
+	if err == nil && empty {
+		return r.cloneEmpty(ctx, path)
 	}
-	_, err = git.PlainClone(path, &git.CloneOptions{
-		URL:      r.URL,
-		Auth:     auth,
-		Progress: os.Stdout,
+	if r.opts.Reference != "" {
+		return r.cloneWithReference(ctx, path)
+	}
+
+	_, err = git.PlainCloneContext(ctx, path, &git.CloneOptions{
+		URL:             r.URL,
+		Auth:            auth,
+		Progress:        r.opts.Progress,
+		Depth:           r.opts.Depth,
+		ReferenceName:   branchReference(r.opts.Branch),
+		InsecureSkipTLS: r.opts.InsecureSkipTLS,
+		CABundle:        r.opts.CABundle,
+		Bare:            r.opts.Bare,
+		Mirror:          r.opts.Mirror,
+		NoCheckout:      r.opts.NoCheckout,
+		RemoteName:      r.opts.RemoteName,
+		Tags:            r.opts.Tags.plumbing(),
+		SingleBranch:    r.opts.SingleBranch,
 	})
-	return err
+	if err != nil && isNoSpace(err) {
+		return &NoSpaceError{Path: path, Free: freeSpace(path), Err: err}
+	}
+	return Classify(err)
+}
+
+// branchReference returns the full reference name of the given branch, or an empty reference name if no branch is given
+func branchReference(branch string) plumbing.ReferenceName {
+	if branch == "" {
+		return ""
+	}
+	return plumbing.NewBranchReferenceName(branch)
+}
+
+// ResolveAuth determines the transport.AuthMethod used to communicate with the remote at the given URL, in the
+// same way as when cloning with the default Options. Local repositories require no authentication, so a nil
+// AuthMethod is returned for them
+func ResolveAuth(url string) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(url)
+	if err == nil && endpoint.Scheme == "file" {
+		return nil, nil
+	}
+
+	auth, err := AuthMethod(url)
+	if err != nil {
+		return nil, err
+	}
+	sshAuth, ok := auth.(*SSHAuthentication)
+	if ok {
+		sshAuth.Command = sshCommand("")
+	}
+	return auth.NewAuthMethod()
+}
+
+// ResolveFetchAuth determines the transport.AuthMethod used to fetch from the remote at the given URL. Unlike
+// ResolveAuth, HTTP(S) remotes are first accessed anonymously - as when cloning - so that public repositories can be
+// fetched from without prompting. A nil AuthMethod is returned if no authentication is needed.
+//
+// Pushing usually requires credentials even when fetching doesn't, so ResolveAuth should be used for pushes instead
+func ResolveFetchAuth(ctx context.Context, url string) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(url)
+	if err == nil && endpoint.Scheme == "file" {
+		return nil, nil
+	}
+
+	repository, err := NewRepository(url)
+	if err != nil {
+		return nil, err
+	}
+	return repository.Auth(ctx)
 }
 
+// Authentication determines how to authenticate with a remote repository
 type Authentication interface {
+	// NewAuthMethod generates the transport.AuthMethod used to authenticate with the remote
 	NewAuthMethod() (transport.AuthMethod, error)
+	// Protocol returns the protocol the remote is accessed with
+	Protocol() Protocol
 }
 
 // AuthMethod parses the Repository's URL to determine the transport protocol being used and generate
-// the correct Authentication method. If the protocol is SSH, then authenticaion will be done via
-// SSH agent - this method does not currently support specifying keyfiles (TODO!)
+// the correct Authentication method, whose Protocol reports the protocol detected. If the protocol is SSH, then
+// authenticaion will be done via SSH agent, unless an IdentityFile is later set on the returned SSHAuthentication
 //
 // Supported formats are:
 //   - URL prefixed with http:// or https:// for HTTP(S)
 //   - URL prefixed with ssh:// for SSH
 //   - URL formatted as <user>@<remote>:<repo> for SSH
+//   - URL formatted as <remote>:<repo> for SSH, where remote may also be a Host alias in ~/.ssh/config
+//
+// For SSH, the HostName, Port, User, and IdentityFile configured in ~/.ssh/config for the remote are honored, as
+// with git. When the URL gives no user, the User configured for the remote is used, falling back to the current user.
 //
 // Other formats, such as 'git://' and 'ftp://' are supported by the git-cli tool, but not by this package.
 // Local repos (ie - /path/to/repo or or file:///path/to/repo) are likewise not (yet) supported
 func AuthMethod(url string) (Authentication, error) {
 	// For HTTP(S): URL must be prefixed with either 'https://' or 'http://'
 	if strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") {
-		return NewHTTPAuthentication(), nil
+		return NewHTTPAuthentication(url), nil
 	}
 
-	// SSH can have two formats: either prefixed with 'ssh://' or '<user>@<remote>:<repo>'
-	sshRegex := regexp.MustCompile(".+@.+:.+")
-	if strings.HasPrefix(url, "ssh://") || sshRegex.MatchString(url) {
+	// SSH can have two formats: either prefixed with 'ssh://' or '[<user>@]<remote>:<repo>'
+	if strings.HasPrefix(url, "ssh://") || isSCPLike(url) {
 		return NewSSHAuthentication(url), nil
 	}
 
-	return nil, fmt.Errorf("could not determine correct transport protocol for %q (expected one of 'https://<repo>', 'ssh://<repo>', or '<user>@<remote>:<repo>')", url)
+	protocol := DetectProtocol(url)
+	if protocol == ProtocolGit || protocol == ProtocolLocal {
+		return nil, fmt.Errorf("unsupported transport protocol %q for %q (expected one of 'https://<repo>', 'ssh://<repo>', or '[<user>@]<remote>:<repo>')", protocol, url)
+	}
+	return nil, fmt.Errorf("could not determine correct transport protocol for %q (expected one of 'https://<repo>', 'ssh://<repo>', or '[<user>@]<remote>:<repo>')", url)
 }
 
 // HTTPAuthentication grants the ability to authenticate against HTTP(S) remote repositories
 //
-// It (interactively) queries the user for a username or password; there is currently no method
-// to pass in either info via argument (TODO? What would be the use-case here?)
+// It (interactively) queries the user for a username or password, unless a Password has been provided or
+// the user's configured git credential helper, .netrc file, or forge CLI ('gh' or 'glab') is able to supply one
 type HTTPAuthentication struct {
+	URL string
+
+	// Username is the user to authenticate as when Password is set. Defaults to "git" if empty
+	Username string
+	// Password is the password or access token to authenticate with. If empty, the user is prompted
+	Password string
+
 	authMethod transport.AuthMethod
 }
 
-func NewHTTPAuthentication() *HTTPAuthentication {
-	return &HTTPAuthentication{}
+func NewHTTPAuthentication(url string) *HTTPAuthentication {
+	a := &HTTPAuthentication{
+		URL: url,
+	}
+	return a
+}
+
+// Protocol returns ProtocolHTTP
+func (a *HTTPAuthentication) Protocol() Protocol {
+	return ProtocolHTTP
 }
 
 const (
 	httpAuthUsernamePrompt = "username: "
 	httpAuthPasswordPrompt = "password: "
+
+	// httpAuthDefaultUsername is used when a credential is provided without an accompanying username
+	httpAuthDefaultUsername = "git"
 )
 
 // NewAuthMethod generates the authentication method used to communicate with git repos via HTTP(S).
 //
-// It interactively queries the user for a username and password, if one has not yet been provided.
+// If no password has been provided, the git credential helper is consulted first, followed by the user's
+// .netrc file, and then the 'gh' or 'glab' CLI for GitHub and GitLab remotes. The user is only queried
+// interactively for a username and password if none is able to supply them.
+//
+// Credentials obtained from these sources are cached for the URL's host, and reused for every other remote on the
+// same host, so that the user is prompted at most once per host. Each host is only sent its own credentials
 func (a *HTTPAuthentication) NewAuthMethod() (transport.AuthMethod, error) {
 	if a.authMethod != nil {
 		return a.authMethod, nil
 	}
-	return a.createCachedAuthMethod()
+	if a.Password != "" {
+		username := a.Username
+		if username == "" {
+			username = httpAuthDefaultUsername
+		}
+		a.authMethod = &http.BasicAuth{
+			Username: username,
+			Password: a.Password,
+		}
+		return a.authMethod, nil
+	}
+
+	hostCredentialsMu.Lock()
+	defer hostCredentialsMu.Unlock()
+	host := credentialHost(a.URL)
+	cached, found := hostCredentials[host]
+	if found {
+		a.authMethod = cached
+		return cached, nil
+	}
+
+	auth, err := a.lookupCredentials()
+	if err != nil {
+		return nil, err
+	}
+	// cache to avoid re-querying the sources, or the user, for this host
+	hostCredentials[host] = auth
+	a.authMethod = auth
+	return auth, nil
 }
 
-func (a *HTTPAuthentication) createCachedAuthMethod() (transport.AuthMethod, error) {
-	fmt.Print(httpAuthUsernamePrompt)
+// lookupCredentials obtains credentials for the URL from the git credential helper, .netrc file, or forge CLI, in
+// that order, before falling back to prompting the user
+func (a *HTTPAuthentication) lookupCredentials() (transport.AuthMethod, error) {
+	auth, err := credentialHelperAuth(a.URL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to retrieve credentials from git credential helper: %v\n", err)
+	}
+	if auth != nil {
+		return auth, nil
+	}
+
+	auth, err = netrcAuth(a.URL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: skipping .netrc: %v\n", err)
+	}
+	if auth != nil {
+		return auth, nil
+	}
+
+	auth, err = cliTokenAuth(a.URL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to retrieve token from forge CLI: %v\n", err)
+	}
+	if auth != nil {
+		return auth, nil
+	}
+	return a.promptCredentials()
+}
+
+// promptCredentials queries the user interactively for a username and password. As with git, the prompts are written
+// to stderr, so that stdout holds only the command's results
+func (a *HTTPAuthentication) promptCredentials() (transport.AuthMethod, error) {
+	announceAuthentication(a.Protocol(), a.URL)
+	fmt.Fprint(os.Stderr, httpAuthUsernamePrompt)
 	username, err := bufio.NewReader(os.Stdin).ReadString('\n')
 	if err != nil {
 		return nil, fmt.Errorf("failed to read username entry: %w", err)
 	}
 	username = strings.TrimSpace(username)
 
-	fmt.Print(httpAuthPasswordPrompt)
+	fmt.Fprint(os.Stderr, httpAuthPasswordPrompt)
 	password, err := term.ReadPassword(int(os.Stdin.Fd()))
-	fmt.Println()
+	fmt.Fprintln(os.Stderr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read password entry: %w", err)
 	}
@@ -159,19 +565,35 @@ func (a *HTTPAuthentication) createCachedAuthMethod() (transport.AuthMethod, err
 		Username: username,
 		Password: string(password),
 	}
-	// cache to avoid re-querying user
-	a.authMethod = auth
 	return auth, nil
 }
 
 // SSHAuthentication grants the ability to authenticate against SSH remote repositories
 //
 // NewAuthMethod generates the authentication method used to communicate with git repos via SSH.
-// Currently, only authenticating via ssh-agent is supported (TODO - add method for auth via keyfiles)
+// Authentication is done via ssh-agent, unless an IdentityFile is provided
 type SSHAuthentication struct {
 	URL string
+	// IdentityFile is the path to the private key to authenticate with. If empty, the SSH agent is used
+	IdentityFile string
+	// KnownHostsFile is the known_hosts file used to verify the remote's host key. If empty, the default files are used
+	KnownHostsFile string
+	// HostKeyChecking determines how unknown or changed host keys are treated. If empty, HostKeyCheckingStrict is used
+	HostKeyChecking HostKeyChecking
+	// Command is the command run to connect to the remote in place of the in-process SSH client, which handles
+	// authentication itself. If empty, the in-process client is used
+	Command string
+
+	authMethod transport.AuthMethod
 }
 
+const (
+	// sshPassphraseEnv is the environment variable consulted for the IdentityFile's passphrase before prompting
+	sshPassphraseEnv = "GROVE_SSH_PASSPHRASE"
+
+	sshPassphrasePrompt = "passphrase for %s: "
+)
+
 func NewSSHAuthentication(url string) *SSHAuthentication {
 	a := &SSHAuthentication{
 		URL: url,
@@ -179,15 +601,107 @@ func NewSSHAuthentication(url string) *SSHAuthentication {
 	return a
 }
 
-// NewAuthMethod generates the authentication method used to communicate with git repos via SSH.
-// Currently, only authenticating via ssh-agent is supported (TODO - add method for auth via keyfiles)
+// Protocol returns ProtocolSSH
+func (a *SSHAuthentication) Protocol() Protocol {
+	return ProtocolSSH
+}
+
+// NewAuthMethod generates the authentication method used to communicate with git repos via SSH. If an IdentityFile
+// has been provided - or is configured for the host in ~/.ssh/config - it is used to authenticate; otherwise, the SSH
+// agent is used. When a Command is set, the returned AuthMethod runs it to connect instead, as UseSSHCommand does
 func (a *SSHAuthentication) NewAuthMethod() (transport.AuthMethod, error) {
-	a.URL = strings.TrimPrefix(a.URL, "ssh://")
-	tokens := strings.Split(a.URL, "@")
-	if len(tokens) != 2 {
-		return nil, fmt.Errorf("invalid format: expected exactly one '@' character for SSH authentication, found %d", len(tokens))
+	if a.Command != "" {
+		return UseSSHCommand(a.Command), nil
+	}
+	if a.authMethod != nil {
+		return a.authMethod, nil
+	}
+
+	endpoint, err := transport.NewEndpoint(a.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH URL %q: %w", a.URL, err)
+	}
+	user, err := sshUser(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if a.IdentityFile == "" {
+		a.IdentityFile, err = sshIdentityFile(endpoint.Hostname())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	callback, err := a.hostKeyCallback()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure host key verification: %w", err)
+	}
+
+	if a.IdentityFile != "" {
+		auth, err := a.createCachedKeyfileAuthMethod(user)
+		if err != nil {
+			return nil, err
+		}
+		auth.HostKeyCallback = callback
+		return auth, nil
+	}
+
+	auth, err := ssh.NewSSHAgentAuth(user)
+	if err != nil {
+		// Without an agent, there is no key to authenticate with
+		return nil, fmt.Errorf("%w: failed to connect to SSH agent: %w", ErrAuthFailed, err)
+	}
+	auth.HostKeyCallback = callback
+	// cache to avoid reloading known_hosts
+	a.authMethod = auth
+	return auth, nil
+}
+
+// createCachedKeyfileAuthMethod generates an authentication method from the IdentityFile. If the key is
+// encrypted, the passphrase is read from $GROVE_SSH_PASSPHRASE or, if unset, interactively queried from the user
+func (a *SSHAuthentication) createCachedKeyfileAuthMethod(user string) (*ssh.PublicKeys, error) {
+	key, err := os.ReadFile(a.IdentityFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity file %q: %w", a.IdentityFile, err)
+	}
+
+	passphrase := ""
+	_, err = cryptossh.ParseRawPrivateKey(key)
+	if err != nil {
+		missingErr := &cryptossh.PassphraseMissingError{}
+		if !errors.As(err, &missingErr) {
+			return nil, fmt.Errorf("failed to parse identity file %q: %w", a.IdentityFile, err)
+		}
+
+		// Key is encrypted
+		passphrase, err = a.passphrase()
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	user := tokens[0]
-	return ssh.NewSSHAgentAuth(user)
+	auth, err := ssh.NewPublicKeys(user, key, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load identity file %q: %w", a.IdentityFile, err)
+	}
+	// cache to avoid re-querying user
+	a.authMethod = auth
+	return auth, nil
+}
+
+// passphrase retrieves the passphrase for the IdentityFile from the environment, falling back to prompting the user
+func (a *SSHAuthentication) passphrase() (string, error) {
+	passphrase, found := os.LookupEnv(sshPassphraseEnv)
+	if found {
+		return passphrase, nil
+	}
+
+	announceAuthentication(a.Protocol(), a.URL)
+	fmt.Fprintf(os.Stderr, sshPassphrasePrompt, a.IdentityFile)
+	input, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase entry: %w", err)
+	}
+	return string(input), nil
 }
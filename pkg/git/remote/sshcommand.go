@@ -0,0 +1,161 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+)
+
+const (
+	// SSHCommandEnv is the environment variable git consults for the command used to connect to SSH remotes
+	SSHCommandEnv = "GIT_SSH_COMMAND"
+
+	// sshScheme is the transport protocol replaced when an SSH command is in use
+	sshScheme = "ssh"
+)
+
+// UseSSHCommand returns an AuthMethod which connects to SSH remotes by running the given command - as git does with
+// $GIT_SSH_COMMAND - in place of the in-process SSH client. This allows the system's SSH client and its configuration
+// (eg - ProxyJump hosts, ControlMaster sockets, or hardware keys) to be used. Only the operations the AuthMethod is used
+// for are affected.
+//
+// The command is run via the shell with the remote's host and the git command to execute appended, so it may include
+// its own arguments. Authentication and host key verification are left entirely to the command
+func UseSSHCommand(command string) transport.AuthMethod {
+	return scope(nil, func(scoped *scopedAuth) {
+		scoped.sshCommand = command
+	})
+}
+
+// sshCommand returns the command used to connect to SSH remotes, if one has been configured: the given command,
+// if set, or $GIT_SSH_COMMAND
+func sshCommand(command string) string {
+	if command != "" {
+		return command
+	}
+	return os.Getenv(SSHCommandEnv)
+}
+
+// sshCommandRunner starts git commands on SSH remotes by running an external SSH command
+type sshCommandRunner struct {
+	command string
+	// rateLimit throttles the command's input and output, if set
+	rateLimit *RateLimit
+}
+
+// Command prepares the external SSH command to run cmd on the endpoint's host. Authentication is handled by the
+// SSH command itself, so auth is ignored
+func (r *sshCommandRunner) Command(ctx context.Context, cmd string, ep *transport.Endpoint, _ transport.AuthMethod, params ...string) (transport.Command, error) {
+	switch transport.Service(cmd) {
+	case transport.UploadPackService, transport.ReceivePackService:
+		// do nothing
+	default:
+		return nil, transport.ErrUnsupportedService
+	}
+
+	args := []string{}
+	gitProtocol := strings.Join(params, ":")
+	if gitProtocol != "" {
+		// Mirror git, which asks OpenSSH to forward the protocol version to the server
+		args = append(args, "-o", "SendEnv=GIT_PROTOCOL")
+	}
+	if ep.Port() != "" {
+		args = append(args, "-p", ep.Port())
+	}
+
+	host := ep.Hostname()
+	if ep.User != nil && ep.User.Username() != "" {
+		host = ep.User.Username() + "@" + host
+	}
+	args = append(args, host, fmt.Sprintf("%s %s", cmd, shellQuote(ep.Path)))
+
+	// The command is run via the shell so that it may contain its own arguments; "$@" expands to the arguments
+	// appended to it
+	c := exec.CommandContext(ctx, "sh", append([]string{"-c", r.command + ` "$@"`, r.command}, args...)...)
+	c.Env = os.Environ()
+	if gitProtocol != "" {
+		c.Env = append(c.Env, "GIT_PROTOCOL="+gitProtocol)
+	}
+	return &sshCommandProcess{cmd: c, command: r.command, rateLimit: r.rateLimit}, nil
+}
+
+// sshCommandProcess adapts an exec.Cmd to the transport.Command interface
+type sshCommandProcess struct {
+	cmd       *exec.Cmd
+	command   string
+	rateLimit *RateLimit
+	stdin     io.WriteCloser
+	closed    bool
+}
+
+// StderrPipe returns a pipe connected to the SSH command's standard error
+func (c *sshCommandProcess) StderrPipe() (io.Reader, error) {
+	return c.cmd.StderrPipe()
+}
+
+// StdinPipe returns a pipe connected to the SSH command's standard input, throttled by the RateLimit, if any
+func (c *sshCommandProcess) StdinPipe() (io.WriteCloser, error) {
+	stdin, err := c.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	c.stdin = stdin
+	if c.rateLimit != nil {
+		return &rateLimitedWriter{WriteCloser: stdin, limit: c.rateLimit.limits.write}, nil
+	}
+	return stdin, nil
+}
+
+// StdoutPipe returns a pipe connected to the SSH command's standard output, throttled by the RateLimit, if any
+func (c *sshCommandProcess) StdoutPipe() (io.Reader, error) {
+	stdout, err := c.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if c.rateLimit != nil {
+		return &rateLimitedReader{Reader: stdout, limit: c.rateLimit.limits.read}, nil
+	}
+	return stdout, nil
+}
+
+// Start starts the SSH command without waiting for it to exit
+func (c *sshCommandProcess) Start() error {
+	return c.cmd.Start()
+}
+
+// Close signals the end of input to the SSH command, then waits for it to exit
+func (c *sshCommandProcess) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	// The command was never started, so there's nothing to wait for
+	if c.cmd.Process == nil {
+		return nil
+	}
+	if c.stdin != nil {
+		// The remote git command only exits once its input is closed
+		_ = c.stdin.Close()
+	}
+	// As with the built-in client, the remote's exit status is ignored: the command is closed as soon as the exchange
+	// is complete, often before the remote git command has exited cleanly, and failures to connect are reported when
+	// reading from it instead
+	err := c.cmd.Wait()
+	exitErr := &exec.ExitError{}
+	if err != nil && !errors.As(err, &exitErr) {
+		return fmt.Errorf("ssh command %q failed: %w", c.command, err)
+	}
+	return nil
+}
+
+// shellQuote quotes s for use as a single argument to a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
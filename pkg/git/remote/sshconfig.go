@@ -0,0 +1,52 @@
+package remote
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/kevinburke/ssh_config"
+)
+
+// sshConfig holds the settings of ~/.ssh/config and /etc/ssh/ssh_config. The in-process SSH client consults the same
+// settings to resolve the HostName and Port of each host
+var sshConfig = ssh_config.DefaultUserSettings
+
+// sshUser determines the user to authenticate as against the given endpoint: the user given in its URL, if any,
+// otherwise the User configured for its host, falling back to the current user - as ssh does
+func sshUser(endpoint *transport.Endpoint) (string, error) {
+	if endpoint.User != nil && endpoint.User.Username() != "" {
+		return endpoint.User.Username(), nil
+	}
+
+	configured := sshConfig.Get(endpoint.Hostname(), "User")
+	if configured != "" {
+		return configured, nil
+	}
+
+	current, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("no user given for %q, and failed to determine the current user: %w", endpoint.Hostname(), err)
+	}
+	return current.Username, nil
+}
+
+// sshIdentityFile returns the IdentityFile configured for the given host, or "" if none is configured explicitly
+func sshIdentityFile(alias string) (string, error) {
+	path := sshConfig.Get(alias, "IdentityFile")
+	if path == "" || path == ssh_config.Default("IdentityFile") {
+		return "", nil
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand identity file %q: %w", path, err)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	return path, nil
+}
@@ -0,0 +1,101 @@
+package remote
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/kevinburke/ssh_config"
+)
+
+func TestSSHUser(t *testing.T) {
+	useSSHConfig(t, "Host alias\n\tHostName git.example.com\n\tUser configured\n")
+	current, err := user.Current()
+	if err != nil {
+		t.Fatalf("failed to determine current user: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "scp-like with user", url: "git@github.com:org/repo.git", want: "git"},
+		{name: "scp-like without user", url: "github.com:org/repo.git", want: current.Username},
+		{name: "scp-like with configured user", url: "alias:org/repo.git", want: "configured"},
+		{name: "scp-like overriding configured user", url: "other@alias:org/repo.git", want: "other"},
+		{name: "ssh with user", url: "ssh://git@github.com/org/repo.git", want: "git"},
+		{name: "ssh without user", url: "ssh://github.com/org/repo.git", want: current.Username},
+		{name: "ssh with configured user", url: "ssh://alias:2222/org/repo.git", want: "configured"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint, err := transport.NewEndpoint(tt.url)
+			if err != nil {
+				t.Fatalf("failed to parse %q: %v", tt.url, err)
+			}
+
+			got, err := sshUser(endpoint)
+			if err != nil {
+				t.Fatalf("sshUser(%q) returned error: %v", tt.url, err)
+			}
+			if got != tt.want {
+				t.Errorf("sshUser(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthMethod(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    Protocol
+		wantErr bool
+	}{
+		{name: "scp-like with user", url: "git@github.com:org/repo.git", want: ProtocolSSH},
+		{name: "scp-like without user", url: "github.com:org/repo.git", want: ProtocolSSH},
+		{name: "scp-like without suffix", url: "git@github.com:org/repo", want: ProtocolSSH},
+		{name: "ssh", url: "ssh://git@github.com/org/repo.git", want: ProtocolSSH},
+		{name: "https", url: "https://github.com/org/repo.git", want: ProtocolHTTP},
+		{name: "http", url: "http://git.example.com/org/repo.git", want: ProtocolHTTP},
+		{name: "git", url: "git://github.com/org/repo.git", wantErr: true},
+		{name: "local path", url: "/srv/git/repo.git", wantErr: true},
+		{name: "file", url: "file:///srv/git/repo.git", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := AuthMethod(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AuthMethod(%q) returned error %v, want error: %v", tt.url, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if auth.Protocol() != tt.want {
+				t.Errorf("AuthMethod(%q).Protocol() = %q, want %q", tt.url, auth.Protocol(), tt.want)
+			}
+		})
+	}
+}
+
+// useSSHConfig replaces the SSH configuration consulted for the rest of the test with one holding the given content
+func useSSHConfig(t *testing.T, content string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config")
+	err := os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write SSH configuration: %v", err)
+	}
+	settings := &ssh_config.UserSettings{}
+	settings.ConfigFinder(func() string { return path })
+
+	original := sshConfig
+	sshConfig = settings
+	t.Cleanup(func() {
+		sshConfig = original
+	})
+}
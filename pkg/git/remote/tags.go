@@ -0,0 +1,42 @@
+package remote
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// TagMode determines which of the remote's tags are retrieved when cloning
+type TagMode string
+
+const (
+	// TagModeAll retrieves every tag of the remote, regardless of whether it points into the history being cloned
+	TagModeAll TagMode = "all"
+	// TagModeNone retrieves no tags
+	TagModeNone TagMode = "none"
+	// TagModeFollowing retrieves only the tags which point into the history being cloned
+	TagModeFollowing TagMode = "following"
+)
+
+// ParseTagMode converts the given value into a TagMode. An empty value results in TagModeAll, matching git clone
+func ParseTagMode(value string) (TagMode, error) {
+	switch TagMode(value) {
+	case "":
+		return TagModeAll, nil
+	case TagModeAll, TagModeNone, TagModeFollowing:
+		return TagMode(value), nil
+	}
+	return "", fmt.Errorf("invalid tag mode %q: expected one of %q, %q, or %q", value, TagModeAll, TagModeNone, TagModeFollowing)
+}
+
+// plumbing converts the TagMode into its go-git equivalent
+func (t TagMode) plumbing() plumbing.TagMode {
+	switch t {
+	case TagModeNone:
+		return plumbing.NoTags
+	case TagModeFollowing:
+		return plumbing.TagFollowing
+	default:
+		return plumbing.AllTags
+	}
+}
@@ -0,0 +1,100 @@
+package remote
+
+import (
+	"sync"
+
+	"github.com/go-git/go-git/v6/plumbing/protocol"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/go-git/go-git/v6/storage"
+)
+
+// scopedSchemes are the transport protocols whose connections may be customized by a scopedAuth
+var scopedSchemes = []string{"http", "https", sshScheme}
+
+// installScopedTransports registers a scopedTransport for each of the scopedSchemes once, wrapping the transport
+// previously registered for it
+var installScopedTransports = sync.OnceFunc(func() {
+	for _, scheme := range scopedSchemes {
+		previous, err := transport.Get(scheme)
+		if err != nil {
+			continue
+		}
+		transport.Register(scheme, &scopedTransport{scheme: scheme, previous: previous})
+	}
+})
+
+// scopedAuth customizes how the operations it authenticates connect to their remote. go-git only looks transports up
+// by scheme, from a registry shared by the whole process, and the AuthMethod is the only value it hands from an
+// operation to its transport - so the customizations travel with it, rather than replacing the registered transports
+// for every other operation
+type scopedAuth struct {
+	// auth is the AuthMethod the remote is authenticated with, if any
+	auth transport.AuthMethod
+	// sshCommand is the command run to connect to SSH remotes, in place of the in-process SSH client, if any
+	sshCommand string
+	// rateLimit throttles the transfers of the operation, if set
+	rateLimit *RateLimit
+}
+
+// Name returns the name of the wrapped AuthMethod, if any
+func (a *scopedAuth) Name() string {
+	if a.auth == nil {
+		return "scoped"
+	}
+	return a.auth.Name()
+}
+
+// String describes the wrapped AuthMethod, if any
+func (a *scopedAuth) String() string {
+	if a.auth == nil {
+		return "scoped"
+	}
+	return a.auth.String()
+}
+
+// scope returns an AuthMethod which authenticates as auth does, customized by the given function
+func scope(auth transport.AuthMethod, customize func(scoped *scopedAuth)) transport.AuthMethod {
+	installScopedTransports()
+
+	scoped := &scopedAuth{auth: auth}
+	existing, ok := auth.(*scopedAuth)
+	if ok {
+		copied := *existing
+		scoped = &copied
+	}
+	customize(scoped)
+	return scoped
+}
+
+// scopedTransport connects to remotes as the transport it wraps does, unless the operation is authenticated with a
+// scopedAuth: then an SSH command or rate limit may be used for that operation alone
+type scopedTransport struct {
+	scheme   string
+	previous transport.Transport
+}
+
+// NewSession starts a session with the remote at ep, using the transport the operation's auth calls for
+func (t *scopedTransport) NewSession(st storage.Storer, ep *transport.Endpoint, auth transport.AuthMethod) (transport.Session, error) {
+	scoped, ok := auth.(*scopedAuth)
+	if !ok {
+		return t.previous.NewSession(st, ep, auth)
+	}
+
+	if t.scheme == sshScheme {
+		if scoped.sshCommand != "" {
+			runner := &sshCommandRunner{command: scoped.sshCommand, rateLimit: scoped.rateLimit}
+			return transport.NewPackTransport(runner).NewSession(st, ep, nil)
+		}
+		// The in-process SSH client can't be throttled
+		return t.previous.NewSession(st, ep, scoped.auth)
+	}
+	if scoped.rateLimit != nil {
+		return scoped.rateLimit.http.NewSession(st, ep, scoped.auth)
+	}
+	return t.previous.NewSession(st, ep, scoped.auth)
+}
+
+// SupportedProtocols returns the protocol versions supported by the wrapped transport
+func (t *scopedTransport) SupportedProtocols() []protocol.Version {
+	return t.previous.SupportedProtocols()
+}
@@ -0,0 +1,102 @@
+package remote
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-git/go-git/v6/plumbing/protocol"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/go-git/go-git/v6/plumbing/transport/http"
+	"github.com/go-git/go-git/v6/storage"
+	"github.com/go-git/go-git/v6/storage/memory"
+)
+
+// errRecorded is returned by recordingTransport, so that no connection is ever made
+var errRecorded = errors.New("recorded")
+
+// recordingTransport records the AuthMethod of each session it's asked to start
+type recordingTransport struct {
+	called bool
+	auth   transport.AuthMethod
+}
+
+func (t *recordingTransport) NewSession(_ storage.Storer, _ *transport.Endpoint, auth transport.AuthMethod) (transport.Session, error) {
+	t.called = true
+	t.auth = auth
+	return nil, errRecorded
+}
+
+func (t *recordingTransport) SupportedProtocols() []protocol.Version {
+	return nil
+}
+
+func TestScopedTransport(t *testing.T) {
+	basic := &http.BasicAuth{Username: "user", Password: "secret"}
+	rateLimit := NewRateLimit(1024)
+
+	tests := []struct {
+		name   string
+		scheme string
+		auth   transport.AuthMethod
+		// wantPrevious is whether the previously registered transport should start the session
+		wantPrevious bool
+		// wantAuth is the AuthMethod the previously registered transport should be given
+		wantAuth transport.AuthMethod
+	}{
+		{name: "unscoped", scheme: "https", auth: basic, wantPrevious: true, wantAuth: basic},
+		{name: "anonymous", scheme: "https", auth: nil, wantPrevious: true, wantAuth: nil},
+		{name: "rate limited anonymous", scheme: "https", auth: rateLimit.Apply(nil)},
+		{name: "rate limited", scheme: "http", auth: rateLimit.Apply(basic)},
+		{name: "unlimited", scheme: "https", auth: (*RateLimit)(nil).Apply(basic), wantPrevious: true, wantAuth: basic},
+		{name: "ssh command", scheme: "ssh", auth: UseSSHCommand("ssh")},
+		{name: "ssh command over https", scheme: "https", auth: UseSSHCommand("ssh"), wantPrevious: true, wantAuth: nil},
+		// The in-process SSH client can't be throttled
+		{name: "rate limited ssh", scheme: "ssh", auth: rateLimit.Apply(basic), wantPrevious: true, wantAuth: basic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := &recordingTransport{}
+			scoped := &scopedTransport{scheme: tt.scheme, previous: previous}
+			endpoint, err := transport.NewEndpoint(tt.scheme + "://localhost:1/repo.git")
+			if err != nil {
+				t.Fatalf("failed to parse endpoint: %v", err)
+			}
+
+			_, err = scoped.NewSession(memory.NewStorage(), endpoint, tt.auth)
+			if previous.called != tt.wantPrevious {
+				t.Fatalf("previous transport called = %v, want %v (err: %v)", previous.called, tt.wantPrevious, err)
+			}
+			if tt.wantPrevious && previous.auth != tt.wantAuth {
+				t.Errorf("previous transport given auth %v, want %v", previous.auth, tt.wantAuth)
+			}
+		})
+	}
+}
+
+func TestScopeCopiesCustomizations(t *testing.T) {
+	command := UseSSHCommand("ssh -F none")
+	rateLimit := NewRateLimit(1024)
+
+	limited, ok := rateLimit.Apply(command).(*scopedAuth)
+	if !ok {
+		t.Fatalf("RateLimit.Apply() returned %T, want *scopedAuth", limited)
+	}
+	if limited.sshCommand != "ssh -F none" || limited.rateLimit != rateLimit {
+		t.Errorf("RateLimit.Apply() = %+v, want the SSH command and the rate limit", limited)
+	}
+	if command.(*scopedAuth).rateLimit != nil {
+		t.Error("RateLimit.Apply() modified the AuthMethod it was given")
+	}
+}
+
+func TestNewRateLimit(t *testing.T) {
+	if NewRateLimit(0) != nil {
+		t.Error("NewRateLimit(0) returned a RateLimit, want nil")
+	}
+	if NewRateLimit(-1) != nil {
+		t.Error("NewRateLimit(-1) returned a RateLimit, want nil")
+	}
+	if NewRateLimit(1) == nil {
+		t.Error("NewRateLimit(1) returned nil")
+	}
+}
@@ -0,0 +1,135 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidURL is returned for remote URLs which are malformed, or use a scheme which is not supported, before any
+// attempt is made to contact the remote
+var ErrInvalidURL = errors.New("invalid remote URL")
+
+// supportedSchemes are the schemes of the remote URLs supported by AuthMethod, in the order typos are matched against
+var supportedSchemes = []string{"https", "http", "ssh"}
+
+// unsupportedSchemes are the schemes git supports, but AuthMethod does not
+var unsupportedSchemes = []string{"git", "file", "ftp", "ftps", "sftp", "rsync"}
+
+// schemePrefix matches what looks like the scheme of a URL, along with the separator following it - whether the
+// correct '://', or a mistyped one such as ':/' or '//'
+var schemePrefix = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]*)(:/*|/{2,})(.*)$`)
+
+// hostLikePath matches paths which begin with what looks like a host name, such as 'github.com/org/repo'
+var hostLikePath = regexp.MustCompile(`^[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)+/`)
+
+// NormalizeURL checks the given remote URL for common mistakes before any network operation is attempted, returning
+// the URL in its normalized form: surrounding whitespace is removed, and the scheme is lower-cased. An error wrapping
+// ErrInvalidURL is returned for URLs which are clearly mistyped - suggesting the URL which was most likely meant, such
+// as 'https://github.com/org/repo' for 'htps://github.com/org/repo' or 'github.com/org/repo' - or whose scheme is not
+// supported, such as 'git://'
+func NormalizeURL(url string) (string, error) {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return "", fmt.Errorf("%w: no URL given", ErrInvalidURL)
+	}
+
+	match := schemePrefix.FindStringSubmatch(url)
+	if match != nil {
+		scheme, separator, rest := strings.ToLower(match[1]), match[2], match[3]
+		intended, typo := intendedScheme(scheme)
+		proper := strings.HasPrefix(separator, "://")
+		if proper && !typo {
+			if !slices.Contains(supportedSchemes, scheme) {
+				return "", unsupportedScheme(scheme, url)
+			}
+			rest = strings.TrimPrefix(separator, "://") + rest
+			if strings.HasPrefix(rest, "/") || rest == "" {
+				return "", fmt.Errorf("%w: no host given in %q", ErrInvalidURL, url)
+			}
+			return scheme + "://" + rest, nil
+		}
+		// A mistyped scheme or separator, such as 'htps://' or 'https:/'. A separator of a single ':' may instead be an
+		// scp-like URL without a user, such as 'host:org/repo', unless the host is named like a scheme
+		if intended != "" && (separator != ":" || slices.Contains(supportedSchemes, scheme)) {
+			rest = strings.TrimLeft(rest, "/")
+			if rest != "" {
+				return "", fmt.Errorf("%w: %q is malformed: did you mean %q?", ErrInvalidURL, url, intended+"://"+rest)
+			}
+		}
+		if proper {
+			return "", unsupportedScheme(scheme, url)
+		}
+		return url, nil
+	}
+
+	// 'git@host/org/repo' - an scp-like URL whose ':' was mistyped as '/'
+	user, hostAndPath, found := strings.Cut(url, "@")
+	if found && !strings.Contains(user, "/") && !strings.Contains(hostAndPath, ":") {
+		host, path, found := strings.Cut(hostAndPath, "/")
+		if found && host != "" && path != "" {
+			return "", fmt.Errorf("%w: %q is malformed: did you mean %q?", ErrInvalidURL, url, user+"@"+host+":"+path)
+		}
+	}
+
+	// 'github.com/org/repo' - a URL without any scheme
+	if hostLikePath.MatchString(url) {
+		return "", fmt.Errorf("%w: %q has no scheme: did you mean %q?", ErrInvalidURL, url, "https://"+url)
+	}
+	return url, nil
+}
+
+// unsupportedScheme explains that the scheme of the given URL is not supported
+func unsupportedScheme(scheme, url string) error {
+	return fmt.Errorf("%w: unsupported scheme %q in %q: only https://, http://, and ssh:// URLs, or scp-like URLs such as 'git@host:org/repo', are supported", ErrInvalidURL, scheme, url)
+}
+
+// intendedScheme determines the supported scheme the given scheme was most likely meant to be, reporting whether it
+// was mistyped. "" is returned if the scheme is not close to any supported scheme, or is another scheme git supports
+func intendedScheme(scheme string) (string, bool) {
+	if slices.Contains(supportedSchemes, scheme) {
+		return scheme, false
+	}
+	if slices.Contains(unsupportedSchemes, scheme) {
+		return "", false
+	}
+
+	intended := ""
+	best := 0
+	for _, supported := range supportedSchemes {
+		distance := editDistance(scheme, supported)
+		// Short schemes are too easily mistaken for one another, such as 's3' for 'ssh'
+		if distance > 2 || distance >= len(scheme) {
+			continue
+		}
+		if intended == "" || distance < best {
+			intended = supported
+			best = distance
+		}
+	}
+	return intended, intended != ""
+}
+
+// editDistance computes the Levenshtein distance between a and b: the number of single-character insertions,
+// deletions, or substitutions which turn one into the other
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
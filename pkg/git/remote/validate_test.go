@@ -0,0 +1,83 @@
+package remote
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+		// wantErr is a substring of the expected error, or "" if the URL is valid
+		wantErr string
+	}{
+		{name: "https", url: "https://github.com/org/repo", want: "https://github.com/org/repo"},
+		{name: "http", url: "http://localhost:8080/repo.git", want: "http://localhost:8080/repo.git"},
+		{name: "ssh", url: "ssh://git@github.com/org/repo", want: "ssh://git@github.com/org/repo"},
+		{name: "scp-like", url: "git@github.com:org/repo.git", want: "git@github.com:org/repo.git"},
+		{name: "scp-like without user", url: "github.com:org/repo", want: "github.com:org/repo"},
+		{name: "surrounding whitespace", url: "  https://github.com/org/repo\n", want: "https://github.com/org/repo"},
+		{name: "upper-case scheme", url: "HTTPS://github.com/org/repo", want: "https://github.com/org/repo"},
+		{name: "empty", url: " ", wantErr: "no URL given"},
+		{name: "no host", url: "https:///org/repo", wantErr: "no host given"},
+		{name: "mistyped scheme", url: "htps://github.com/org/repo", wantErr: `did you mean "https://github.com/org/repo"?`},
+		{name: "mistyped separator", url: "https:/github.com/org/repo", wantErr: `did you mean "https://github.com/org/repo"?`},
+		{name: "missing colon", url: "https//github.com/org/repo", wantErr: `did you mean "https://github.com/org/repo"?`},
+		{name: "scp-like with slash", url: "git@github.com/org/repo", wantErr: `did you mean "git@github.com:org/repo"?`},
+		{name: "no scheme", url: "github.com/org/repo", wantErr: `did you mean "https://github.com/org/repo"?`},
+		{name: "git scheme", url: "git://github.com/org/repo", wantErr: `unsupported scheme "git"`},
+		{name: "file scheme", url: "file:///srv/repo.git", wantErr: `unsupported scheme "file"`},
+		{name: "unknown scheme", url: "s3://bucket/repo", wantErr: `unsupported scheme "s3"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeURL(tt.url)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("NormalizeURL(%q) = %q, want an error", tt.url, got)
+				}
+				if !errors.Is(err, ErrInvalidURL) {
+					t.Errorf("NormalizeURL(%q) returned error %v, want one wrapping ErrInvalidURL", tt.url, err)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("NormalizeURL(%q) returned error %q, want it to contain %q", tt.url, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeURL(%q) returned error: %v", tt.url, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeURL(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIntendedScheme(t *testing.T) {
+	tests := []struct {
+		scheme   string
+		want     string
+		wantTypo bool
+	}{
+		{scheme: "https", want: "https"},
+		{scheme: "ssh", want: "ssh"},
+		{scheme: "htps", want: "https", wantTypo: true},
+		{scheme: "htttp", want: "http", wantTypo: true},
+		{scheme: "shh", want: "ssh", wantTypo: true},
+		{scheme: "git", want: ""},
+		{scheme: "s3", want: ""},
+		{scheme: "mailto", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scheme, func(t *testing.T) {
+			got, typo := intendedScheme(tt.scheme)
+			if got != tt.want || typo != tt.wantTypo {
+				t.Errorf("intendedScheme(%q) = %q, %v, want %q, %v", tt.scheme, got, typo, tt.want, tt.wantTypo)
+			}
+		})
+	}
+}
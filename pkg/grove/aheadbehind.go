@@ -0,0 +1,82 @@
+package grove
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tnierman/git-grove/pkg/git/local"
+)
+
+// Comparison describes how far the branch of a tree has diverged from a base
+type Comparison struct {
+	// Tree is the tree which was compared
+	Tree Tree
+	// Base is the revision the tree was compared with
+	Base string
+	// MergeBase is the commit hash of the best common ancestor of the tree's HEAD and Base. It is empty when the two
+	// share no history
+	MergeBase string
+	// Ahead is the number of commits in the tree's HEAD which are not in Base
+	Ahead int
+	// Behind is the number of commits in Base which are not in the tree's HEAD
+	Behind int
+}
+
+// AheadBehind compares the HEAD of the tree at the given path with base, counting the commits each has which the
+// other does not. If base is empty, the upstream of the tree's branch is used, falling back to the grove's default
+// branch if it has none
+func (g *Grove) AheadBehind(path, base string) (Comparison, error) {
+	head, err := g.treeHead(path)
+	if err != nil {
+		return Comparison{}, err
+	}
+	tree, err := g.Tree(path)
+	if err != nil {
+		return Comparison{}, err
+	}
+
+	if base == "" {
+		base, err = g.defaultBase(tree)
+		if err != nil {
+			return Comparison{}, err
+		}
+	}
+	baseHash, err := g.repo.ResolveCommit(base)
+	if err != nil {
+		return Comparison{}, err
+	}
+
+	comparison := Comparison{
+		Tree: tree,
+		Base: base,
+	}
+	mergeBase, err := g.repo.MergeBase(head, baseHash)
+	if err != nil {
+		return Comparison{}, err
+	}
+	if !mergeBase.IsZero() {
+		comparison.MergeBase = mergeBase.String()
+	}
+
+	comparison.Ahead, comparison.Behind, err = g.repo.AheadBehind(head, baseHash)
+	if err != nil {
+		return Comparison{}, fmt.Errorf("failed to compare tree %q with %q: %w", tree.Path, base, err)
+	}
+	return comparison, nil
+}
+
+// defaultBase determines the revision the given tree is compared with when none is provided: the upstream of its
+// branch, or otherwise the grove's default branch
+func (g *Grove) defaultBase(tree Tree) (string, error) {
+	if tree.Branch != "" {
+		upstream, err := g.repo.Upstream(tree.Branch)
+		if err == nil {
+			return upstream.TrackingRef.Short(), nil
+		}
+		if !errors.Is(err, local.ErrNoUpstream) {
+			return "", err
+		}
+	}
+
+	return g.DefaultBranch()
+}
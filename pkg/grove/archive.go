@@ -0,0 +1,32 @@
+package grove
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/tnierman/git-grove/pkg/git/local"
+)
+
+// Archive writes the files committed at the HEAD of the tree at the given path - or at revision, if given - to w as an
+// archive of the given format. Uncommitted changes are not included. Each file's path within the archive is prefixed
+// with prefix, if given
+func (g *Grove) Archive(w io.Writer, path, revision string, format local.ArchiveFormat, prefix string) error {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return err
+	}
+
+	commit := plumbing.NewHash(tree.Hash)
+	if revision != "" {
+		commit, err = g.repo.ResolveCommit(revision)
+		if err != nil {
+			return err
+		}
+	}
+	if commit.IsZero() {
+		return fmt.Errorf("tree %q has no commits to archive", tree.Path)
+	}
+
+	return g.repo.Archive(w, commit, format, prefix)
+}
@@ -0,0 +1,76 @@
+package grove
+
+import (
+	"fmt"
+	"sort"
+)
+
+// BranchInfo describes a branch which can be checked out in the grove: a local branch, a branch of one of the grove's
+// remotes, or both
+type BranchInfo struct {
+	// Name is the short name of the branch, ie: 'main' rather than 'origin/main'
+	Name string `json:"name"`
+	// Local indicates whether a local branch of the same name exists
+	Local bool `json:"local"`
+	// Remotes are the names of the remotes with a branch of the same name, as of their last fetch
+	Remotes []string `json:"remotes,omitempty"`
+	// Tree is the absolute path of the tree the branch is checked out in. It is empty when the branch has no tree
+	Tree string `json:"tree,omitempty"`
+}
+
+// Branches lists every local branch and remote-tracking branch of the grove, sorted by name. Branches of the same name
+// are combined, so that a local branch and the remote branch it was created from are listed once
+func (g *Grove) Branches() ([]BranchInfo, error) {
+	byName := map[string]*BranchInfo{}
+	branch := func(name string) *BranchInfo {
+		info, found := byName[name]
+		if !found {
+			info = &BranchInfo{Name: name}
+			byName[name] = info
+		}
+		return info
+	}
+
+	locals, err := g.repo.LocalBranches()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range locals {
+		branch(name).Local = true
+	}
+
+	remotes, err := g.repo.Remotes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remotes: %w", err)
+	}
+	for _, remote := range remotes {
+		remoteBranches, err := g.repo.RemoteBranches(remote.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, remoteBranch := range remoteBranches {
+			info := branch(remoteBranch.Name)
+			info.Remotes = append(info.Remotes, remote.Name)
+		}
+	}
+
+	trees, err := g.Trees()
+	if err != nil {
+		return nil, err
+	}
+	for _, tree := range trees {
+		info, found := byName[tree.Branch]
+		if found {
+			info.Tree = tree.Path
+		}
+	}
+
+	branches := make([]BranchInfo, 0, len(byName))
+	for _, info := range byName {
+		branches = append(branches, *info)
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		return branches[i].Name < branches[j].Name
+	})
+	return branches, nil
+}
@@ -0,0 +1,88 @@
+package grove
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/tnierman/git-grove/pkg/git/local"
+)
+
+// CheckoutResult describes what CheckoutTree checked out
+type CheckoutResult struct {
+	// Branch is the branch checked out. It is empty when a commit was checked out with a detached HEAD
+	Branch string
+	// Commit is the commit the tree's HEAD now points to
+	Commit string
+	// Created indicates that Branch was created from the remote-tracking branch of the same name, which it tracks
+	Created bool
+}
+
+// CheckoutTree checks out ref in the tree at the given path, as with 'git checkout': a local branch is checked out as
+// is, and a branch which only exists on a remote is created from the remote's branch, tracking it. Any other ref - such
+// as a tag, commit, or remote-tracking branch - is checked out with a detached HEAD.
+//
+// A branch checked out in another tree cannot be checked out. Unless force is set, an error wrapping
+// local.ErrUncommittedChanges is returned if the tree has uncommitted changes to tracked files, which would be lost
+func (g *Grove) CheckoutTree(path, ref string, force bool) (CheckoutResult, error) {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return CheckoutResult{}, err
+	}
+
+	opts := local.CheckoutOptions{
+		Force: force,
+	}
+	upstream, track, err := g.repo.TrackingBranch(ref)
+	if err != nil {
+		return CheckoutResult{}, err
+	}
+	_, err = g.repo.ResolveReference(plumbing.NewBranchReferenceName(ref))
+	switch {
+	case err == nil:
+		opts.Branch = ref
+	case track && upstream.Name == ref:
+		// As with git, only a branch's bare name creates it: naming the remote-tracking branch detaches HEAD instead
+		opts.Branch = ref
+		opts.Create = true
+		opts.Commit = upstream.Head
+	default:
+		opts.Commit, err = g.repo.ResolveCommit(ref)
+		if err != nil {
+			return CheckoutResult{}, fmt.Errorf("no branch, tag, or commit named %q exists: %w", ref, err)
+		}
+	}
+
+	if opts.Branch != "" && opts.Branch != tree.Branch {
+		// A branch can only be checked out in one worktree at a time
+		err = g.checkBranchAvailable(opts.Branch)
+		if err != nil {
+			return CheckoutResult{}, err
+		}
+	}
+
+	err = g.repo.CheckoutWorktree(tree.Path, opts)
+	if err != nil {
+		return CheckoutResult{}, err
+	}
+	if opts.Create {
+		err = g.repo.SetUpstream(ref, upstream.Remote, plumbing.NewBranchReferenceName(upstream.Name))
+		if err != nil {
+			return CheckoutResult{}, fmt.Errorf("branch %q was checked out, but %w", ref, err)
+		}
+	}
+
+	checkedOut, err := g.Tree(tree.Path)
+	if err != nil {
+		return CheckoutResult{}, err
+	}
+	previous := plumbing.ZeroHash
+	if tree.Hash != "" {
+		previous = plumbing.NewHash(tree.Hash)
+	}
+	// As with git, the post-checkout hook is told whether a branch was checked out, rather than a single file
+	_, err = g.repo.RunHook(tree.Path, local.PostCheckoutHook, previous.String(), checkedOut.Hash, "1")
+	if err != nil {
+		return CheckoutResult{}, fmt.Errorf("%q was checked out, but %w", ref, err)
+	}
+	return CheckoutResult{Branch: checkedOut.Branch, Commit: checkedOut.Hash, Created: opts.Create}, nil
+}
@@ -0,0 +1,17 @@
+package grove
+
+import (
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// Commit records the changes staged in the tree at the given path as a new commit with the given message. If all is
+// set, changes to tracked files are staged first; untracked files are never staged.
+//
+// The new commit is returned
+func (g *Grove) Commit(path, message string, all bool) (plumbing.Hash, error) {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return g.repo.Commit(tree.Path, message, all)
+}
@@ -0,0 +1,25 @@
+package grove
+
+import (
+	"fmt"
+)
+
+// DefaultBranch returns the grove's default branch: the branch HEAD points to on the grove's remote, if recorded,
+// otherwise the branch the repository's HEAD points to
+func (g *Grove) DefaultBranch() (string, error) {
+	branch, err := g.repo.DefaultBranch()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine default branch: %w", err)
+	}
+	return branch, nil
+}
+
+// SetDefaultBranch changes the grove's default branch to the given branch, which must exist on the grove's remote -
+// 'origin', if it exists, otherwise the first remote by name
+func (g *Grove) SetDefaultBranch(branch string) error {
+	remote, err := g.repo.DefaultRemote()
+	if err != nil {
+		return fmt.Errorf("failed to set default branch: %w", err)
+	}
+	return g.repo.SetDefaultBranch(remote, branch)
+}
@@ -0,0 +1,34 @@
+package grove
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+)
+
+// Diff computes the changes between the HEAD commits of the trees at the given paths: the returned patch turns the
+// committed files of the tree at from into those of the tree at to. Uncommitted changes are not included
+func (g *Grove) Diff(from, to string) (*object.Patch, error) {
+	fromHash, err := g.treeHead(from)
+	if err != nil {
+		return nil, err
+	}
+	toHash, err := g.treeHead(to)
+	if err != nil {
+		return nil, err
+	}
+	return g.repo.Diff(fromHash, toHash)
+}
+
+// treeHead returns the commit the HEAD of the tree at the given path points to
+func (g *Grove) treeHead(path string) (plumbing.Hash, error) {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if tree.Hash == "" {
+		return plumbing.ZeroHash, fmt.Errorf("tree %q has no commits", tree.Path)
+	}
+	return plumbing.NewHash(tree.Hash), nil
+}
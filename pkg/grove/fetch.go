@@ -0,0 +1,73 @@
+package grove
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+)
+
+// FetchResult records the outcome of fetching a single remote as part of FetchRemotes
+type FetchResult struct {
+	// Remote is the name of the remote
+	Remote string
+	// Duration is how long fetching from the remote took, excluding resolving its credentials
+	Duration time.Duration
+	// Err is the error encountered while authenticating with, or fetching from, the remote, if any
+	Err error
+}
+
+// FetchRemotes updates the remote-tracking branches of each of the given remotes, fetching their configured refspecs
+// using up to parallel concurrent workers. Progress information sent by each remote is written to progress, if not nil -
+// which interleaves when fetching concurrently.
+//
+// Credentials are resolved for every remote first, one remote at a time, so that prompts for them are never
+// interleaved; only the transfers run concurrently, each with its own instance of the repository. A failure to fetch one
+// remote does not prevent the others from being fetched; the outcome of each is returned, in the order given. Once ctx
+// is cancelled, no further remotes are fetched, and the remaining results record ctx's error
+func (g *Grove) FetchRemotes(ctx context.Context, remotes []string, parallel int, progress io.Writer) []FetchResult {
+	results := make([]FetchResult, len(remotes))
+	auths := make([]transport.AuthMethod, len(remotes))
+	for i, remote := range remotes {
+		results[i].Remote = remote
+		auths[i], results[i].Err = g.RemoteAuth(remote)
+	}
+
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for range parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					results[i].Err = ctx.Err()
+					continue
+				}
+				repo, err := g.repo.Reopen()
+				if err != nil {
+					results[i].Err = err
+					continue
+				}
+				start := time.Now()
+				results[i].Err = (&Grove{repo: repo}).Fetch(ctx, results[i].Remote, nil, auths[i], progress)
+				results[i].Duration = time.Since(start)
+			}
+		}()
+	}
+	for i := range remotes {
+		if results[i].Err == nil {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,31 @@
+package grove
+
+import (
+	"io"
+)
+
+// GCResult describes the effect of a garbage collection on the size of the grove's repository
+type GCResult struct {
+	// Before is the size of the repository in bytes before collecting garbage
+	Before int64
+	// After is the size of the repository in bytes after collecting garbage
+	After int64
+}
+
+// GarbageCollect compacts the grove's repository, whose object store is shared by every tree. If aggressive is set,
+// the repository is compacted more thoroughly, but much more slowly. Output from the collection is written to out
+func (g *Grove) GarbageCollect(aggressive bool, out io.Writer) (GCResult, error) {
+	before, err := g.repo.StoreSize()
+	if err != nil {
+		return GCResult{}, err
+	}
+	err = g.repo.GarbageCollect(aggressive, out)
+	if err != nil {
+		return GCResult{}, err
+	}
+	after, err := g.repo.StoreSize()
+	if err != nil {
+		return GCResult{}, err
+	}
+	return GCResult{Before: before, After: after}, nil
+}
@@ -1,32 +1,83 @@
 package grove
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
-	"strings"
+	"sync"
 
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/go-git/go-git/v6/storage/filesystem"
+	"github.com/tnierman/git-grove/pkg/config"
 	"github.com/tnierman/git-grove/pkg/git/local"
+	gitremote "github.com/tnierman/git-grove/pkg/git/remote"
 )
 
+// BareGitDir is the name of the hidden directory at the root of a bare grove which holds its bare repository
+const BareGitDir = ".bare"
+
 type Grove struct {
+	// Layout determines where the trees created for branches are placed within the grove
+	Layout Layout
+	// SubmoduleAuth determines how to authenticate with the submodules updated by UpdateSubmodules. Defaults to
+	// authenticating as when fetching from a remote
+	SubmoduleAuth SubmoduleAuth
+	// RateLimit throttles the transfers authenticated by RemoteAuth. Defaults to nil, in which case transfers are not
+	// limited
+	RateLimit *gitremote.RateLimit
+
 	repo *local.Repository
+
+	// submoduleAuths caches the credentials determined for each submodule URL
+	submoduleAuths   map[string]transport.AuthMethod
+	submoduleAuthsMu sync.Mutex
+}
+
+// Tree describes a single worktree within the grove
+type Tree struct {
+	// Branch is the short name of the branch checked out in the tree. It is empty when the tree has a detached HEAD
+	Branch string `json:"branch,omitempty"`
+	// Path is the absolute path to the root of the tree, with any symlinks resolved
+	Path string `json:"path"`
+	// Hash is the commit hash the tree's HEAD currently points to
+	Hash string `json:"hash,omitempty"`
+	// Locked indicates whether the tree is locked, protecting it from being pruned
+	Locked bool `json:"locked"`
+	// LockReason is the reason given when the tree was locked, if any
+	LockReason string `json:"lockReason,omitempty"`
 }
 
-// Open creates a new grove
-func Init() (*Grove, error) {
-	// We can safely assume that this operation is either being executed A) directly within the
-	// main worktree itself, or B) within a directory that has $GIT_COMMON_DIR set (either via
-	// non-main worktree initialization or via grove itself). If this is not the main worktree or
-	// an environment with $GIT_COMMON_DIR set, then it's invalid to perform grove operations anyway
+// Open opens the existing grove containing the current working directory
+func Open() (*Grove, error) {
 	cwd, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine current working directory: %w", err)
 	}
 
-	repo, err := local.NewRepository(cwd)
+	// Grove operations may be run from any directory within a tree, or from the root of a bare grove, so walk
+	// up to the root of the enclosing worktree before opening the repository
+	root, err := local.FindWorktreeRoot(cwd)
+	if err != nil {
+		// Outside of any tree, such as at the root of a grove which isn't bare, the grove is found by its marker
+		marked, found, markerErr := findMarkedRoot(cwd)
+		if markerErr == nil && found {
+			return openMarkedRoot(marked)
+		}
+		return nil, fmt.Errorf("no grove found at %q or any of its parent directories: %w", cwd, err)
+	}
+	return OpenAt(root)
+}
+
+// OpenAt opens the existing grove containing the worktree at the given path
+func OpenAt(path string) (*Grove, error) {
+	repo, err := local.NewRepository(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize git repo %q: %w", cwd, err)
+		return nil, fmt.Errorf("failed to open git repo %q: %w", path, err)
 	}
 
 	g := &Grove{
@@ -36,39 +87,668 @@ func Init() (*Grove, error) {
 	return g, nil
 }
 
-// Root gives the absolute path of the root directory of the grove
+// FromRepository constructs a grove around a repository which has already been opened or cloned, such as by a
+// library consumer using go-git directly. The repository must be stored on disk, as a worktree of the grove rooted at
+// the given directory
+func FromRepository(repo *git.Repository, root string) (*Grove, error) {
+	if repo == nil {
+		return nil, fmt.Errorf("no repository provided")
+	}
+	if _, ok := repo.Storer.(*filesystem.Storage); !ok {
+		return nil, fmt.Errorf("repository is not stored on disk: groves require a repository on the local filesystem")
+	}
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine absolute path of %q: %w", root, err)
+	}
+	root = canonicalPath(root)
+
+	g := &Grove{
+		repo: local.FromRepository(repo, root),
+	}
+
+	// A grove's root is derived from the layout of its repository, so make sure the two agree
+	actual, err := g.Root()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine grove root of repository: %w", err)
+	}
+	if filepath.Clean(actual) != root {
+		return nil, fmt.Errorf("repository belongs to the grove at %q, not %q", actual, root)
+	}
+	return g, nil
+}
+
+// IsRoot reports whether the directory at path looks like the root of a grove: either it holds a bare repository in
+// BareGitDir or grove's configuration, or it is not a repository itself, but one of its subdirectories is the main
+// worktree of a repository with linked worktrees beneath path
+func IsRoot(path string) (bool, error) {
+	info, err := os.Stat(local.GitPath(path))
+	if err == nil && info.IsDir() {
+		// The root of a standalone repository
+		return false, nil
+	}
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("failed to check %q: %w", local.GitPath(path), err)
+	}
+	for _, dir := range []string{BareGitDir, config.GroveDir} {
+		info, err := os.Stat(filepath.Join(path, dir))
+		if err == nil && info.IsDir() {
+			return true, nil
+		}
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %q: %w", path, err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		mainWorktree := filepath.Join(path, entry.Name())
+		info, err := os.Stat(local.GitPath(mainWorktree))
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		repo, err := local.NewRepository(mainWorktree)
+		if err != nil {
+			continue
+		}
+		worktrees, err := repo.Worktrees()
+		if err != nil {
+			return false, fmt.Errorf("failed to list worktrees of %q: %w", mainWorktree, err)
+		}
+		for _, worktree := range worktrees {
+			if !worktree.Main && within(canonicalPath(path), canonicalPath(worktree.Path)) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// ErrNotGrove is returned when a repository's layout does not match that of a grove, so that its root cannot be
+// determined
+var ErrNotGrove = errors.New("repository is not a grove")
+
+// Root gives the absolute path of the root directory of the grove: the nearest directory above the repository's git
+// directory holding a RootMarker, or for groves without a marker, the directory derived from the repository's layout.
+//
+// An error wrapping ErrNotGrove is returned if the repository is not laid out as a grove, rather than a root which
+// commands would go on to act upon.
+//
+// The root is given in its canonical form, with any symlinks resolved, as are the paths of the grove's trees - so that
+// they can be compared regardless of whether the grove was reached through a symlink
 func (g *Grove) Root() (string, error) {
+	bare, err := g.repo.IsBare()
+	if err != nil {
+		return "", err
+	}
+	commonDir, err := g.repo.CommonDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine path to git directory of repository: %w", err)
+	}
+	// A bare repository's directory lies directly within the root, while a main worktree's .git/ lies within the
+	// worktree, beneath the root
+	start := filepath.Dir(commonDir)
+	if !bare {
+		start = filepath.Dir(start)
+	}
+	marked, found, err := findMarkedRoot(start)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return canonicalPath(marked), nil
+	}
+
+	if bare {
+		// Bare groves keep the repository in a hidden directory directly beneath the grove's root
+		if !filepath.IsAbs(commonDir) || filepath.Base(commonDir) != BareGitDir {
+			return "", fmt.Errorf("%w: bare repository %q is not held in %s at the grove's root", ErrNotGrove, commonDir, BareGitDir)
+		}
+		return canonicalPath(filepath.Dir(commonDir)), nil
+	}
+
 	mainWorktree, err := g.repo.MainWorktree()
 	if err != nil {
 		return "", fmt.Errorf("failed to determine path to main worktree of repository: %w", err)
 	}
+	if !filepath.IsAbs(mainWorktree) {
+		return "", fmt.Errorf("%w: main worktree %q is not an absolute path", ErrNotGrove, mainWorktree)
+	}
 
 	// The grove's root directory is always one directory level above the root of the main worktree
-	return filepath.Clean(filepath.Join(mainWorktree, "..")), nil
+	root := filepath.Dir(filepath.Clean(mainWorktree))
+	if root == filepath.Clean(mainWorktree) {
+		return "", fmt.Errorf("%w: main worktree %q has no parent directory to act as the grove's root", ErrNotGrove, mainWorktree)
+	}
+	info, err := os.Stat(local.GitPath(mainWorktree))
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%w: main worktree %q does not hold the repository's git directory", ErrNotGrove, mainWorktree)
+	}
+	return canonicalPath(root), nil
+}
+
+// AddTreeOptions configures how AddTree creates a new tree
+type AddTreeOptions struct {
+	// Branch is the branch checked out in the new tree. If the branch does not already exist, it is created
+	// at Commit. Defaults to the last element of the tree's path
+	Branch string
+	// Commit is the revision - a commit hash, tag, or branch - a newly-created Branch starts at. Defaults to
+	// the main worktree's HEAD
+	Commit string
+	// Detach checks out Commit in the new tree with a detached HEAD, rather than checking out a Branch
+	Detach bool
+	// Template is a directory whose contents are copied into the new tree once it is checked out, without being
+	// committed. Files which would overwrite those checked out are skipped
+	Template string
+	// NoTrack skips configuring a newly-created Branch to track the remote-tracking branch it was created from. By
+	// default, a Branch which only exists on a remote - or names a remote-tracking branch, such as 'origin/feature' - is
+	// created at the remote's branch, and tracks it, as with 'git worktree add'
+	NoTrack bool
+	// NoHooks skips running the post-checkout hook in the new tree, which is otherwise run once it is checked out -
+	// and Template copied into it - as 'git worktree add' does
+	NoHooks bool
+	// RecurseSubmodules initializes and checks out the new tree's submodules, recursively, once it is checked out - see
+	// UpdateSubmodules
+	RecurseSubmodules bool
 }
 
-// AddTree creates a new worktree at the given path relative to the grove's root, unless prefixed with /
+// AddTree creates a new worktree at the given path relative to the grove's root, unless already absolute
 //
+// A BranchCheckedOutError is returned if the branch to check out is already checked out in another tree.
 // If the provided path contains a directory that does not exist, it will be created with mode 0700
-func (g *Grove) AddTree(path string) error {
-	if !strings.HasPrefix(path, "/") {
-		// Absolute path not provided: construct absolute path of new worktree relative to grove root
-		root, err := g.Root()
+func (g *Grove) AddTree(path string, opts AddTreeOptions) error {
+	path, err := g.resolvePath(path)
+	if err != nil {
+		return err
+	}
+
+	if opts.Detach && opts.Commit == "" {
+		return fmt.Errorf("a commit must be provided to create a tree with a detached HEAD")
+	}
+
+	branch := opts.Branch
+	if branch == "" {
+		branch = filepath.Base(path)
+	}
+	var upstream local.RemoteBranch
+	track := false
+	if !opts.Detach {
+		upstream, track, err = g.repo.TrackingBranch(branch)
+		if err != nil {
+			return err
+		}
+		if track {
+			branch = upstream.Name
+			// A branch named by its remote-tracking branch may already exist locally, in which case it's checked out as is
+			_, err = g.repo.ResolveReference(plumbing.NewBranchReferenceName(branch))
+			track = err != nil
+		}
+
+		// A branch can only be checked out in one worktree at a time
+		err = g.checkBranchAvailable(branch)
 		if err != nil {
-			return fmt.Errorf("failed to determine grove root: %w", err)
+			return err
 		}
-		path = filepath.Join(root, path)
 	}
 
-	err := os.MkdirAll(path, 0o700)
+	// Resolve the commit and template before creating any directories, so nothing is left behind if either is invalid
+	template := ""
+	if opts.Template != "" {
+		template, err = checkTemplate(opts.Template)
+		if err != nil {
+			return err
+		}
+	}
+	commit := plumbing.ZeroHash
+	if opts.Commit != "" {
+		commit, err = g.repo.ResolveCommit(opts.Commit)
+		if err != nil {
+			return err
+		}
+	}
+	if track {
+		// As with git, a branch only tracks the remote-tracking branch it starts at
+		if commit.IsZero() {
+			commit = upstream.Head
+		}
+		track = commit == upstream.Head
+	}
+
+	created, err := createDirs(path)
 	if err != nil {
-		return fmt.Errorf("failed to create directory %q: %w", path, err)
+		return err
 	}
 
-	err = g.repo.AddWorktree(path)
+	err = g.repo.AddWorktree(path, local.AddWorktreeOptions{
+		Branch: branch,
+		Commit: commit,
+		Detach: opts.Detach,
+	})
 	if err != nil {
+		// Roll back any directories created above, so that a retry isn't blocked by them. Directories which
+		// already existed are left in place
+		if created != "" {
+			removeErr := os.RemoveAll(created)
+			if removeErr != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to cleanup directory %q: %v\n", created, removeErr)
+			}
+		}
 		return fmt.Errorf("failed to create worktree %q: %w", path, err)
 	}
 
+	if track && !opts.NoTrack {
+		err = g.repo.SetUpstream(branch, upstream.Remote, plumbing.NewBranchReferenceName(upstream.Name))
+		if err != nil {
+			return fmt.Errorf("tree %q was created, but %w", path, err)
+		}
+	}
+
+	if template != "" {
+		err = copyTemplate(template, path)
+		if err != nil {
+			return fmt.Errorf("failed to copy template %q into tree %q: %w", template, path, err)
+		}
+	}
+
+	if opts.RecurseSubmodules {
+		err = g.updateSubmodules(context.Background(), path)
+		if err != nil {
+			return fmt.Errorf("tree %q was created, but its submodules could not be updated: %w", path, err)
+		}
+	}
+
+	if !opts.NoHooks {
+		err = g.runPostCheckout(path)
+		if err != nil {
+			return fmt.Errorf("tree %q was created, but %w", path, err)
+		}
+	}
+	return nil
+}
+
+// runPostCheckout runs the post-checkout hook in the newly-created tree at path, with the arguments given by
+// 'git worktree add': the null commit as the previous HEAD, the tree's HEAD, and a flag indicating a branch checkout
+func (g *Grove) runPostCheckout(path string) error {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return err
+	}
+	head := plumbing.ZeroHash
+	if tree.Hash != "" {
+		head = plumbing.NewHash(tree.Hash)
+	}
+	_, err = g.repo.RunHook(path, local.PostCheckoutHook, plumbing.ZeroHash.String(), head.String(), "1")
+	return err
+}
+
+// LocalBranch returns the name of the local branch checked out by AddTree for branch: when branch names a
+// remote-tracking branch, such as 'origin/feature', the local branch is named after the remote's branch - 'feature'
+func (g *Grove) LocalBranch(branch string) (string, error) {
+	upstream, track, err := g.repo.TrackingBranch(branch)
+	if err != nil {
+		return "", err
+	}
+	if !track {
+		return branch, nil
+	}
+	return upstream.Name, nil
+}
+
+// BranchCheckedOutError is returned when a tree cannot be created for a branch, because the branch is already checked
+// out in another tree
+type BranchCheckedOutError struct {
+	// Branch is the branch which was to be checked out
+	Branch string
+	// Tree is the absolute path of the tree the branch is checked out in
+	Tree string
+}
+
+func (e *BranchCheckedOutError) Error() string {
+	return fmt.Sprintf("branch %q is already checked out in tree %q", e.Branch, e.Tree)
+}
+
+// checkBranchAvailable returns a BranchCheckedOutError if the given branch is checked out in any tree of the grove
+func (g *Grove) checkBranchAvailable(branch string) error {
+	trees, err := g.Trees()
+	if err != nil {
+		return err
+	}
+	for _, tree := range trees {
+		if tree.Branch == branch {
+			return &BranchCheckedOutError{Branch: branch, Tree: tree.Path}
+		}
+	}
 	return nil
 }
+
+// BranchTreePath returns the absolute path at which the tree for the given branch is placed, according to the grove's
+// Layout
+func (g *Grove) BranchTreePath(branch string) (string, error) {
+	relative, err := g.Layout.TreePath(branch)
+	if err != nil {
+		return "", err
+	}
+	root, err := g.Root()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine grove root: %w", err)
+	}
+	return filepath.Join(root, relative), nil
+}
+
+// createDirs creates the directory at path, along with any missing parents, with mode 0700. The topmost directory
+// which was created is returned, or "" if the directory already existed
+func createDirs(path string) (string, error) {
+	created := ""
+	for dir := path; ; dir = filepath.Dir(dir) {
+		_, err := os.Stat(dir)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("failed to check %q: %w", dir, err)
+		}
+		created = dir
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+
+	err := os.MkdirAll(path, 0o700)
+	if err != nil {
+		return "", fmt.Errorf("failed to create directory %q: %w", path, err)
+	}
+	return created, nil
+}
+
+// Trees lists every tree in the grove, starting with the main worktree unless the grove is bare
+func (g *Grove) Trees() ([]Tree, error) {
+	worktrees, err := g.repo.Worktrees()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	trees := make([]Tree, 0, len(worktrees))
+	for _, worktree := range worktrees {
+		tree := Tree{
+			Branch:     worktree.Branch,
+			Path:       canonicalPath(worktree.Path),
+			Locked:     worktree.Locked,
+			LockReason: worktree.LockReason,
+		}
+		if !worktree.Head.IsZero() {
+			tree.Hash = worktree.Head.String()
+		}
+		trees = append(trees, tree)
+	}
+	return trees, nil
+}
+
+// Tree retrieves the tree at the given path relative to the grove's root, unless already absolute
+func (g *Grove) Tree(path string) (Tree, error) {
+	path, err := g.resolvePath(path)
+	if err != nil {
+		return Tree{}, err
+	}
+
+	trees, err := g.Trees()
+	if err != nil {
+		return Tree{}, err
+	}
+	for _, tree := range trees {
+		if filepath.Clean(tree.Path) == path {
+			return tree, nil
+		}
+	}
+	return Tree{}, fmt.Errorf("no tree found at %q", path)
+}
+
+// CurrentTree retrieves the tree containing the current working directory
+func (g *Grove) CurrentTree() (Tree, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return Tree{}, fmt.Errorf("failed to determine current working directory: %w", err)
+	}
+	path, err := local.FindWorktreeRoot(cwd)
+	if err != nil {
+		return Tree{}, fmt.Errorf("%q is not within a tree: %w", cwd, err)
+	}
+	return g.Tree(path)
+}
+
+// CurrentOrNamedTree retrieves the tree at the given path, or the tree containing the current working directory if path
+// is empty
+func (g *Grove) CurrentOrNamedTree(path string) (Tree, error) {
+	if path == "" {
+		tree, err := g.CurrentTree()
+		if err != nil {
+			return Tree{}, fmt.Errorf("failed to determine current tree: %w", err)
+		}
+		return tree, nil
+	}
+	return g.Tree(path)
+}
+
+// RenameBranch renames the branch checked out in the tree at the given path to branch, and moves the tree
+// to the branch's directory according to the grove's Layout, so that the tree's directory continues to reflect
+// its branch.
+//
+// An error is returned if the tree has a detached HEAD, or if the destination directory already exists
+func (g *Grove) RenameBranch(path, branch string) error {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return err
+	}
+	if tree.Branch == "" {
+		return fmt.Errorf("tree %q has a detached HEAD: no branch to rename", tree.Path)
+	}
+
+	destination, err := g.BranchTreePath(branch)
+	if err != nil {
+		return err
+	}
+
+	_, err = os.Stat(destination)
+	if err == nil {
+		return fmt.Errorf("cannot move tree %q: %q already exists", tree.Path, destination)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check %q: %w", destination, err)
+	}
+
+	// Rename the branch first: it is validated by git, and the repository may not be usable once the tree has moved
+	err = g.repo.RenameBranch(tree.Branch, branch)
+	if err != nil {
+		return fmt.Errorf("failed to rename branch %q to %q: %w", tree.Branch, branch, err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(destination), 0o700)
+	if err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", filepath.Dir(destination), err)
+	}
+
+	err = g.repo.MoveWorktree(tree.Path, destination)
+	if err != nil {
+		return fmt.Errorf("failed to move tree %q to %q: %w", tree.Path, destination, err)
+	}
+	return nil
+}
+
+// TreeResult records the outcome of creating a single tree as part of a bulk operation
+type TreeResult struct {
+	// Path is the absolute path of the tree
+	Path string
+	// Branch is the branch checked out in the tree
+	Branch string
+	// Err is the error encountered while creating the tree, if any
+	Err error
+}
+
+// AddRemoteBranchTrees creates a tree for each branch of the given remote which does not already have a local
+// branch checked out in the grove. If any patterns are given, only branches whose names match one of them - as with
+// path.Match, such as 'release/*' - are included. Each tree is placed within the grove according to its Layout, using
+// up to parallel concurrent workers. Each new branch tracks the remote's branch it was created from. If
+// recurseSubmodules is set, each tree's submodules are checked out too.
+//
+// A failure to create one tree does not prevent the others from being created; the outcome of each is returned.
+// Once ctx is cancelled, no further trees are created, and the remaining results record ctx's error
+func (g *Grove) AddRemoteBranchTrees(ctx context.Context, remote string, patterns []string, parallel int, recurseSubmodules bool) ([]TreeResult, error) {
+	root, err := g.Root()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine grove root: %w", err)
+	}
+
+	branches, err := g.repo.RemoteBranches(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	trees, err := g.Trees()
+	if err != nil {
+		return nil, err
+	}
+	checkedOut := map[string]bool{}
+	for _, tree := range trees {
+		checkedOut[tree.Branch] = true
+	}
+
+	pending := []local.RemoteBranch{}
+	for _, branch := range branches {
+		if checkedOut[branch.Name] {
+			continue
+		}
+		matched, err := MatchBranch(branch.Name, patterns)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			pending = append(pending, branch)
+		}
+	}
+
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	// Branches which already exist locally are checked out as they are, without tracking the remote's branch
+	track := make([]bool, len(pending))
+	for i, branch := range pending {
+		_, err = g.repo.ResolveReference(plumbing.NewBranchReferenceName(branch.Name))
+		track[i] = err != nil
+	}
+
+	// Each worktree has its own index and administrative directory, and objects are only ever read from the
+	// shared store while checking out, so trees can safely be created concurrently
+	results := make([]TreeResult, len(pending))
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for range parallel {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				branch := pending[i]
+				results[i] = TreeResult{
+					Path:   filepath.Join(root, branch.Name),
+					Branch: branch.Name,
+				}
+				relative, err := g.Layout.TreePath(branch.Name)
+				if err != nil {
+					results[i].Err = err
+					continue
+				}
+				path := filepath.Join(root, relative)
+				results[i].Path = path
+				if ctx.Err() != nil {
+					results[i].Err = ctx.Err()
+					continue
+				}
+				results[i].Err = g.AddTree(path, AddTreeOptions{
+					Branch:            branch.Name,
+					Commit:            branch.Head.String(),
+					NoTrack:           true,
+					RecurseSubmodules: recurseSubmodules,
+				})
+			}
+		}()
+	}
+	for i := range pending {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// Upstreams are recorded in the repository's config, which every tree reads while it's checked out, so they're
+	// only set once all trees are created
+	for i, branch := range pending {
+		if results[i].Err != nil || !track[i] {
+			continue
+		}
+		err = g.repo.SetUpstream(branch.Name, branch.Remote, plumbing.NewBranchReferenceName(branch.Name))
+		if err != nil {
+			results[i].Err = fmt.Errorf("tree %q was created, but %w", results[i].Path, err)
+		}
+	}
+
+	return results, nil
+}
+
+// MatchBranch reports whether the given branch matches any of the glob patterns, as with path.Match, in which '*'
+// matches any sequence of characters except '/'. Every branch matches when no patterns are given
+func MatchBranch(branch string, patterns []string) (bool, error) {
+	if len(patterns) == 0 {
+		return true, nil
+	}
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, branch)
+		if err != nil {
+			return false, fmt.Errorf("invalid branch pattern %q: %w", pattern, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// LockTree locks the tree at the given path, recording the given reason, which may be empty. The grove's
+// main worktree cannot be locked
+func (g *Grove) LockTree(path, reason string) error {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return err
+	}
+	return g.repo.LockWorktree(tree.Path, reason)
+}
+
+// UnlockTree unlocks the tree at the given path
+func (g *Grove) UnlockTree(path string) error {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return err
+	}
+	return g.repo.UnlockWorktree(tree.Path)
+}
+
+// resolvePath constructs the canonical absolute path of the given path relative to the grove's root, unless already
+// absolute. Home directories and environment variables in the path are expanded first
+func (g *Grove) resolvePath(path string) (string, error) {
+	path, err := ExpandPath(path)
+	if err != nil {
+		return "", err
+	}
+
+	if filepath.IsAbs(path) {
+		return canonicalPath(path), nil
+	}
+
+	// Absolute path not provided: construct absolute path relative to grove root
+	root, err := g.Root()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine grove root: %w", err)
+	}
+	return canonicalPath(filepath.Join(root, path)), nil
+}
@@ -0,0 +1,362 @@
+package grove
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v6"
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/object"
+	"github.com/go-git/go-git/v6/storage/memory"
+)
+
+// testEpoch is when the commits made by newTestGrove are authored, unless given another time
+var testEpoch = time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestGrove creates a grove in a temporary directory, whose main worktree 'main' has the branch 'main' checked out
+// with a single commit. The grove's root is returned with any symlinks resolved, as the grove reports paths
+func newTestGrove(t *testing.T) (*Grove, string) {
+	t.Helper()
+
+	root := canonicalPath(t.TempDir())
+	mainTree := filepath.Join(root, "main")
+	_, err := git.PlainInit(mainTree, false, git.WithDefaultBranch(plumbing.NewBranchReferenceName("main")))
+	if err != nil {
+		t.Fatalf("failed to initialize repository: %v", err)
+	}
+	commitFile(t, mainTree, "README.md", "grove\n", testEpoch)
+
+	g, err := OpenAt(mainTree)
+	if err != nil {
+		t.Fatalf("failed to open grove: %v", err)
+	}
+	return g, root
+}
+
+// addTestTree adds a tree for a new branch at the given path relative to the grove's root, failing the test otherwise
+func addTestTree(t *testing.T, g *Grove, path string) string {
+	t.Helper()
+
+	err := g.AddTree(path, AddTreeOptions{NoHooks: true})
+	if err != nil {
+		t.Fatalf("failed to add tree %q: %v", path, err)
+	}
+	tree, err := g.Tree(path)
+	if err != nil {
+		t.Fatalf("failed to find tree %q: %v", path, err)
+	}
+	return tree.Path
+}
+
+// commitFile writes the file of the given name in the worktree at path, and commits it at the given time
+func commitFile(t *testing.T, path, name, content string, when time.Time) plumbing.Hash {
+	t.Helper()
+
+	writeFile(t, filepath.Join(path, name), content)
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		t.Fatalf("failed to open worktree %q: %v", path, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to open worktree %q: %v", path, err)
+	}
+	_, err = wt.Add(name)
+	if err != nil {
+		t.Fatalf("failed to stage %q: %v", name, err)
+	}
+	signature := &object.Signature{Name: "grove", Email: "grove@example.com", When: when}
+	hash, err := wt.Commit("add "+name, &git.CommitOptions{Author: signature, Committer: signature})
+	if err != nil {
+		t.Fatalf("failed to commit %q: %v", name, err)
+	}
+	return hash
+}
+
+// writeFile writes content to the file at path, creating its parent directories
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		t.Fatalf("failed to create directory %q: %v", filepath.Dir(path), err)
+	}
+	err = os.WriteFile(path, []byte(content), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write %q: %v", path, err)
+	}
+}
+
+func TestCurrentOrNamedTree(t *testing.T) {
+	g, root := newTestGrove(t)
+	feature := addTestTree(t, g, "feature")
+	t.Chdir(filepath.Join(root, "main"))
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "current", path: "", want: filepath.Join(root, "main")},
+		{name: "named", path: feature, want: feature},
+		{name: "missing", path: filepath.Join(root, "missing"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := g.CurrentOrNamedTree(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("CurrentOrNamedTree(%q) = %q, want an error", tt.path, tree.Path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CurrentOrNamedTree(%q) returned error: %v", tt.path, err)
+			}
+			if tree.Path != tt.want {
+				t.Errorf("CurrentOrNamedTree(%q) = %q, want %q", tt.path, tree.Path, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddRemoteBranchTreesTracksEveryBranch(t *testing.T) {
+	g, root := newTestGrove(t)
+	err := g.repo.AddRemote("origin", "https://example.com/grove.git")
+	if err != nil {
+		t.Fatalf("failed to add remote: %v", err)
+	}
+	head, err := g.repo.ResolveCommit("HEAD")
+	if err != nil {
+		t.Fatalf("failed to resolve HEAD: %v", err)
+	}
+	repo, err := git.PlainOpen(filepath.Join(root, "main"))
+	if err != nil {
+		t.Fatalf("failed to open repository: %v", err)
+	}
+	// Branches sharing a final path element are also given administrative directories of the same name
+	branches := []string{}
+	for i := range 16 {
+		branches = append(branches, fmt.Sprintf("feature-%d", i), fmt.Sprintf("fix/%d/tree", i))
+	}
+	for _, branch := range branches {
+		err = repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", branch), head))
+		if err != nil {
+			t.Fatalf("failed to create remote-tracking branch %q: %v", branch, err)
+		}
+	}
+
+	results, err := g.AddRemoteBranchTrees(context.Background(), "origin", []string{"feature-*", "fix/*/tree"}, 8, false)
+	if err != nil {
+		t.Fatalf("AddRemoteBranchTrees() returned error: %v", err)
+	}
+	if len(results) != len(branches) {
+		t.Fatalf("AddRemoteBranchTrees() created %d trees, want %d", len(results), len(branches))
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("failed to create tree for branch %q: %v", result.Branch, result.Err)
+		}
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatalf("failed to read repository configuration: %v", err)
+	}
+	for _, branch := range branches {
+		branchConfig, found := cfg.Branches[branch]
+		if !found {
+			t.Errorf("branch %q has no tracking configuration", branch)
+			continue
+		}
+		if branchConfig.Remote != "origin" || branchConfig.Merge != plumbing.NewBranchReferenceName(branch) {
+			t.Errorf("branch %q tracks %q of remote %q, want %q of remote %q", branch, branchConfig.Merge, branchConfig.Remote, plumbing.NewBranchReferenceName(branch), "origin")
+		}
+	}
+
+	trees, err := g.Trees()
+	if err != nil {
+		t.Fatalf("failed to list trees: %v", err)
+	}
+	// Every tree, and the main worktree
+	if len(trees) != len(branches)+1 {
+		t.Errorf("grove has %d trees, want %d", len(trees), len(branches)+1)
+	}
+}
+
+func TestAddTreeRollsBackOnFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		// existing is created, relative to the grove's root, before adding the tree, holding a file if keep is set
+		existing string
+		keep     bool
+		path     string
+		// want lists the paths, relative to the grove's root, expected to exist once adding the tree failed
+		want []string
+		// wantGone lists the paths expected not to exist
+		wantGone []string
+	}{
+		{name: "new directories", path: "new/nested/tree", wantGone: []string{"new"}},
+		{
+			name:     "existing parent",
+			existing: "existing",
+			keep:     true,
+			path:     "existing/nested/tree",
+			want:     []string{"existing", "existing/kept.txt"},
+			wantGone: []string{"existing/nested"},
+		},
+		{name: "existing empty directory", existing: "empty", path: "empty", want: []string{"empty"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, root := newTestGrove(t)
+			if tt.existing != "" {
+				mkdirAll(t, filepath.Join(root, tt.existing))
+			}
+			if tt.keep {
+				writeFile(t, filepath.Join(root, tt.existing, "kept.txt"), "kept\n")
+			}
+
+			// The invalid branch name is only rejected when checking out the new worktree, once its directories and
+			// metadata have been created
+			err := g.AddTree(tt.path, AddTreeOptions{Branch: "invalid..branch", NoHooks: true})
+			if err == nil {
+				t.Fatal("AddTree() succeeded with an invalid branch name")
+			}
+
+			for _, path := range tt.want {
+				_, err := os.Stat(filepath.Join(root, path))
+				if err != nil {
+					t.Errorf("%q was removed: %v", path, err)
+				}
+			}
+			for _, path := range tt.wantGone {
+				_, err := os.Stat(filepath.Join(root, path))
+				if !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("%q was left behind", path)
+				}
+			}
+			// The worktree's administrative directory is removed along with it
+			admin, err := os.ReadDir(filepath.Join(root, "main", ".git", "worktrees"))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				t.Fatalf("failed to read worktree metadata: %v", err)
+			}
+			if len(admin) > 0 {
+				t.Errorf("worktree metadata %v was left behind", admin)
+			}
+
+			// Nothing left behind blocks a retry
+			addTestTree(t, g, tt.path)
+		})
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	g, root := newTestGrove(t)
+	t.Setenv("TREES", "trees")
+
+	tests := []struct {
+		name string
+		path string
+		// unixAbs and windowsAbs determine whether the path is absolute on Unix and Windows respectively, rather than
+		// relative to the grove's root
+		unixAbs    bool
+		windowsAbs bool
+	}{
+		{name: "relative", path: "feature/x"},
+		{name: "nested relative", path: "./feature/../release/1.0"},
+		{name: "environment variable", path: "$TREES/x"},
+		{name: "unix absolute", path: "/srv/trees/x", unixAbs: true},
+		{name: "drive letter", path: `C:\trees\x`, windowsAbs: true},
+		{name: "drive letter with forward slashes", path: "C:/trees/x", windowsAbs: true},
+		{name: "UNC path", path: `\\server\share\x`, windowsAbs: true},
+		{name: "backslash separators", path: `feature\x`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			abs := tt.unixAbs
+			if runtime.GOOS == "windows" {
+				abs = tt.windowsAbs
+			}
+			expanded := os.ExpandEnv(tt.path)
+			want := filepath.Join(root, expanded)
+			if abs {
+				want = canonicalPath(expanded)
+			}
+
+			got, err := g.resolvePath(tt.path)
+			if err != nil {
+				t.Fatalf("resolvePath(%q) returned error: %v", tt.path, err)
+			}
+			if got != want {
+				t.Errorf("resolvePath(%q) = %q, want %q", tt.path, got, want)
+			}
+		})
+	}
+}
+
+func TestFromRepository(t *testing.T) {
+	_, root := newTestGrove(t)
+	mainTree := filepath.Join(root, "main")
+	opened, err := git.PlainOpen(mainTree)
+	if err != nil {
+		t.Fatalf("failed to open repository: %v", err)
+	}
+	inMemory, err := git.Init(memory.NewStorage())
+	if err != nil {
+		t.Fatalf("failed to initialize repository: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		repo *git.Repository
+		root string
+		// dir is the working directory FromRepository is called from, if set
+		dir     string
+		wantErr bool
+	}{
+		{name: "root", repo: opened, root: root},
+		{name: "relative root", repo: opened, root: ".", dir: root},
+		{name: "root through parent", repo: opened, root: filepath.Join(mainTree, "..")},
+		{name: "another directory", repo: opened, root: t.TempDir(), wantErr: true},
+		{name: "main worktree as root", repo: opened, root: mainTree, wantErr: true},
+		{name: "no repository", root: root, wantErr: true},
+		{name: "repository in memory", repo: inMemory, root: root, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.dir != "" {
+				t.Chdir(tt.dir)
+			}
+
+			g, err := FromRepository(tt.repo, tt.root)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromRepository(%q) returned error %v, want error: %v", tt.root, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got, err := g.Root()
+			if err != nil {
+				t.Fatalf("Root() returned error: %v", err)
+			}
+			if got != root {
+				t.Errorf("Root() = %q, want %q", got, root)
+			}
+			trees, err := g.Trees()
+			if err != nil {
+				t.Fatalf("Trees() returned error: %v", err)
+			}
+			if len(trees) != 1 || trees[0].Path != mainTree || trees[0].Branch != "main" {
+				t.Errorf("Trees() = %+v, want only the main worktree", trees)
+			}
+		})
+	}
+}
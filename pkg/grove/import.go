@@ -0,0 +1,105 @@
+package grove
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tnierman/git-grove/pkg/git/local"
+)
+
+// ImportTree reconciles a worktree created outside of grove - such as with 'git worktree add' - with the grove. The
+// worktree at the given path, relative to the current directory unless already absolute, must belong to the grove's
+// repository.
+//
+// If move is set and the worktree lies outside the grove's root, it is moved to destination - relative to the grove's
+// root, unless already absolute - or, if destination is empty, to its branch's path according to the grove's Layout.
+// The resulting tree is returned
+func (g *Grove) ImportTree(path, destination string, move bool) (Tree, error) {
+	path, err := ExpandPath(path)
+	if err != nil {
+		return Tree{}, err
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return Tree{}, fmt.Errorf("failed to determine absolute path of %q: %w", path, err)
+	}
+
+	err = g.sameRepository(path)
+	if err != nil {
+		return Tree{}, err
+	}
+
+	tree, err := g.Tree(path)
+	if err != nil {
+		return Tree{}, fmt.Errorf("%q is not a worktree of the grove's repository: %w", path, err)
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return Tree{}, fmt.Errorf("failed to determine grove root: %w", err)
+	}
+	if !move || within(root, tree.Path) {
+		return tree, nil
+	}
+
+	if destination == "" {
+		name := tree.Branch
+		if name == "" {
+			// Detached trees have no branch to derive a path from, so keep the directory's name
+			name = filepath.Base(tree.Path)
+		}
+		destination, err = g.BranchTreePath(name)
+	} else {
+		destination, err = g.resolvePath(destination)
+	}
+	if err != nil {
+		return Tree{}, err
+	}
+
+	_, err = createDirs(filepath.Dir(destination))
+	if err != nil {
+		return Tree{}, err
+	}
+	err = g.repo.MoveWorktree(tree.Path, destination)
+	if err != nil {
+		return Tree{}, fmt.Errorf("failed to move tree %q to %q: %w", tree.Path, destination, err)
+	}
+	tree.Path = destination
+	return tree, nil
+}
+
+// sameRepository returns an error unless the worktree at the given path shares the grove's repository
+func (g *Grove) sameRepository(path string) error {
+	repo, err := local.NewRepository(path)
+	if err != nil {
+		return fmt.Errorf("%q is not a git worktree: %w", path, err)
+	}
+	theirs, err := repo.CommonDir()
+	if err != nil {
+		return fmt.Errorf("failed to determine git directory of %q: %w", path, err)
+	}
+	ours, err := g.repo.CommonDir()
+	if err != nil {
+		return fmt.Errorf("failed to determine git directory of grove: %w", err)
+	}
+
+	if !samePath(theirs, ours) {
+		return fmt.Errorf("%q is a worktree of a different repository (%q)", path, theirs)
+	}
+	return nil
+}
+
+// samePath determines whether the two paths refer to the same location, after resolving any symlinks
+func samePath(a, b string) bool {
+	return canonicalPath(a) == canonicalPath(b)
+}
+
+// within determines whether path lies within the directory dir
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
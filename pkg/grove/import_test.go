@@ -0,0 +1,30 @@
+package grove
+
+import (
+	"testing"
+)
+
+func TestWithin(t *testing.T) {
+	tests := []struct {
+		name string
+		dir  string
+		path string
+		want bool
+	}{
+		{name: "same", dir: "/grove", path: "/grove", want: true},
+		{name: "child", dir: "/grove", path: "/grove/main", want: true},
+		{name: "nested", dir: "/grove", path: "/grove/feature/foo", want: true},
+		{name: "parent", dir: "/grove/main", path: "/grove", want: false},
+		{name: "sibling", dir: "/grove/main", path: "/grove/feature", want: false},
+		{name: "shared prefix", dir: "/grove", path: "/grove2/main", want: false},
+		{name: "dotted name", dir: "/grove", path: "/grove/..main", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := within(tt.dir, tt.path)
+			if got != tt.want {
+				t.Errorf("within(%q, %q) = %v, want %v", tt.dir, tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package grove
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/tnierman/git-grove/pkg/git/local"
+)
+
+// TreeInfo holds detailed information about a single tree
+type TreeInfo struct {
+	Tree
+	// Subject is the first line of the message of the tree's HEAD commit
+	Subject string `json:"subject,omitempty"`
+	// LastCommit is the time the tree's HEAD commit was made. It is zero when the tree's branch is unborn
+	LastCommit time.Time `json:"lastCommit,omitzero"`
+	// Upstream is the short name of the remote-tracking branch the tree's branch tracks. It is empty when the
+	// branch has no upstream, or the tree's HEAD is detached
+	Upstream string `json:"upstream,omitempty"`
+	// Ahead is the number of commits in the tree's branch which are not in its upstream
+	Ahead int `json:"ahead"`
+	// Behind is the number of commits in the tree's upstream which are not in its branch
+	Behind int `json:"behind"`
+	// Status summarizes the uncommitted changes in the tree
+	Status local.WorktreeStatus `json:"status"`
+}
+
+// Info gathers detailed information about the tree at the given path
+func (g *Grove) Info(path string) (TreeInfo, error) {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return TreeInfo{}, err
+	}
+	info := TreeInfo{
+		Tree: tree,
+	}
+
+	info.Status, err = g.repo.Status(tree.Path)
+	if err != nil {
+		return TreeInfo{}, err
+	}
+
+	// The remaining details describe the tree's commits, so there's nothing more to gather for an unborn branch
+	if tree.Hash == "" {
+		return info, nil
+	}
+	head := plumbing.NewHash(tree.Hash)
+	info.Subject, err = g.repo.CommitSubject(head)
+	if err != nil {
+		return TreeInfo{}, err
+	}
+	info.LastCommit, err = g.repo.CommitTime(head)
+	if err != nil {
+		return TreeInfo{}, err
+	}
+
+	if tree.Branch == "" {
+		return info, nil
+	}
+	upstream, err := g.repo.Upstream(tree.Branch)
+	if err != nil {
+		if errors.Is(err, local.ErrNoUpstream) {
+			return info, nil
+		}
+		return TreeInfo{}, err
+	}
+	info.Upstream = upstream.TrackingRef.Short()
+
+	upstreamHead, err := g.repo.ResolveReference(upstream.TrackingRef)
+	if err != nil {
+		// The upstream may not have been fetched yet
+		return info, nil
+	}
+	info.Ahead, info.Behind, err = g.repo.AheadBehind(head, upstreamHead)
+	if err != nil {
+		return TreeInfo{}, fmt.Errorf("failed to compare tree %q with its upstream: %w", tree.Path, err)
+	}
+	return info, nil
+}
+
+// Dirty reports whether the tree has any uncommitted changes, including untracked files
+func (i TreeInfo) Dirty() bool {
+	return !i.Status.Clean() || i.Status.Untracked > 0
+}
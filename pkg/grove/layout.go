@@ -0,0 +1,67 @@
+package grove
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	// LayoutPath places each branch's tree at the path matching the branch's name, so that a branch such as
+	// 'feature/foo' is nested within a 'feature' directory
+	LayoutPath = "path"
+	// LayoutFlat places each branch's tree directly beneath the grove's root, replacing each '/' in the branch's name
+	// with '-'
+	LayoutFlat = "flat"
+)
+
+// layoutFuncs are the functions available to layout templates
+var layoutFuncs = template.FuncMap{
+	"replace": strings.ReplaceAll,
+	"lower":   strings.ToLower,
+}
+
+// Layout determines where the tree for a branch is placed, relative to the grove's root. The zero Layout is LayoutPath
+type Layout struct {
+	tmpl *template.Template
+}
+
+// ParseLayout parses the given layout: either one of LayoutPath or LayoutFlat, or a Go text/template executed against
+// the branch to produce the tree's path, such as 'wt/{{replace .Branch "/" "-"}}'. The template functions 'replace' and
+// 'lower' are available, mirroring strings.ReplaceAll and strings.ToLower
+func ParseLayout(layout string) (Layout, error) {
+	switch layout {
+	case "", LayoutPath:
+		return Layout{}, nil
+	case LayoutFlat:
+		layout = `{{replace .Branch "/" "-"}}`
+	}
+
+	tmpl, err := template.New("layout").Funcs(layoutFuncs).Option("missingkey=error").Parse(layout)
+	if err != nil {
+		return Layout{}, fmt.Errorf("failed to parse layout %q: %w", layout, err)
+	}
+	return Layout{tmpl: tmpl}, nil
+}
+
+// TreePath returns the path of the tree for the given branch, relative to the grove's root
+func (l Layout) TreePath(branch string) (string, error) {
+	if l.tmpl == nil {
+		return branch, nil
+	}
+
+	path := &bytes.Buffer{}
+	err := l.tmpl.Execute(path, struct{ Branch string }{Branch: branch})
+	if err != nil {
+		return "", fmt.Errorf("failed to render tree path for branch %q: %w", branch, err)
+	}
+
+	// The tree must be placed within the grove
+	clean := filepath.Clean(path.String())
+	if path.Len() == 0 || filepath.IsAbs(clean) || clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("layout produced invalid tree path %q for branch %q: must be a path within the grove", path.String(), branch)
+	}
+	return clean, nil
+}
@@ -0,0 +1,58 @@
+package grove
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLayoutTreePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  string
+		branch  string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", layout: "", branch: "feature/foo", want: filepath.Join("feature", "foo")},
+		{name: "path", layout: LayoutPath, branch: "feature/foo", want: filepath.Join("feature", "foo")},
+		{name: "flat", layout: LayoutFlat, branch: "feature/foo", want: "feature-foo"},
+		{name: "flat without slashes", layout: LayoutFlat, branch: "main", want: "main"},
+		{name: "template", layout: `wt/{{replace .Branch "/" "-"}}`, branch: "feature/foo", want: filepath.Join("wt", "feature-foo")},
+		{name: "lower", layout: `{{lower .Branch}}`, branch: "Feature/FOO", want: filepath.Join("feature", "foo")},
+		{name: "cleaned", layout: `./trees//{{.Branch}}/`, branch: "main", want: filepath.Join("trees", "main")},
+		{name: "empty", layout: `{{if false}}x{{end}}`, branch: "main", wantErr: true},
+		{name: "root", layout: `.`, branch: "main", wantErr: true},
+		{name: "parent", layout: `../{{.Branch}}`, branch: "main", wantErr: true},
+		{name: "escapes", layout: `{{.Branch}}`, branch: "../../etc", wantErr: true},
+		{name: "absolute", layout: `/tmp/{{.Branch}}`, branch: "main", wantErr: true},
+		{name: "missing key", layout: `{{.Tree}}`, branch: "main", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout, err := ParseLayout(tt.layout)
+			if err != nil {
+				t.Fatalf("ParseLayout(%q) returned error: %v", tt.layout, err)
+			}
+			got, err := layout.TreePath(tt.branch)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("TreePath(%q) = %q, want an error", tt.branch, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TreePath(%q) returned error: %v", tt.branch, err)
+			}
+			if got != tt.want {
+				t.Errorf("TreePath(%q) = %q, want %q", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLayoutInvalid(t *testing.T) {
+	_, err := ParseLayout(`{{.Branch`)
+	if err == nil {
+		t.Error("ParseLayout() accepted an unterminated template")
+	}
+}
@@ -0,0 +1,13 @@
+package grove
+
+import "github.com/tnierman/git-grove/pkg/git/local"
+
+// Log lists the commits in the history of the HEAD of the tree at the given path, most recent first. If limit is
+// positive, at most limit commits are listed
+func (g *Grove) Log(path string, limit int) ([]local.LogEntry, error) {
+	head, err := g.treeHead(path)
+	if err != nil {
+		return nil, err
+	}
+	return g.repo.Log(head, limit)
+}
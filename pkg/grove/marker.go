@@ -0,0 +1,83 @@
+package grove
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tnierman/git-grove/pkg/config"
+	"github.com/tnierman/git-grove/pkg/git/local"
+)
+
+// RootMarker is the name of the file within config.GroveDir which marks the root of a grove. Groves created before
+// the marker was introduced have none, so their root is derived from the layout of their repository instead
+const RootMarker = "root"
+
+// rootMarkerContent explains the marker to anyone who comes across it
+const rootMarkerContent = "This file marks the root of a grove. Removing it makes grove derive the root from the layout of the repository instead.\n"
+
+// MarkRoot records the directory at root as the root of a grove, by creating its RootMarker
+func MarkRoot(root string) error {
+	dir := filepath.Join(root, config.GroveDir)
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", dir, err)
+	}
+	path := filepath.Join(dir, RootMarker)
+	err = os.WriteFile(path, []byte(rootMarkerContent), 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to mark grove root %q: %w", root, err)
+	}
+	return nil
+}
+
+// findMarkedRoot walks up from dir to the nearest directory holding a RootMarker, reporting whether one was found.
+// The walk stops at the first directory belonging to a repository - so that a grove without a marker, nested within
+// the directory of one with a marker, is not mistaken for part of it - or at the filesystem's root
+func findMarkedRoot(dir string) (string, bool, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to determine absolute path of %q: %w", dir, err)
+	}
+
+	for ; ; dir = filepath.Dir(dir) {
+		info, err := os.Stat(filepath.Join(dir, config.GroveDir, RootMarker))
+		if err == nil && info.Mode().IsRegular() {
+			return dir, true, nil
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", false, fmt.Errorf("failed to check for grove root marker in %q: %w", dir, err)
+		}
+
+		_, err = os.Stat(local.GitPath(dir))
+		if err == nil || filepath.Dir(dir) == dir {
+			return "", false, nil
+		}
+	}
+}
+
+// openMarkedRoot opens the grove rooted at root, which holds a RootMarker: bare groves are opened from the root itself,
+// which links to their repository, and other groves from their main worktree, directly beneath the root
+func openMarkedRoot(root string) (*Grove, error) {
+	_, err := os.Stat(local.GitPath(root))
+	if err == nil {
+		return OpenAt(root)
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read grove root %q: %w", root, err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		mainWorktree := filepath.Join(root, entry.Name())
+		info, err := os.Stat(local.GitPath(mainWorktree))
+		if err == nil && info.IsDir() {
+			return OpenAt(mainWorktree)
+		}
+	}
+	return nil, fmt.Errorf("%w: no main worktree found beneath grove root %q", ErrNotGrove, root)
+}
@@ -0,0 +1,53 @@
+package grove
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandPath expands a leading '~' or '~user' in the given path to the matching home directory, then replaces any
+// $VAR or ${VAR} references with the values of the corresponding environment variables, as a shell would
+func ExpandPath(path string) (string, error) {
+	if strings.HasPrefix(path, "~") {
+		name, rest, _ := strings.Cut(path[1:], string(filepath.Separator))
+
+		var home string
+		if name == "" {
+			var err error
+			home, err = os.UserHomeDir()
+			if err != nil {
+				return "", fmt.Errorf("failed to expand %q: %w", path, err)
+			}
+		} else {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", fmt.Errorf("failed to expand %q: %w", path, err)
+			}
+			home = u.HomeDir
+		}
+		path = filepath.Join(home, os.ExpandEnv(rest))
+		return path, nil
+	}
+	return os.ExpandEnv(path), nil
+}
+
+// canonicalPath gives the canonical form of the given absolute path, by which the grove's root and trees are compared:
+// the path with any symlinks resolved, so that a grove reached through a symlink - such as '~/dev' linking to
+// '/mnt/dev' - is recognized as the same grove. Where the path does not exist, symlinks are resolved in its nearest
+// existing parent directory
+func canonicalPath(path string) string {
+	path = filepath.Clean(path)
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return resolved
+	}
+	parent := filepath.Dir(path)
+	if !errors.Is(err, os.ErrNotExist) || parent == path {
+		return path
+	}
+	return filepath.Join(canonicalPath(parent), filepath.Base(path))
+}
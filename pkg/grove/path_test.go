@@ -0,0 +1,84 @@
+package grove
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCanonicalPath(t *testing.T) {
+	resolved := canonicalPath(t.TempDir())
+	mkdirAll(t, filepath.Join(resolved, "dev", "grove"))
+	links := t.TempDir()
+	symlink(t, filepath.Join(resolved, "dev"), filepath.Join(links, "dev"))
+	symlink(t, filepath.Join(links, "dev"), filepath.Join(links, "chained"))
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "resolved", path: filepath.Join(resolved, "dev", "grove"), want: filepath.Join(resolved, "dev", "grove")},
+		{name: "uncleaned", path: filepath.Join(resolved, "dev") + "/./grove/", want: filepath.Join(resolved, "dev", "grove")},
+		{name: "symlink", path: filepath.Join(links, "dev", "grove"), want: filepath.Join(resolved, "dev", "grove")},
+		{name: "chained symlinks", path: filepath.Join(links, "chained", "grove"), want: filepath.Join(resolved, "dev", "grove")},
+		{name: "missing beneath symlink", path: filepath.Join(links, "dev", "grove", "a", "b"), want: filepath.Join(resolved, "dev", "grove", "a", "b")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := canonicalPath(tt.path)
+			if got != tt.want {
+				t.Errorf("canonicalPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GROVE_TEST_DIR", "dev")
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "unchanged", path: "/srv/grove", want: "/srv/grove"},
+		{name: "home", path: "~", want: home},
+		{name: "within home", path: "~/dev/grove", want: filepath.Join(home, "dev", "grove")},
+		{name: "variable", path: "/srv/$GROVE_TEST_DIR/grove", want: "/srv/dev/grove"},
+		{name: "braced variable within home", path: "~/${GROVE_TEST_DIR}", want: filepath.Join(home, "dev")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandPath(tt.path)
+			if err != nil {
+				t.Fatalf("ExpandPath(%q) returned error: %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// mkdirAll creates the directory at path, along with its parents
+func mkdirAll(t *testing.T, path string) {
+	t.Helper()
+
+	err := os.MkdirAll(path, 0o755)
+	if err != nil {
+		t.Fatalf("failed to create directory %q: %v", path, err)
+	}
+}
+
+// symlink creates a symlink at path pointing to target
+func symlink(t *testing.T, target, path string) {
+	t.Helper()
+
+	err := os.Symlink(target, path)
+	if err != nil {
+		t.Fatalf("failed to create symlink %q: %v", path, err)
+	}
+}
@@ -0,0 +1,162 @@
+package grove
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// PruneOptions determines which trees are considered stale by StaleTrees. A tree must satisfy every criterion given
+type PruneOptions struct {
+	// Merged selects trees whose branch has been fully merged into Base
+	Merged bool
+	// Base is the revision branches must be merged into. Defaults to the grove's default branch
+	Base string
+	// OlderThan selects trees whose HEAD was committed longer ago than the given duration. Ignored when zero
+	OlderThan time.Duration
+	// Selector limits the trees considered to those whose branch it selects. The zero Selector selects every tree
+	Selector Selector
+}
+
+// StaleTree describes a tree found by StaleTrees
+type StaleTree struct {
+	Tree
+	// LastCommit is when the tree's HEAD was committed
+	LastCommit time.Time
+	// Skipped explains why the tree cannot be removed despite being stale, such as it being locked. It is empty
+	// when the tree can be removed
+	Skipped string
+}
+
+// StaleTrees finds the trees matching every criterion of opts. The main worktree, trees with a detached HEAD, and the
+// tree of the base branch itself are never considered stale.
+//
+// Stale trees which are locked, have uncommitted changes or untracked files, or contain the current working directory
+// are still returned, with the reason they must be kept in Skipped
+func (g *Grove) StaleTrees(opts PruneOptions, now time.Time) ([]StaleTree, error) {
+	if !opts.Merged && opts.OlderThan == 0 {
+		return nil, fmt.Errorf("at least one criterion for stale trees must be provided")
+	}
+
+	var err error
+	base := opts.Base
+	if base == "" {
+		base, err = g.DefaultBranch()
+		if err != nil {
+			return nil, err
+		}
+	}
+	var baseHash plumbing.Hash
+	if opts.Merged {
+		baseHash, err = g.repo.ResolveCommit(base)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	worktrees, err := g.repo.Worktrees()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine current working directory: %w", err)
+	}
+	cwd = canonicalPath(cwd)
+
+	stale := []StaleTree{}
+	for _, worktree := range worktrees {
+		if worktree.Main || worktree.Branch == "" || worktree.Branch == base || worktree.Head.IsZero() {
+			continue
+		}
+		if !opts.Selector.Matches(Tree{Branch: worktree.Branch}) {
+			continue
+		}
+
+		if opts.Merged {
+			merged, err := g.repo.IsAncestor(worktree.Head, baseHash)
+			if err != nil {
+				return nil, err
+			}
+			if !merged {
+				continue
+			}
+		}
+
+		lastCommit, err := g.repo.CommitTime(worktree.Head)
+		if err != nil {
+			return nil, err
+		}
+		if opts.OlderThan != 0 && now.Sub(lastCommit) < opts.OlderThan {
+			continue
+		}
+
+		tree := StaleTree{
+			Tree: Tree{
+				Branch:     worktree.Branch,
+				Path:       worktree.Path,
+				Hash:       worktree.Head.String(),
+				Locked:     worktree.Locked,
+				LockReason: worktree.LockReason,
+			},
+			LastCommit: lastCommit,
+		}
+		if worktree.Locked {
+			tree.Skipped = "locked"
+		} else if within(canonicalPath(worktree.Path), cwd) {
+			tree.Skipped = "contains the current working directory"
+		} else {
+			// Removing the tree deletes its untracked files along with it, so they count as uncommitted changes
+			info, err := g.Info(worktree.Path)
+			if err != nil {
+				return nil, err
+			}
+			if info.Dirty() {
+				tree.Skipped = "has uncommitted changes or untracked files"
+			}
+		}
+		stale = append(stale, tree)
+	}
+	return stale, nil
+}
+
+// RemoveTree deletes the tree at the given path relative to the grove's root, unless already absolute. The branch it
+// had checked out is kept.
+//
+// An error is returned if the tree is the main worktree, is locked, or has uncommitted changes or untracked files - as
+// with 'git worktree remove' - which would be deleted along with it. Ignored files are deleted
+func (g *Grove) RemoveTree(path string) error {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := g.Info(tree.Path)
+	if err != nil {
+		return err
+	}
+	if info.Dirty() {
+		return fmt.Errorf("tree %q has uncommitted changes or untracked files", tree.Path)
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return fmt.Errorf("failed to determine grove root: %w", err)
+	}
+	err = g.repo.RemoveWorktree(tree.Path)
+	if err != nil {
+		return err
+	}
+
+	// Remove the directories left empty by the tree's removal - such as 'feature/' - up to the grove's root
+	for dir := filepath.Dir(tree.Path); within(root, dir) && !samePath(root, dir); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package grove
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStaleTrees(t *testing.T) {
+	g, root := newTestGrove(t)
+
+	// Branches without commits of their own are merged into 'main'
+	addTestTree(t, g, "merged")
+	locked := addTestTree(t, g, "locked")
+	err := g.LockTree(locked, "in use")
+	if err != nil {
+		t.Fatalf("failed to lock tree: %v", err)
+	}
+	modified := addTestTree(t, g, "modified")
+	writeFile(t, filepath.Join(modified, "README.md"), "changed\n")
+	untracked := addTestTree(t, g, "untracked")
+	writeFile(t, filepath.Join(untracked, "newfile.txt"), "not committed\n")
+	ignored := addTestTree(t, g, "ignored")
+	commitFile(t, ignored, ".gitignore", "build/\n", testEpoch)
+	writeFile(t, filepath.Join(ignored, "build", "output"), "ignored\n")
+	current := addTestTree(t, g, "current")
+	recent := addTestTree(t, g, "recent")
+	commitFile(t, recent, "recent.txt", "recent\n", testEpoch.Add(30*24*time.Hour))
+
+	t.Chdir(current)
+
+	tests := []struct {
+		name string
+		opts PruneOptions
+		// want maps the name of each tree expected to be stale to the reason it is kept, if any
+		want map[string]string
+	}{
+		{
+			name: "merged",
+			opts: PruneOptions{Merged: true},
+			want: map[string]string{
+				"merged":    "",
+				"locked":    "locked",
+				"modified":  "has uncommitted changes or untracked files",
+				"untracked": "has uncommitted changes or untracked files",
+				"current":   "contains the current working directory",
+			},
+		},
+		{
+			name: "older than",
+			opts: PruneOptions{OlderThan: 7 * 24 * time.Hour},
+			want: map[string]string{
+				"merged":    "",
+				"locked":    "locked",
+				"modified":  "has uncommitted changes or untracked files",
+				"untracked": "has uncommitted changes or untracked files",
+				"ignored":   "",
+				"current":   "contains the current working directory",
+			},
+		},
+		{
+			name: "merged and older than",
+			opts: PruneOptions{Merged: true, OlderThan: 7 * 24 * time.Hour},
+			want: map[string]string{
+				"merged":    "",
+				"locked":    "locked",
+				"modified":  "has uncommitted changes or untracked files",
+				"untracked": "has uncommitted changes or untracked files",
+				"current":   "contains the current working directory",
+			},
+		},
+		{
+			name: "merged into another base",
+			opts: PruneOptions{Merged: true, Base: "recent"},
+			want: map[string]string{
+				"merged":    "",
+				"locked":    "locked",
+				"modified":  "has uncommitted changes or untracked files",
+				"untracked": "has uncommitted changes or untracked files",
+				"current":   "contains the current working directory",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Measured from just after the latest commit, so only 'recent' is younger than a week
+			now := testEpoch.Add(31 * 24 * time.Hour)
+			stale, err := g.StaleTrees(tt.opts, now)
+			if err != nil {
+				t.Fatalf("StaleTrees() returned error: %v", err)
+			}
+
+			got := map[string]string{}
+			for _, tree := range stale {
+				name, err := filepath.Rel(root, tree.Path)
+				if err != nil {
+					t.Fatalf("tree %q is outside the grove: %v", tree.Path, err)
+				}
+				got[name] = tree.Skipped
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("StaleTrees() = %v, want %v", got, tt.want)
+			}
+			for name, skipped := range tt.want {
+				actual, found := got[name]
+				if !found {
+					t.Errorf("tree %q is not stale, want it to be", name)
+					continue
+				}
+				if actual != skipped {
+					t.Errorf("tree %q is skipped because %q, want %q", name, actual, skipped)
+				}
+			}
+		})
+	}
+}
+
+func TestStaleTreesRequiresCriterion(t *testing.T) {
+	g, _ := newTestGrove(t)
+
+	_, err := g.StaleTrees(PruneOptions{}, time.Now())
+	if err == nil {
+		t.Fatal("StaleTrees() returned no error without any criterion")
+	}
+}
+
+func TestRemoveTree(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare changes the tree at path before it is removed
+		prepare func(t *testing.T, g *Grove, path string)
+		wantErr bool
+	}{
+		{
+			name:    "clean",
+			prepare: func(*testing.T, *Grove, string) {},
+		},
+		{
+			name: "ignored files",
+			prepare: func(t *testing.T, _ *Grove, path string) {
+				commitFile(t, path, ".gitignore", "node_modules/\n", testEpoch)
+				writeFile(t, filepath.Join(path, "node_modules", "dep.js"), "ignored\n")
+			},
+		},
+		{
+			name: "modified",
+			prepare: func(t *testing.T, _ *Grove, path string) {
+				writeFile(t, filepath.Join(path, "README.md"), "changed\n")
+			},
+			wantErr: true,
+		},
+		{
+			name: "untracked",
+			prepare: func(t *testing.T, _ *Grove, path string) {
+				writeFile(t, filepath.Join(path, "newfile.txt"), "not committed\n")
+			},
+			wantErr: true,
+		},
+		{
+			name: "locked",
+			prepare: func(t *testing.T, g *Grove, path string) {
+				err := g.LockTree(path, "in use")
+				if err != nil {
+					t.Fatalf("failed to lock tree: %v", err)
+				}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, root := newTestGrove(t)
+			path := addTestTree(t, g, filepath.Join("feature", "tree"))
+			tt.prepare(t, g, path)
+
+			err := g.RemoveTree(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("RemoveTree() returned no error")
+				}
+				_, statErr := os.Stat(path)
+				if statErr != nil {
+					t.Errorf("tree was deleted despite the error: %v", statErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RemoveTree() returned error: %v", err)
+			}
+			_, err = os.Stat(filepath.Join(root, "feature"))
+			if !os.IsNotExist(err) {
+				t.Errorf("directory left empty by the tree was not removed: %v", err)
+			}
+		})
+	}
+}
+
+func TestRemoveTreeRefusesMainWorktree(t *testing.T) {
+	g, root := newTestGrove(t)
+
+	err := g.RemoveTree(filepath.Join(root, "main"))
+	if err == nil {
+		t.Fatal("RemoveTree() removed the main worktree")
+	}
+}
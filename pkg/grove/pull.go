@@ -0,0 +1,91 @@
+package grove
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/tnierman/git-grove/pkg/git/local"
+)
+
+// PullOptions configures how Pull updates a tree
+type PullOptions struct {
+	// Rebase replays the tree's local commits on top of its upstream when the two have diverged, rather than failing
+	Rebase bool
+	// Progress receives the human-readable progress information sent by the remote while fetching, if not nil
+	Progress io.Writer
+}
+
+// PullResult describes how Pull updated a tree
+type PullResult struct {
+	// Tree is the tree which was pulled, as it was before being updated
+	Tree Tree
+	// Upstream is the short name of the remote-tracking branch the tree was updated from
+	Upstream string
+	// Hash is the commit hash the tree's HEAD points to after being updated
+	Hash string
+	// Rebased indicates whether the tree's local commits were rebased onto its upstream
+	Rebased bool
+}
+
+// Pull fetches the upstream of the branch checked out in the tree at the given path, and fast-forwards the tree to
+// it. The fetch authenticates in the same way as cloning the grove.
+//
+// If the tree's branch has diverged from its upstream, local.ErrDiverged is returned rather than creating a merge
+// commit, unless opts.Rebase is set
+func (g *Grove) Pull(path string, opts PullOptions) (PullResult, error) {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return PullResult{}, err
+	}
+	if tree.Branch == "" {
+		return PullResult{}, fmt.Errorf("tree %q has a detached HEAD: no branch to pull", tree.Path)
+	}
+
+	upstream, err := g.repo.Upstream(tree.Branch)
+	if err != nil {
+		return PullResult{}, err
+	}
+	auth, err := g.RemoteAuth(upstream.Remote)
+	if err != nil {
+		return PullResult{}, err
+	}
+	err = g.Fetch(context.Background(), upstream.Remote, nil, auth, opts.Progress)
+	if err != nil {
+		return PullResult{}, err
+	}
+
+	target, err := g.repo.ResolveReference(upstream.TrackingRef)
+	if err != nil {
+		return PullResult{}, err
+	}
+	result := PullResult{
+		Tree:     tree,
+		Upstream: upstream.TrackingRef.Short(),
+		Hash:     target.String(),
+	}
+	if tree.Hash == result.Hash {
+		return result, nil
+	}
+
+	err = g.repo.FastForward(tree.Path, target)
+	if err == nil {
+		return result, nil
+	}
+	if !errors.Is(err, local.ErrDiverged) || !opts.Rebase {
+		return PullResult{}, fmt.Errorf("failed to fast-forward tree %q to %q: %w", tree.Path, result.Upstream, err)
+	}
+
+	err = g.repo.Rebase(tree.Path, target)
+	if err != nil {
+		return PullResult{}, err
+	}
+	rebased, err := g.Tree(tree.Path)
+	if err != nil {
+		return PullResult{}, err
+	}
+	result.Hash = rebased.Hash
+	result.Rebased = true
+	return result, nil
+}
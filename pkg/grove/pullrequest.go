@@ -0,0 +1,84 @@
+package grove
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/go-git/go-git/v6/plumbing/transport"
+)
+
+// Provider identifies a git hosting provider, which determines where the refs of pull requests are found
+type Provider string
+
+const (
+	// ProviderGitHub publishes each pull request's head at 'refs/pull/<number>/head'. Gitea and Forgejo use the
+	// same layout
+	ProviderGitHub Provider = "github"
+	// ProviderGitLab publishes each merge request's head at 'refs/merge-requests/<number>/head'
+	ProviderGitLab Provider = "gitlab"
+)
+
+// ParseProvider parses the given provider. An empty value is returned as is, so that it may be detected later
+func ParseProvider(value string) (Provider, error) {
+	switch Provider(value) {
+	case "", ProviderGitHub, ProviderGitLab:
+		return Provider(value), nil
+	default:
+		return "", fmt.Errorf("unsupported provider %q: expected %q or %q", value, ProviderGitHub, ProviderGitLab)
+	}
+}
+
+// DetectProvider guesses the provider hosting the repository at the given URL from its hostname, falling back to
+// ProviderGitHub
+func DetectProvider(url string) Provider {
+	endpoint, err := transport.NewEndpoint(url)
+	if err == nil && strings.Contains(strings.ToLower(endpoint.Hostname()), "gitlab") {
+		return ProviderGitLab
+	}
+	return ProviderGitHub
+}
+
+// PullRequestRef returns the ref the provider publishes the head of the given pull request at
+func (p Provider) PullRequestRef(number int) plumbing.ReferenceName {
+	if p == ProviderGitLab {
+		return plumbing.ReferenceName(fmt.Sprintf("refs/merge-requests/%d/head", number))
+	}
+	return plumbing.ReferenceName(fmt.Sprintf("refs/pull/%d/head", number))
+}
+
+// PullRequestBranch returns the name of the local branch the given pull request is checked out as
+func PullRequestBranch(number int) string {
+	return fmt.Sprintf("pr/%d", number)
+}
+
+// FetchPullRequest fetches the head of the given pull request from remote into the remote-tracking ref
+// 'refs/remotes/<remote>/pr/<number>', which is returned. If provider is empty, it is detected from the remote's URL
+func (g *Grove) FetchPullRequest(remote string, number int, provider Provider, progress io.Writer) (plumbing.ReferenceName, error) {
+	if number <= 0 {
+		return "", fmt.Errorf("invalid pull request number %d", number)
+	}
+
+	url, err := g.repo.RemoteURL(remote)
+	if err != nil {
+		return "", err
+	}
+	if provider == "" {
+		provider = DetectProvider(url)
+	}
+
+	auth, err := g.RemoteAuth(remote)
+	if err != nil {
+		return "", err
+	}
+
+	tracking := plumbing.NewRemoteReferenceName(remote, PullRequestBranch(number))
+	refSpec := fmt.Sprintf("+%s:%s", provider.PullRequestRef(number), tracking)
+	err = g.Fetch(context.Background(), remote, []string{refSpec}, auth, progress)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch pull request %d: %w", number, err)
+	}
+	return tracking, nil
+}
@@ -0,0 +1,93 @@
+package grove
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/tnierman/git-grove/pkg/git/local"
+	"github.com/tnierman/git-grove/pkg/git/remote"
+)
+
+// PushOptions configures how Push updates a tree's upstream
+type PushOptions struct {
+	// ForceWithLease allows the upstream to be overwritten with commits which do not descend from it, as long as
+	// the upstream has not changed since it was last fetched
+	ForceWithLease bool
+	// Progress receives the human-readable progress information sent by the remote while pushing, if not nil
+	Progress io.Writer
+}
+
+// PushResult describes how Push updated a tree's upstream
+type PushResult struct {
+	// Tree is the tree which was pushed
+	Tree Tree
+	// Remote is the name of the remote the tree's branch was pushed to
+	Remote string
+	// Branch is the short name of the branch on the remote which was updated
+	Branch string
+	// UpToDate indicates whether the remote branch already matched the tree's branch
+	UpToDate bool
+	// SetUpstream indicates whether the tree's branch had no upstream, and now tracks the pushed branch
+	SetUpstream bool
+}
+
+// Push pushes the branch checked out in the tree at the given path to its upstream, authenticating in the same way
+// as cloning the grove. If the branch has no upstream, it is pushed to the branch of the same name on the default
+// remote, which is then configured as its upstream
+func (g *Grove) Push(path string, opts PushOptions) (PushResult, error) {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return PushResult{}, err
+	}
+	if tree.Branch == "" {
+		return PushResult{}, fmt.Errorf("tree %q has a detached HEAD: no branch to push", tree.Path)
+	}
+
+	result := PushResult{
+		Tree: tree,
+	}
+	upstream, err := g.repo.Upstream(tree.Branch)
+	if err != nil {
+		if !errors.Is(err, local.ErrNoUpstream) {
+			return PushResult{}, err
+		}
+		upstream = local.Upstream{
+			Remote: remote.DefaultRemoteName,
+			Merge:  plumbing.NewBranchReferenceName(tree.Branch),
+		}
+		result.SetUpstream = true
+	}
+	result.Remote = upstream.Remote
+	result.Branch = upstream.Merge.Short()
+
+	url, err := g.repo.RemoteURL(upstream.Remote)
+	if err != nil {
+		return PushResult{}, err
+	}
+	auth, err := remote.ResolveAuth(url)
+	if err != nil {
+		return PushResult{}, fmt.Errorf("failed to authenticate with %q: %w", url, err)
+	}
+
+	err = g.repo.Push(upstream.Remote, tree.Branch, upstream.Merge, local.PushOptions{
+		Auth:           auth,
+		Progress:       opts.Progress,
+		ForceWithLease: opts.ForceWithLease,
+	})
+	if err != nil {
+		if !errors.Is(err, local.ErrAlreadyUpToDate) {
+			return PushResult{}, remote.Classify(err)
+		}
+		result.UpToDate = true
+	}
+
+	if result.SetUpstream {
+		err = g.repo.SetUpstream(tree.Branch, upstream.Remote, upstream.Merge)
+		if err != nil {
+			return PushResult{}, err
+		}
+	}
+	return result, nil
+}
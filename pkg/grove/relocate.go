@@ -0,0 +1,159 @@
+package grove
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/tnierman/git-grove/pkg/git/local"
+)
+
+// Relocate moves the entire grove to destination - relative to the current directory, unless already absolute - then
+// repairs the location recorded for each of its trees, so that the grove remains usable. destination must either not
+// exist, or be an empty directory. When it lies on a different filesystem, the grove is copied there, then removed.
+//
+// The absolute path of the grove's new root is returned. Afterwards, the Grove refers to the grove at its new location
+func (g *Grove) Relocate(destination string) (string, error) {
+	destination, err := ExpandPath(destination)
+	if err != nil {
+		return "", err
+	}
+	destination, err = filepath.Abs(destination)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine absolute path of %q: %w", destination, err)
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine grove root: %w", err)
+	}
+	if within(root, destination) {
+		return "", fmt.Errorf("cannot move grove %q within itself, to %q", root, destination)
+	}
+	err = checkEmptyDestination(destination)
+	if err != nil {
+		return "", err
+	}
+
+	// The moved repository is reopened from the same directory relative to the grove's root it was opened from
+	anchor := "."
+	bare, err := g.repo.IsBare()
+	if err != nil {
+		return "", err
+	}
+	if !bare {
+		mainWorktree, err := g.repo.MainWorktree()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine path to main worktree of repository: %w", err)
+		}
+		anchor, err = filepath.Rel(root, mainWorktree)
+		if err != nil {
+			return "", fmt.Errorf("failed to determine path of main worktree %q within grove: %w", mainWorktree, err)
+		}
+	}
+
+	err = moveDir(root, destination)
+	if err != nil {
+		return "", err
+	}
+
+	repo, err := local.NewRepository(filepath.Join(destination, anchor))
+	if err != nil {
+		return "", fmt.Errorf("failed to open moved repository: %w", err)
+	}
+	err = repo.RepairWorktrees(root, destination)
+	if err != nil {
+		return "", fmt.Errorf("grove was moved to %q, but its trees could not be repaired: %w", destination, err)
+	}
+	g.repo = repo
+	return destination, nil
+}
+
+// checkEmptyDestination returns an error unless path does not exist, or is an empty directory. Its parent directory
+// is created if missing
+func checkEmptyDestination(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to read %q: %w", path, err)
+		}
+		_, err = createDirs(filepath.Dir(path))
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("destination %q is not empty: found %q", path, entries[0].Name())
+	}
+	return nil
+}
+
+// moveDir moves the directory from to to, which must not exist or be empty. If the two are on different filesystems,
+// from is copied to to, then removed
+func moveDir(from, to string) error {
+	err := os.Rename(from, to)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return fmt.Errorf("failed to move %q to %q: %w", from, to, err)
+	}
+
+	err = copyDir(from, to)
+	if err != nil {
+		// Leave the original untouched, so nothing is lost
+		removeErr := os.RemoveAll(to)
+		if removeErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to cleanup directory %q: %v\n", to, removeErr)
+		}
+		return fmt.Errorf("failed to copy %q to %q: %w", from, to, err)
+	}
+	err = os.RemoveAll(from)
+	if err != nil {
+		return fmt.Errorf("grove was copied to %q, but %q could not be removed: %w", to, from, err)
+	}
+	return nil
+}
+
+// copyDir recursively copies the contents of the directory src into dst, preserving permissions and symlinks
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case entry.IsDir():
+			err = os.MkdirAll(target, info.Mode().Perm())
+			if err != nil {
+				return fmt.Errorf("failed to create %q: %w", target, err)
+			}
+			return nil
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink %q: %w", path, err)
+			}
+			err = os.Symlink(link, target)
+			if err != nil {
+				return fmt.Errorf("failed to create symlink %q: %w", target, err)
+			}
+			return nil
+		case info.Mode().IsRegular():
+			return copyFile(path, target, info.Mode().Perm())
+		default:
+			fmt.Fprintf(os.Stderr, "warning: skipping %q: not a regular file\n", path)
+			return nil
+		}
+	})
+}
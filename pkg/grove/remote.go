@@ -0,0 +1,56 @@
+package grove
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	"github.com/tnierman/git-grove/pkg/git/local"
+	gitremote "github.com/tnierman/git-grove/pkg/git/remote"
+)
+
+// AddRemote adds a remote with the given name and URL to the grove's repository
+func (g *Grove) AddRemote(name, url string) error {
+	return g.repo.AddRemote(name, url)
+}
+
+// Remotes lists every remote of the grove's repository, sorted by name
+func (g *Grove) Remotes() ([]local.Remote, error) {
+	return g.repo.Remotes()
+}
+
+// SetRemoteURL replaces the URL of the remote with the given name in the grove's repository
+func (g *Grove) SetRemoteURL(name, url string) error {
+	return g.repo.SetRemoteURL(name, url)
+}
+
+// RemoveRemote removes the remote with the given name from the grove's repository, along with its remote-tracking
+// branches
+func (g *Grove) RemoveRemote(name string) error {
+	return g.repo.RemoveRemote(name)
+}
+
+// Fetch updates the remote-tracking branches of the given remote, authenticating with auth. Fetching stops if ctx is
+// cancelled. Progress information sent by the remote is written to progress, if not nil.
+//
+// If refSpecs are given, they are fetched instead of the remote's configured refspecs. Failures to access the remote
+// are wrapped with remote.ErrAuthFailed or remote.ErrUnreachable, where applicable
+func (g *Grove) Fetch(ctx context.Context, remote string, refSpecs []string, auth transport.AuthMethod, progress io.Writer) error {
+	return gitremote.Classify(g.repo.Fetch(ctx, remote, refSpecs, auth, progress))
+}
+
+// RemoteAuth determines how to authenticate when fetching from the remote of the given name. As when cloning the
+// grove, credentials are only requested if the remote cannot be accessed anonymously. The grove's RateLimit, if any, is
+// applied to the operations the AuthMethod is used for
+func (g *Grove) RemoteAuth(name string) (transport.AuthMethod, error) {
+	url, err := g.repo.RemoteURL(name)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := gitremote.ResolveFetchAuth(context.Background(), url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate with %q: %w", url, err)
+	}
+	return g.RateLimit.Apply(auth), nil
+}
@@ -0,0 +1,45 @@
+package grove
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// ResetTree discards every uncommitted change in the tree at the given path, hard-resetting it to revision and
+// removing untracked files. If revision is empty, the tree is reset to the upstream of its branch.
+//
+// The commit the tree was reset to is returned
+func (g *Grove) ResetTree(path, revision string) (string, error) {
+	tree, err := g.Tree(path)
+	if err != nil {
+		return "", err
+	}
+
+	var commit plumbing.Hash
+	if revision != "" {
+		// Revisions such as 'HEAD' refer to the tree being reset, rather than the tree the grove was opened from
+		commit, err = g.repo.ResolveCommitIn(tree.Path, revision)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		if tree.Branch == "" {
+			return "", fmt.Errorf("tree %q has a detached HEAD: a revision to reset to must be provided", tree.Path)
+		}
+		upstream, err := g.repo.Upstream(tree.Branch)
+		if err != nil {
+			return "", fmt.Errorf("failed to determine upstream of tree %q: %w", tree.Path, err)
+		}
+		commit, err = g.repo.ResolveReference(upstream.TrackingRef)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = g.repo.ResetWorktree(tree.Path, commit)
+	if err != nil {
+		return "", err
+	}
+	return commit.String(), nil
+}
@@ -0,0 +1,86 @@
+package grove
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResetTree(t *testing.T) {
+	g, _ := newTestGrove(t)
+	path := addTestTree(t, g, "feature")
+	commitFile(t, path, ".gitignore", "ign/\n*.log\n.env\n", testEpoch)
+	head := commitFile(t, path, "tracked.txt", "committed\n", testEpoch)
+
+	writeFile(t, filepath.Join(path, "tracked.txt"), "modified\n")
+	writeFile(t, filepath.Join(path, "untracked.txt"), "untracked\n")
+	writeFile(t, filepath.Join(path, "scratch", "notes.txt"), "untracked\n")
+	writeFile(t, filepath.Join(path, "ign", "cache"), "ignored\n")
+	writeFile(t, filepath.Join(path, "ign", "nested", "cache"), "ignored\n")
+	writeFile(t, filepath.Join(path, "debug.log"), "ignored\n")
+	writeFile(t, filepath.Join(path, "scratch", "debug.log"), "ignored\n")
+	writeFile(t, filepath.Join(path, ".env"), "SECRET=1\n")
+
+	reset, err := g.ResetTree(path, "HEAD")
+	if err != nil {
+		t.Fatalf("ResetTree() returned error: %v", err)
+	}
+	if reset != head.String() {
+		t.Errorf("ResetTree() reset to %q, want %q", reset, head)
+	}
+
+	tests := []struct {
+		file    string
+		want    string
+		removed bool
+	}{
+		{file: "tracked.txt", want: "committed\n"},
+		{file: "untracked.txt", removed: true},
+		{file: filepath.Join("scratch", "notes.txt"), removed: true},
+		{file: filepath.Join("ign", "cache"), want: "ignored\n"},
+		{file: filepath.Join("ign", "nested", "cache"), want: "ignored\n"},
+		{file: "debug.log", want: "ignored\n"},
+		{file: filepath.Join("scratch", "debug.log"), want: "ignored\n"},
+		{file: ".env", want: "SECRET=1\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(path, tt.file))
+			if tt.removed {
+				if !os.IsNotExist(err) {
+					t.Errorf("%q was not removed: %v", tt.file, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%q was removed: %v", tt.file, err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("%q contains %q, want %q", tt.file, data, tt.want)
+			}
+		})
+	}
+}
+
+func TestResetTreeToEarlierCommit(t *testing.T) {
+	g, _ := newTestGrove(t)
+	path := addTestTree(t, g, "feature")
+	earlier := commitFile(t, path, "first.txt", "first\n", testEpoch)
+	commitFile(t, path, "second.txt", "second\n", testEpoch)
+
+	reset, err := g.ResetTree(path, earlier.String())
+	if err != nil {
+		t.Fatalf("ResetTree() returned error: %v", err)
+	}
+	if reset != earlier.String() {
+		t.Errorf("ResetTree() reset to %q, want %q", reset, earlier)
+	}
+	_, err = os.Stat(filepath.Join(path, "first.txt"))
+	if err != nil {
+		t.Errorf("file committed before the reset commit was removed: %v", err)
+	}
+	_, err = os.Stat(filepath.Join(path, "second.txt"))
+	if !os.IsNotExist(err) {
+		t.Errorf("file committed after the reset commit was not removed: %v", err)
+	}
+}
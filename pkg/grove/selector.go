@@ -0,0 +1,104 @@
+package grove
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Selector filters trees by the name of the branch they have checked out. The zero Selector selects every tree
+type Selector struct {
+	match   []branchPattern
+	exclude []branchPattern
+}
+
+// branchPattern matches branch names against either a glob or a regular expression
+type branchPattern struct {
+	glob  string
+	regex *regexp.Regexp
+}
+
+// ParseSelector parses the given patterns into a Selector. A tree is selected if its branch matches any of the match
+// patterns - or if none are given - and none of the exclude patterns.
+//
+// Each pattern is a glob, as understood by path.Match, such as 'release/*'. Patterns wrapped in slashes, such as
+// '/^release\/v[0-9]+$/', are regular expressions instead
+func ParseSelector(match, exclude []string) (Selector, error) {
+	sel := Selector{}
+	for _, pattern := range match {
+		p, err := parseBranchPattern(pattern)
+		if err != nil {
+			return Selector{}, err
+		}
+		sel.match = append(sel.match, p)
+	}
+	for _, pattern := range exclude {
+		p, err := parseBranchPattern(pattern)
+		if err != nil {
+			return Selector{}, err
+		}
+		sel.exclude = append(sel.exclude, p)
+	}
+	return sel, nil
+}
+
+// parseBranchPattern parses a single glob or slash-delimited regular expression
+func parseBranchPattern(pattern string) (branchPattern, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		regex, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return branchPattern{}, fmt.Errorf("invalid branch pattern %q: %w", pattern, err)
+		}
+		return branchPattern{regex: regex}, nil
+	}
+
+	// Validate the glob up front, rather than when it is first matched
+	_, err := path.Match(pattern, "")
+	if err != nil {
+		return branchPattern{}, fmt.Errorf("invalid branch pattern %q: %w", pattern, err)
+	}
+	return branchPattern{glob: pattern}, nil
+}
+
+// matches determines whether the given branch matches the pattern
+func (p branchPattern) matches(branch string) bool {
+	if p.regex != nil {
+		return p.regex.MatchString(branch)
+	}
+	// The glob was validated when parsed, so no error can occur
+	match, _ := path.Match(p.glob, branch)
+	return match
+}
+
+// Matches determines whether the given tree is selected. Trees with a detached HEAD have no branch, so they are only
+// selected when no match patterns are given
+func (s Selector) Matches(tree Tree) bool {
+	if len(s.match) > 0 {
+		if tree.Branch == "" || !anyMatch(s.match, tree.Branch) {
+			return false
+		}
+	}
+	return tree.Branch == "" || !anyMatch(s.exclude, tree.Branch)
+}
+
+// Select returns the given trees which are selected, preserving their order
+func (s Selector) Select(trees []Tree) []Tree {
+	selected := []Tree{}
+	for _, tree := range trees {
+		if s.Matches(tree) {
+			selected = append(selected, tree)
+		}
+	}
+	return selected
+}
+
+// anyMatch determines whether the branch matches any of the given patterns
+func anyMatch(patterns []branchPattern, branch string) bool {
+	for _, p := range patterns {
+		if p.matches(branch) {
+			return true
+		}
+	}
+	return false
+}
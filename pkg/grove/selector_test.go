@@ -0,0 +1,100 @@
+package grove
+
+import (
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		match   []string
+		exclude []string
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "glob", match: []string{"release/*"}, exclude: []string{"feature/draft-*"}},
+		{name: "regex", match: []string{`/^release\/v[0-9]+$/`}},
+		{name: "single slash is a glob", match: []string{"/"}},
+		{name: "invalid glob", match: []string{"release/["}, wantErr: true},
+		{name: "invalid exclude glob", exclude: []string{"["}, wantErr: true},
+		{name: "invalid regex", match: []string{"/(/"}, wantErr: true},
+		{name: "invalid exclude regex", exclude: []string{"/[a-/"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSelector(tt.match, tt.exclude)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSelector(%v, %v) returned error %v, want error: %v", tt.match, tt.exclude, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		match   []string
+		exclude []string
+		branch  string
+		want    bool
+	}{
+		{name: "no patterns", branch: "main", want: true},
+		{name: "no patterns, detached", branch: "", want: true},
+		{name: "glob", match: []string{"release/*"}, branch: "release/1.0", want: true},
+		{name: "glob mismatch", match: []string{"release/*"}, branch: "main", want: false},
+		// As with path.Match, '*' does not match '/'
+		{name: "glob within one segment", match: []string{"release/*"}, branch: "release/1.0/hotfix", want: false},
+		{name: "any glob", match: []string{"main", "release/*"}, branch: "main", want: true},
+		{name: "regex", match: []string{`/^v[0-9]+$/`}, branch: "v12", want: true},
+		{name: "regex mismatch", match: []string{`/^v[0-9]+$/`}, branch: "v12-rc", want: false},
+		{name: "unanchored regex", match: []string{"/fix/"}, branch: "feature/bugfix-1", want: true},
+		{name: "exclude", exclude: []string{"feature/draft-*"}, branch: "feature/draft-x", want: false},
+		{name: "exclude mismatch", exclude: []string{"feature/draft-*"}, branch: "feature/x", want: true},
+		{name: "exclude regex", exclude: []string{"/^wip/"}, branch: "wip/x", want: false},
+		{name: "match and exclude", match: []string{"feature/*"}, exclude: []string{"feature/draft-*"}, branch: "feature/x", want: true},
+		{name: "excluded match", match: []string{"feature/*"}, exclude: []string{"feature/draft-*"}, branch: "feature/draft-x", want: false},
+		// Detached trees have no branch to match
+		{name: "detached with match", match: []string{"*"}, branch: "", want: false},
+		{name: "detached with exclude", exclude: []string{"*"}, branch: "", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sel, err := ParseSelector(tt.match, tt.exclude)
+			if err != nil {
+				t.Fatalf("ParseSelector(%v, %v) returned error: %v", tt.match, tt.exclude, err)
+			}
+			got := sel.Matches(Tree{Branch: tt.branch})
+			if got != tt.want {
+				t.Errorf("Matches(%q) = %v, want %v", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectorSelect(t *testing.T) {
+	trees := []Tree{
+		{Path: "/grove/main", Branch: "main"},
+		{Path: "/grove/feature-x", Branch: "feature/x"},
+		{Path: "/grove/detached"},
+		{Path: "/grove/feature-y", Branch: "feature/y"},
+	}
+	sel, err := ParseSelector([]string{"feature/*"}, []string{"feature/x"})
+	if err != nil {
+		t.Fatalf("ParseSelector() returned error: %v", err)
+	}
+
+	got := sel.Select(trees)
+	if len(got) != 1 || got[0].Path != "/grove/feature-y" {
+		t.Errorf("Select() = %v, want only the tree of feature/y", got)
+	}
+	// The zero Selector selects every tree, in order
+	got = Selector{}.Select(trees)
+	if len(got) != len(trees) {
+		t.Fatalf("Selector{}.Select() = %v, want every tree", got)
+	}
+	for i := range trees {
+		if got[i].Path != trees[i].Path {
+			t.Errorf("Selector{}.Select()[%d] = %q, want %q", i, got[i].Path, trees[i].Path)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package grove
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v6/plumbing"
+)
+
+// SnapshotVersion is the version of the snapshot format written by Snapshot
+const SnapshotVersion = 1
+
+// Snapshot records the set of trees in a grove, so that they can be recreated in another grove of the same
+// repository - such as on another machine, or after a fresh clone
+type Snapshot struct {
+	// Version is the version of the snapshot's format
+	Version int `json:"version"`
+	// Trees are the grove's trees, starting with the main worktree unless the grove is bare
+	Trees []SnapshotTree `json:"trees"`
+}
+
+// SnapshotTree records a single tree of a Snapshot
+type SnapshotTree struct {
+	// Path is the path of the tree, relative to the grove's root
+	Path string `json:"path"`
+	// Branch is the branch checked out in the tree. It is empty when the tree has a detached HEAD
+	Branch string `json:"branch,omitempty"`
+	// Ref is the commit the tree's HEAD pointed to when the snapshot was taken
+	Ref string `json:"ref"`
+}
+
+// Snapshot records the grove's trees: the branch checked out in each, and where
+func (g *Grove) Snapshot() (Snapshot, error) {
+	root, err := g.Root()
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to determine grove root: %w", err)
+	}
+	trees, err := g.Trees()
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot := Snapshot{Version: SnapshotVersion, Trees: make([]SnapshotTree, 0, len(trees))}
+	for _, tree := range trees {
+		relative, err := filepath.Rel(root, tree.Path)
+		if err != nil || !filepath.IsLocal(relative) {
+			return Snapshot{}, fmt.Errorf("tree %q is not within the grove's root %q", tree.Path, root)
+		}
+		snapshot.Trees = append(snapshot.Trees, SnapshotTree{
+			Path:   filepath.ToSlash(relative),
+			Branch: tree.Branch,
+			Ref:    tree.Hash,
+		})
+	}
+	return snapshot, nil
+}
+
+// RestoreSnapshot creates each tree recorded by snapshot which is not already present in the grove, in order.
+//
+// A branch which exists in the grove, either locally or on a remote, is checked out as it is now; otherwise, it is
+// created at the commit recorded by the snapshot. Trees with a detached HEAD are checked out at the recorded commit.
+// Trees already present at their recorded path are skipped, provided they check out the same branch.
+//
+// A failure to create one tree does not prevent the others from being created; the outcome of each tree not skipped
+// is returned
+func (g *Grove) RestoreSnapshot(snapshot Snapshot) ([]TreeResult, error) {
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d (expected %d)", snapshot.Version, SnapshotVersion)
+	}
+	root, err := g.Root()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine grove root: %w", err)
+	}
+
+	results := []TreeResult{}
+	for _, recorded := range snapshot.Trees {
+		relative := filepath.FromSlash(recorded.Path)
+		if !filepath.IsLocal(relative) {
+			results = append(results, TreeResult{
+				Path:   recorded.Path,
+				Branch: recorded.Branch,
+				Err:    fmt.Errorf("path %q is not within the grove", recorded.Path),
+			})
+			continue
+		}
+		path := filepath.Join(root, relative)
+
+		existing, err := g.Tree(path)
+		if err == nil {
+			if existing.Branch != recorded.Branch {
+				results = append(results, TreeResult{
+					Path:   path,
+					Branch: recorded.Branch,
+					Err:    fmt.Errorf("tree %q already exists, checking out %q instead", path, existing.Branch),
+				})
+			}
+			continue
+		}
+
+		results = append(results, TreeResult{
+			Path:   path,
+			Branch: recorded.Branch,
+			Err:    g.restoreTree(path, recorded),
+		})
+	}
+	return results, nil
+}
+
+// restoreTree creates the tree recorded by a snapshot at path
+func (g *Grove) restoreTree(path string, recorded SnapshotTree) error {
+	if recorded.Branch == "" {
+		return g.AddTree(path, AddTreeOptions{Detach: true, Commit: recorded.Ref})
+	}
+
+	opts := AddTreeOptions{Branch: recorded.Branch}
+	_, err := g.repo.ResolveReference(plumbing.NewBranchReferenceName(recorded.Branch))
+	if err != nil {
+		// Branches found on a remote are created from it, and track it
+		_, onRemote, err := g.repo.TrackingBranch(recorded.Branch)
+		if err != nil {
+			return err
+		}
+		if !onRemote {
+			opts.Commit = recorded.Ref
+		}
+	}
+	return g.AddTree(path, opts)
+}
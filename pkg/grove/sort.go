@@ -0,0 +1,117 @@
+package grove
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// SortKey determines the order ListTrees returns trees in
+type SortKey string
+
+const (
+	// SortNone keeps the order of Trees: the main worktree first, followed by each linked worktree
+	SortNone SortKey = ""
+	// SortPath orders trees alphabetically by path
+	SortPath SortKey = "path"
+	// SortBranch orders trees alphabetically by branch, followed by those with a detached HEAD
+	SortBranch SortKey = "branch"
+	// SortDate orders trees by the time of their HEAD commit, most recent first
+	SortDate SortKey = "date"
+	// SortAhead orders trees by the number of commits they are ahead of their upstream, most first
+	SortAhead SortKey = "ahead"
+	// SortBehind orders trees by the number of commits they are behind their upstream, most first
+	SortBehind SortKey = "behind"
+)
+
+// ParseSortKey parses the given sort key: one of 'path', 'branch', 'date', 'ahead', or 'behind'. An empty key keeps
+// the trees' default order
+func ParseSortKey(key string) (SortKey, error) {
+	switch SortKey(key) {
+	case SortNone, SortPath, SortBranch, SortDate, SortAhead, SortBehind:
+		return SortKey(key), nil
+	}
+	return "", fmt.Errorf("invalid sort key %q: expected one of 'path', 'branch', 'date', 'ahead', or 'behind'", key)
+}
+
+// ListOptions configures which trees ListTrees returns, and in which order
+type ListOptions struct {
+	// Sort is the order trees are returned in
+	Sort SortKey
+	// Dirty only returns trees with uncommitted changes or untracked files
+	Dirty bool
+	// Ahead only returns trees whose branch is ahead of its upstream
+	Ahead bool
+	// Behind only returns trees whose branch is behind its upstream
+	Behind bool
+	// Selector only returns trees whose branch it selects. The zero Selector selects every tree
+	Selector Selector
+	// Details gathers the detailed information described by TreeInfo for every tree, even if neither Sort nor any
+	// filter requires it
+	Details bool
+}
+
+// needsDetails reports whether sorting or filtering by the options requires more than the information given by Trees
+func (o ListOptions) needsDetails() bool {
+	return o.Details || o.Dirty || o.Ahead || o.Behind || o.Sort == SortDate || o.Sort == SortAhead || o.Sort == SortBehind
+}
+
+// ListTrees lists the trees in the grove matching every filter of opts, ordered by opts.Sort. Detailed information is
+// only gathered about each tree when needed, as it is slow to gather for large trees: otherwise, only the embedded Tree
+// of each TreeInfo is set
+func (g *Grove) ListTrees(opts ListOptions) ([]TreeInfo, error) {
+	trees, err := g.Trees()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]TreeInfo, 0, len(trees))
+	for _, tree := range opts.Selector.Select(trees) {
+		info := TreeInfo{Tree: tree}
+		if opts.needsDetails() {
+			info, err = g.Info(tree.Path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to describe tree %q: %w", tree.Path, err)
+			}
+		}
+
+		if (opts.Dirty && !info.Dirty()) || (opts.Ahead && info.Ahead == 0) || (opts.Behind && info.Behind == 0) {
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	sortTrees(infos, opts.Sort)
+	return infos, nil
+}
+
+// sortTrees orders infos by key, keeping the existing order of trees which compare equal
+func sortTrees(infos []TreeInfo, key SortKey) {
+	less := map[SortKey]func(a, b TreeInfo) bool{
+		SortPath: func(a, b TreeInfo) bool {
+			return filepath.Clean(a.Path) < filepath.Clean(b.Path)
+		},
+		SortBranch: func(a, b TreeInfo) bool {
+			// Detached trees have no branch, so follow every tree which does
+			if a.Branch == "" || b.Branch == "" {
+				return a.Branch != ""
+			}
+			return a.Branch < b.Branch
+		},
+		SortDate: func(a, b TreeInfo) bool {
+			return a.LastCommit.After(b.LastCommit)
+		},
+		SortAhead: func(a, b TreeInfo) bool {
+			return a.Ahead > b.Ahead
+		},
+		SortBehind: func(a, b TreeInfo) bool {
+			return a.Behind > b.Behind
+		},
+	}[key]
+	if less == nil {
+		return
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return less(infos[i], infos[j])
+	})
+}
@@ -0,0 +1,189 @@
+package grove
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v6/plumbing"
+	"github.com/tnierman/git-grove/pkg/config"
+	"github.com/tnierman/git-grove/pkg/git/local"
+)
+
+// StashRecord is the name of the file within config.GroveDir recording the trees stashed by StashTrees, so that
+// PopStashes restores exactly those trees
+const StashRecord = "stashes"
+
+// stashMessage is the message of each stash pushed by StashTrees, so that they can be told apart in 'git stash list'
+const stashMessage = "grove stash"
+
+// StashedTree records a single tree stashed by StashTrees
+type StashedTree struct {
+	// Path is the path of the tree, relative to the grove's root
+	Path string `json:"path"`
+	// Branch is the branch checked out in the tree when it was stashed. It is empty when the tree had a detached HEAD
+	Branch string `json:"branch,omitempty"`
+	// Stash is the commit recording the tree's stash
+	Stash string `json:"stash"`
+}
+
+// StashTrees stashes the uncommitted changes, including untracked files, of every tree selected by sel which has any,
+// recording which trees were stashed. A failure to stash one tree does not prevent the others from being stashed; the outcome of each
+// is returned.
+//
+// An error is returned if trees are already recorded as stashed, as they must be popped first
+func (g *Grove) StashTrees(sel Selector) ([]TreeResult, error) {
+	stashed, err := g.StashedTrees()
+	if err != nil {
+		return nil, err
+	}
+	if len(stashed) > 0 {
+		return nil, fmt.Errorf("%d trees are already stashed: pop them first", len(stashed))
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine grove root: %w", err)
+	}
+	trees, err := g.Trees()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []TreeResult{}
+	for _, tree := range sel.Select(trees) {
+		info, err := g.Info(tree.Path)
+		if err != nil {
+			results = append(results, TreeResult{Path: tree.Path, Branch: tree.Branch, Err: err})
+			continue
+		}
+		if !info.Dirty() {
+			continue
+		}
+
+		result := TreeResult{Path: tree.Path, Branch: tree.Branch}
+		stash, err := g.repo.Stash(tree.Path, stashMessage)
+		if errors.Is(err, local.ErrNothingToStash) {
+			continue
+		}
+		if err != nil {
+			result.Err = err
+			results = append(results, result)
+			continue
+		}
+		results = append(results, result)
+
+		relative, err := filepath.Rel(root, tree.Path)
+		if err != nil {
+			relative = tree.Path
+		}
+		stashed = append(stashed, StashedTree{Path: relative, Branch: tree.Branch, Stash: stash.String()})
+	}
+
+	// Record the trees stashed so far, even if others failed, so that none of their stashes are forgotten
+	err = g.writeStashRecord(stashed)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// PopStashes restores the stash of each tree recorded by StashTrees, then forgets it. Trees whose stash cannot be
+// popped - such as when it conflicts with changes made since - stay recorded, so that popping them can be retried. A
+// failure to pop one tree's stash does not prevent the others from being popped; the outcome of each is returned
+func (g *Grove) PopStashes() ([]TreeResult, error) {
+	stashed, err := g.StashedTrees()
+	if err != nil {
+		return nil, err
+	}
+	root, err := g.Root()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine grove root: %w", err)
+	}
+
+	results := make([]TreeResult, 0, len(stashed))
+	remaining := []StashedTree{}
+	for _, entry := range stashed {
+		path := entry.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		result := TreeResult{Path: path, Branch: entry.Branch}
+		tree, err := g.Tree(path)
+		if err == nil {
+			err = g.repo.PopStash(tree.Path, plumbing.NewHash(entry.Stash))
+		}
+		if err != nil {
+			result.Err = err
+			remaining = append(remaining, entry)
+		}
+		results = append(results, result)
+	}
+
+	err = g.writeStashRecord(remaining)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// StashedTrees lists the trees recorded as stashed by StashTrees, which have not been popped yet
+func (g *Grove) StashedTrees() ([]StashedTree, error) {
+	path, err := g.stashRecordPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []StashedTree{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stash record %q: %w", path, err)
+	}
+	stashed := []StashedTree{}
+	err = json.Unmarshal(data, &stashed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stash record %q: %w", path, err)
+	}
+	return stashed, nil
+}
+
+// writeStashRecord records the given trees as stashed, removing the record entirely if there are none
+func (g *Grove) writeStashRecord(stashed []StashedTree) error {
+	path, err := g.stashRecordPath()
+	if err != nil {
+		return err
+	}
+	if len(stashed) == 0 {
+		err = os.Remove(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove stash record %q: %w", path, err)
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(stashed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode stash record: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return fmt.Errorf("failed to create directory %q: %w", filepath.Dir(path), err)
+	}
+	err = os.WriteFile(path, append(data, '\n'), 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to write stash record %q: %w", path, err)
+	}
+	return nil
+}
+
+// stashRecordPath gives the path of the grove's StashRecord
+func (g *Grove) stashRecordPath() (string, error) {
+	root, err := g.Root()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine grove root: %w", err)
+	}
+	return filepath.Join(root, config.GroveDir, StashRecord), nil
+}
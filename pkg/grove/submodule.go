@@ -0,0 +1,81 @@
+package grove
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-git/go-git/v6/plumbing/transport"
+	gitremote "github.com/tnierman/git-grove/pkg/git/remote"
+)
+
+// SubmoduleAuth determines how to authenticate when cloning or fetching the submodule at the given URL. A nil
+// AuthMethod is returned if no authentication is needed
+type SubmoduleAuth func(ctx context.Context, url string) (transport.AuthMethod, error)
+
+// UpdateSubmodules initializes every submodule of the tree at the given path, then clones or fetches it and checks
+// out the commit the tree records for it, as with 'git submodule update --init --recursive'. Submodules of those
+// submodules are updated in turn. Updating stops if ctx is cancelled.
+//
+// Credentials for each submodule are determined by the grove's SubmoduleAuth - or, if unset, as when fetching from a
+// remote, only requesting them if the submodule cannot be accessed anonymously - and are reused for every other
+// submodule at the same URL.
+//
+// As with git, each tree's submodules are cloned into the tree's own git directory, so their objects are not shared
+// with the submodules of any other tree. Only the submodules' configuration, such as their URLs, is shared
+func (g *Grove) UpdateSubmodules(ctx context.Context, path string) error {
+	path, err := g.resolvePath(path)
+	if err != nil {
+		return err
+	}
+	return g.updateSubmodules(ctx, path)
+}
+
+// updateSubmodules updates the submodules of the worktree at path - either a tree, or the checkout of a submodule -
+// then recurses into each of them
+func (g *Grove) updateSubmodules(ctx context.Context, path string) error {
+	submodules, err := g.repo.Submodules(path)
+	if err != nil {
+		return err
+	}
+	for _, submodule := range submodules {
+		auth, err := g.submoduleAuth(ctx, submodule.URL)
+		if err != nil {
+			return fmt.Errorf("failed to authenticate with %q for submodule %q: %w", submodule.URL, submodule.Name, err)
+		}
+		err = g.repo.UpdateSubmodule(ctx, path, submodule.Name, auth)
+		if err != nil {
+			return gitremote.Classify(err)
+		}
+		err = g.updateSubmodules(ctx, submodule.Path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// submoduleAuth determines how to authenticate with the submodule at the given URL, reusing the credentials already
+// determined for it. Credentials are determined for one submodule at a time, so that prompts for them are never
+// interleaved when several trees are created concurrently
+func (g *Grove) submoduleAuth(ctx context.Context, url string) (transport.AuthMethod, error) {
+	g.submoduleAuthsMu.Lock()
+	defer g.submoduleAuthsMu.Unlock()
+
+	auth, found := g.submoduleAuths[url]
+	if found {
+		return auth, nil
+	}
+	resolve := g.SubmoduleAuth
+	if resolve == nil {
+		resolve = gitremote.ResolveFetchAuth
+	}
+	auth, err := resolve(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if g.submoduleAuths == nil {
+		g.submoduleAuths = map[string]transport.AuthMethod{}
+	}
+	g.submoduleAuths[url] = auth
+	return auth, nil
+}
@@ -0,0 +1,50 @@
+package grove
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/tnierman/git-grove/pkg/git/local"
+)
+
+// Summary describes the contents of a grove
+type Summary struct {
+	local.RepositoryStats
+	// Size is the number of bytes the grove occupies on disk, including its repository and every tree
+	Size int64
+}
+
+// Summary summarizes the contents of the grove. Its size is measured by walking the grove's root directory, so may
+// take a while for groves with many trees
+func (g *Grove) Summary() (Summary, error) {
+	stats, err := g.repo.Stats()
+	if err != nil {
+		return Summary{}, err
+	}
+
+	root, err := g.Root()
+	if err != nil {
+		return Summary{}, err
+	}
+	size := int64(0)
+	err = filepath.WalkDir(root, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to measure size of grove %q: %w", root, err)
+	}
+
+	return Summary{RepositoryStats: stats, Size: size}, nil
+}
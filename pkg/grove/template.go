@@ -0,0 +1,111 @@
+package grove
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/tnierman/git-grove/pkg/git/local"
+)
+
+// checkTemplate verifies the template directory at the given path exists, returning its expanded path
+func checkTemplate(path string) (string, error) {
+	path, err := ExpandPath(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template %q: %w", path, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("template %q is not a directory", path)
+	}
+	return path, nil
+}
+
+// copyTemplate copies the contents of the template directory into the tree rooted at dest. Files which already exist
+// in the tree - such as those checked out from the branch - are left untouched, with a warning
+func copyTemplate(template, dest string) error {
+	return filepath.WalkDir(template, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(template, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		// A template's own repository must not replace the tree's
+		if rel == local.GitStorePath {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		target := filepath.Join(dest, rel)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			err = os.MkdirAll(target, info.Mode().Perm())
+			if err != nil {
+				return fmt.Errorf("failed to create %q: %w", target, err)
+			}
+			return nil
+		}
+
+		_, err = os.Lstat(target)
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping template file %q: %q already exists in the tree\n", rel, target)
+			return nil
+		}
+
+		if info.Mode()&fs.ModeSymlink != 0 {
+			link, err := os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("failed to read symlink %q: %w", path, err)
+			}
+			err = os.Symlink(link, target)
+			if err != nil {
+				return fmt.Errorf("failed to create symlink %q: %w", target, err)
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			fmt.Fprintf(os.Stderr, "warning: skipping template file %q: not a regular file\n", rel)
+			return nil
+		}
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+// copyFile copies the regular file at src to the new file dst, created with the given permissions
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create %q: %w", dst, err)
+	}
+	_, err = io.Copy(out, in)
+	if err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy %q to %q: %w", src, dst, err)
+	}
+	err = out.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write %q: %w", dst, err)
+	}
+	return nil
+}
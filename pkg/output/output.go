@@ -0,0 +1,166 @@
+/*
+output defines the machine-readable event stream emitted by long-running grove commands
+*/
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Format determines how a command reports its progress and results
+type Format string
+
+const (
+	// FormatText reports progress and results as human-readable text
+	FormatText Format = "text"
+	// FormatJSON reports progress and results as a stream of newline-delimited JSON Events
+	FormatJSON Format = "json"
+)
+
+// ParseFormat validates the given output format
+func ParseFormat(format string) (Format, error) {
+	switch Format(format) {
+	case FormatText, FormatJSON:
+		return Format(format), nil
+	default:
+		return "", fmt.Errorf("invalid output format %q: expected one of %q or %q", format, FormatText, FormatJSON)
+	}
+}
+
+// EventsSelected determines whether a command reports its progress and results as a stream of JSON Events, rather than
+// as human-readable text: either resultFormat - the value of the ResultFormatFlag - is ResultFormatJSON, or legacyFormat
+// - the value of the command's deprecated '--output' flag - is FormatJSON. Events have no YAML form, so
+// ResultFormatYAML is rejected for the named command
+func EventsSelected(resultFormat, legacyFormat, command string) (bool, error) {
+	format, err := ParseResultFormat(resultFormat)
+	if err != nil {
+		return false, err
+	}
+	if format == ResultFormatYAML {
+		return false, fmt.Errorf("--%s %s is not supported by %s: use %s for a stream of JSON events", ResultFormatFlag, format, command, ResultFormatJSON)
+	}
+	legacy, err := ParseFormat(legacyFormat)
+	if err != nil {
+		return false, err
+	}
+	return format == ResultFormatJSON || legacy == FormatJSON, nil
+}
+
+// EventType identifies the kind of an Event
+type EventType string
+
+const (
+	// EventStarted is emitted once, when a command begins
+	EventStarted EventType = "started"
+	// EventProgress is emitted as a stage of a command, such as receiving objects from a remote, advances
+	EventProgress EventType = "progress"
+	// EventTree is emitted with the outcome of the command for a single tree
+	EventTree EventType = "tree"
+	// EventDone is emitted once, when a command finishes
+	EventDone EventType = "done"
+)
+
+// Event is a single entry in the event stream. Fields which do not apply to the event's Type are omitted
+type Event struct {
+	// Type identifies the kind of event
+	Type EventType `json:"type"`
+	// Command is the name of the command which started, for EventStarted
+	Command string `json:"command,omitempty"`
+	// Stage describes the step which advanced, for EventProgress
+	Stage string `json:"stage,omitempty"`
+	// Percent is the completion percentage of Stage, for EventProgress
+	Percent *int `json:"percent,omitempty"`
+	// Tree is the absolute path of the tree, for EventTree
+	Tree string `json:"tree,omitempty"`
+	// Branch is the branch checked out in the tree, for EventTree
+	Branch string `json:"branch,omitempty"`
+	// Output holds any output the command produced for the tree, for EventTree
+	Output string `json:"output,omitempty"`
+	// Error describes why the command failed, for EventTree and EventDone. It is omitted on success
+	Error string `json:"error,omitempty"`
+}
+
+// Emitter writes Events to an underlying writer as newline-delimited JSON. It is safe for concurrent use
+type Emitter struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewEmitter creates an Emitter which writes to w
+func NewEmitter(w io.Writer) *Emitter {
+	return &Emitter{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// Emit writes the given event to the stream
+func (e *Emitter) Emit(event Event) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	// Events are simple structs, so encoding can only fail when writing - which there's no one to report to
+	_ = e.encoder.Encode(event)
+}
+
+// Started emits an EventStarted for the given command
+func (e *Emitter) Started(command string) {
+	e.Emit(Event{Type: EventStarted, Command: command})
+}
+
+// Done emits an EventDone, recording err if it is not nil
+func (e *Emitter) Done(err error) {
+	event := Event{Type: EventDone}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	e.Emit(event)
+}
+
+// ProgressWriter converts the human-readable progress information sent by a git remote into EventProgress events
+type ProgressWriter struct {
+	progressLines
+	emitter *Emitter
+	stage   string
+	percent int
+}
+
+// Progress creates a ProgressWriter which emits its events to e
+func (e *Emitter) Progress() *ProgressWriter {
+	p := &ProgressWriter{
+		emitter: e,
+		percent: -1,
+	}
+	p.handle = p.parse
+	return p
+}
+
+// parse emits an EventProgress for the given line, if it reports progress which has not already been emitted
+func (p *ProgressWriter) parse(line string) {
+	stage, percent, ok := parseProgress(line)
+	if !ok {
+		return
+	}
+	if stage == p.stage && percent == p.percent {
+		return
+	}
+	p.stage = stage
+	p.percent = percent
+	p.emitter.Emit(Event{Type: EventProgress, Stage: p.stage, Percent: &percent})
+}
+
+// FormatBytes describes the given number of bytes in the largest binary unit it contains
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,38 @@
+package output
+
+import "testing"
+
+func TestEventsSelected(t *testing.T) {
+	tests := []struct {
+		name         string
+		resultFormat string
+		legacyFormat string
+		want         bool
+		wantErr      bool
+	}{
+		{name: "table", resultFormat: "table", legacyFormat: "text", want: false},
+		{name: "json", resultFormat: "json", legacyFormat: "text", want: true},
+		{name: "legacy json", resultFormat: "table", legacyFormat: "json", want: true},
+		{name: "both json", resultFormat: "json", legacyFormat: "json", want: true},
+		{name: "yaml", resultFormat: "yaml", legacyFormat: "text", wantErr: true},
+		{name: "invalid", resultFormat: "xml", legacyFormat: "text", wantErr: true},
+		{name: "invalid legacy", resultFormat: "table", legacyFormat: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EventsSelected(tt.resultFormat, tt.legacyFormat, "foreach")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("EventsSelected(%q, %q) = %v, want an error", tt.resultFormat, tt.legacyFormat, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EventsSelected(%q, %q) returned error: %v", tt.resultFormat, tt.legacyFormat, err)
+			}
+			if got != tt.want {
+				t.Errorf("EventsSelected(%q, %q) = %v, want %v", tt.resultFormat, tt.legacyFormat, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	// progressBarWidth is the number of characters filled by a complete progress bar
+	progressBarWidth = 30
+	// progressStep is the smallest change in percentage reported as a new line when not writing to a terminal
+	progressStep = 10
+)
+
+// progressPattern matches the progress lines sent by git servers, such as 'Receiving objects:  45% (450/1000)'
+var progressPattern = regexp.MustCompile(`^(?:remote:\s*)?([^:]+):\s+(\d+)%`)
+
+// parseProgress extracts the stage and completion percentage reported by the given progress line
+func parseProgress(line string) (string, int, bool) {
+	match := progressPattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", 0, false
+	}
+	percent, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return match[1], percent, true
+}
+
+// progressLines splits the progress information sent by a git remote into lines, passing each complete line to
+// handle. Lines are terminated by either '\r' or '\n', as servers rewrite the same line as a stage advances
+type progressLines struct {
+	buffer []byte
+	handle func(line string)
+}
+
+// Write passes each complete line written to it to the handler
+func (p *progressLines) Write(data []byte) (int, error) {
+	p.buffer = append(p.buffer, data...)
+	for {
+		end := strings.IndexAny(string(p.buffer), "\r\n")
+		if end < 0 {
+			return len(data), nil
+		}
+		p.handle(string(p.buffer[:end]))
+		p.buffer = p.buffer[end+1:]
+	}
+}
+
+// ProgressBar renders the progress information sent by a git remote as a single updating progress bar per stage.
+// When not writing to a terminal, a plain line is written each time a stage advances by 10% instead
+type ProgressBar struct {
+	progressLines
+	w       io.Writer
+	tty     bool
+	stage   string
+	percent int
+}
+
+// NewProgressBar creates a ProgressBar which renders to w
+func NewProgressBar(w io.Writer) *ProgressBar {
+	p := &ProgressBar{
+		w:       w,
+		percent: -1,
+	}
+	if f, ok := w.(*os.File); ok {
+		p.tty = term.IsTerminal(int(f.Fd()))
+	}
+	p.handle = p.render
+	return p
+}
+
+// render updates the progress bar with the given line. Lines which don't report progress are written as-is
+func (p *ProgressBar) render(line string) {
+	stage, percent, ok := parseProgress(line)
+	if !ok {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			p.finish()
+			fmt.Fprintln(p.w, line)
+		}
+		return
+	}
+
+	if stage != p.stage {
+		p.finish()
+		p.stage = stage
+		p.percent = -1
+	}
+	if percent == p.percent {
+		return
+	}
+	if !p.tty {
+		// Only report meaningful steps, so logs aren't flooded
+		if p.percent >= 0 && percent < 100 && percent/progressStep == p.percent/progressStep {
+			return
+		}
+		p.percent = percent
+		fmt.Fprintf(p.w, "%s: %d%%\n", stage, percent)
+		return
+	}
+
+	p.percent = percent
+	filled := percent * progressBarWidth / 100
+	fmt.Fprintf(p.w, "\r%-24s [%s%s] %3d%%", stage, strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled), percent)
+	if percent >= 100 {
+		// The stage is complete, so its bar won't be updated again
+		fmt.Fprintln(p.w)
+	}
+}
+
+// finish ends the line of an incomplete progress bar, so that subsequent output starts on a new line
+func (p *ProgressBar) finish() {
+	if p.tty && p.stage != "" && p.percent >= 0 && p.percent < 100 {
+		fmt.Fprintln(p.w)
+	}
+	p.stage = ""
+	p.percent = -1
+}
@@ -0,0 +1,47 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ResultFormatFlag is the name of the persistent flag selecting the ResultFormat of read commands
+const ResultFormatFlag = "output-format"
+
+// ResultFormat determines how read commands, such as 'grove list', present their results
+type ResultFormat string
+
+const (
+	// ResultFormatTable presents results as human-readable text, typically a table
+	ResultFormatTable ResultFormat = "table"
+	// ResultFormatJSON presents results as a single, indented JSON document
+	ResultFormatJSON ResultFormat = "json"
+	// ResultFormatYAML presents results as a YAML document
+	ResultFormatYAML ResultFormat = "yaml"
+)
+
+// ParseResultFormat validates the given result format
+func ParseResultFormat(format string) (ResultFormat, error) {
+	switch ResultFormat(format) {
+	case ResultFormatTable, ResultFormatJSON, ResultFormatYAML:
+		return ResultFormat(format), nil
+	default:
+		return "", fmt.Errorf("invalid output format %q: expected one of %q, %q, or %q", format, ResultFormatTable, ResultFormatJSON, ResultFormatYAML)
+	}
+}
+
+// Render writes v to w in the given format. v is encoded according to its JSON struct tags for both ResultFormatJSON
+// and ResultFormatYAML, so that the two are equivalent; for ResultFormatTable, table is called to write v instead
+func Render(w io.Writer, format ResultFormat, v any, table func(w io.Writer) error) error {
+	switch format {
+	case ResultFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case ResultFormatYAML:
+		return EncodeYAML(w, v)
+	default:
+		return table(w)
+	}
+}
@@ -0,0 +1,74 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// spinnerInterval is how often a Spinner advances to its next frame
+const spinnerInterval = 100 * time.Millisecond
+
+// spinnerFrames are drawn in turn by a Spinner
+var spinnerFrames = []string{"|", "/", "-", "\\"}
+
+// Spinner shows that a long-running operation which reports no progress of its own is still working. When writing to a
+// terminal, an animated spinner is drawn beside its message until stopped, then erased. Otherwise, the message is
+// written once, as a plain line
+type Spinner struct {
+	w       io.Writer
+	message string
+	stop    chan struct{}
+	done    sync.WaitGroup
+	once    sync.Once
+}
+
+// NewSpinner starts a Spinner with the given message, rendering to w
+func NewSpinner(w io.Writer, message string) *Spinner {
+	s := &Spinner{
+		w:       w,
+		message: message,
+		stop:    make(chan struct{}),
+	}
+	tty := false
+	if f, ok := w.(*os.File); ok {
+		tty = term.IsTerminal(int(f.Fd()))
+	}
+	if !tty {
+		fmt.Fprintf(w, "%s...\n", message)
+		return s
+	}
+
+	s.done.Add(1)
+	go s.spin()
+	return s
+}
+
+// spin redraws the spinner each interval, until stopped
+func (s *Spinner) spin() {
+	defer s.done.Done()
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		fmt.Fprintf(s.w, "\r%s %s...", spinnerFrames[frame%len(spinnerFrames)], s.message)
+		select {
+		case <-s.stop:
+			// Erase the spinner, so that subsequent output starts on a clean line
+			fmt.Fprint(s.w, "\r\033[K")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops the spinner, erasing it. It is safe to call more than once
+func (s *Spinner) Stop() {
+	s.once.Do(func() {
+		close(s.stop)
+		s.done.Wait()
+	})
+}
@@ -0,0 +1,159 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// yamlField is a single key of a JSON object, which keeps its keys in the order they were encoded
+type yamlField struct {
+	key   string
+	value any
+}
+
+// plainScalar matches strings which YAML reads back as the same string without quoting. Strings starting with a
+// digit are always quoted, so that they are not mistaken for numbers or dates
+var plainScalar = regexp.MustCompile(`^[A-Za-z_./][A-Za-z0-9_./()@+ -]*$`)
+
+// reservedScalars are plain words which YAML reads as something other than a string
+var reservedScalars = []string{"true", "false", "yes", "no", "on", "off", "y", "n", "null", "~"}
+
+// EncodeYAML writes v to w as a YAML document, according to the JSON encoding of v: struct fields are named and
+// omitted according to their JSON tags, and keep their order
+func EncodeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %T: %w", v, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeOrdered(decoder)
+	if err != nil {
+		return fmt.Errorf("failed to decode %T: %w", v, err)
+	}
+
+	lines := yamlLines(value)
+	if lines == nil {
+		lines = []string{yamlScalar(value)}
+	}
+	_, err = io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// decodeOrdered decodes the next JSON value from decoder, representing objects as []yamlField so that their keys keep
+// their order
+func decodeOrdered(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := []yamlField{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, yamlField{key: fmt.Sprint(key), value: value})
+		}
+		_, err = decoder.Token()
+		return object, err
+	case json.Delim('['):
+		array := []any{}
+		for decoder.More() {
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = decoder.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+// yamlLines renders a non-empty object or array as YAML block lines, without any indentation of its own. It returns
+// nil for scalars, and empty objects or arrays, which are rendered inline by yamlScalar instead
+func yamlLines(value any) []string {
+	lines := []string{}
+	switch value := value.(type) {
+	case []yamlField:
+		if len(value) == 0 {
+			return nil
+		}
+		for _, field := range value {
+			key := yamlScalar(field.key)
+			nested := yamlLines(field.value)
+			if nested == nil {
+				lines = append(lines, key+": "+yamlScalar(field.value))
+				continue
+			}
+			lines = append(lines, key+":")
+			for _, line := range nested {
+				lines = append(lines, "  "+line)
+			}
+		}
+	case []any:
+		if len(value) == 0 {
+			return nil
+		}
+		for _, item := range value {
+			nested := yamlLines(item)
+			if nested == nil {
+				lines = append(lines, "- "+yamlScalar(item))
+				continue
+			}
+			lines = append(lines, "- "+nested[0])
+			for _, line := range nested[1:] {
+				lines = append(lines, "  "+line)
+			}
+		}
+	default:
+		return nil
+	}
+	return lines
+}
+
+// yamlScalar renders a scalar, or an empty object or array, inline
+func yamlScalar(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return fmt.Sprint(value)
+	case json.Number:
+		return value.String()
+	case []yamlField:
+		return "{}"
+	case []any:
+		return "[]"
+	case string:
+		if plainScalar.MatchString(value) && !strings.HasSuffix(value, " ") && !isReservedScalar(value) {
+			return value
+		}
+		// JSON strings are also valid double-quoted YAML scalars
+		quoted, _ := json.Marshal(value)
+		return string(quoted)
+	}
+	return fmt.Sprint(value)
+}
+
+// isReservedScalar determines whether YAML reads the plain scalar s as something other than a string
+func isReservedScalar(s string) bool {
+	for _, reserved := range reservedScalars {
+		if strings.EqualFold(s, reserved) {
+			return true
+		}
+	}
+	return false
+}